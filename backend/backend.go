@@ -2,7 +2,6 @@ package backend
 
 import (
 	"context"
-	"crypto/rand"
 	"fmt"
 
 	"github.com/hashicorp/go-hclog"
@@ -10,14 +9,20 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/sina-haseli/trust_vault/service"
 	"github.com/sina-haseli/trust_vault/storage"
+	"github.com/sina-haseli/trust_vault/wallet"
 )
 
+// EngineConfigKey is the mount option that selects the wallet engine
+// An empty value uses the default engine of the build
+const EngineConfigKey = "engine"
+
 // TrustVaultBackend implements the Vault logical.Backend interface
 // for the Trust Vault plugin
 type TrustVaultBackend struct {
 	*framework.Backend
-	walletService *service.WalletService
-	logger        hclog.Logger
+	storageService *storage.StorageService
+	walletService  *service.WalletService
+	logger         hclog.Logger
 }
 
 // Factory creates and initializes a new TrustVaultBackend instance
@@ -30,32 +35,52 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 
 	b.logger.Info("initializing Trust Vault plugin")
 
-	// Generate encryption key for storage (32 bytes for AES-256)
-	encryptionKey := make([]byte, 32)
-	if _, err := rand.Read(encryptionKey); err != nil {
-		b.logger.Error("failed to generate encryption key", "error", err)
-		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
-	}
-
-	b.logger.Debug("encryption key generated successfully")
-
 	// Initialize storage service
-	storageService := storage.NewStorageService(conf.StorageView, encryptionKey, b.logger)
-
-	// Initialize wallet service
-	b.walletService = service.NewWalletService(storageService, b.logger)
+	// The encryption key is persisted in the keyring and loaded on initialization
+	b.storageService = storage.NewStorageService(conf.StorageView, b.logger)
+
+	// Initialize wallet engine and service
+	engineName := conf.Config[EngineConfigKey]
+	engine, err := wallet.NewEngine(engineName)
+	if err != nil {
+		b.logger.Error("failed to create wallet engine", "engine", engineName, "error", err)
+		return nil, fmt.Errorf("failed to create wallet engine: %w", err)
+	}
+	b.walletService = service.NewWalletService(b.storageService, engine, b.logger)
 
 	// Configure backend
 	b.Backend = &framework.Backend{
 		BackendType: logical.TypeLogical,
 		Help:        "Trust Vault Plugin provides cryptocurrency wallet management through Trust Wallet Core",
+		PathsSpecial: &logical.Paths{
+			SealWrapStorage: []string{
+				storage.KeyringPath,
+			},
+		},
+		InitializeFunc: b.initialize,
+		Invalidate:     b.invalidate,
+		PeriodicFunc:   b.periodicFunc,
 		Paths: []*framework.Path{
-			b.pathWalletCreate(),
-			b.pathWalletRead(),
-			b.pathWalletDelete(),
+			b.pathWallet(),
 			b.pathWalletList(),
 			b.pathWalletSign(),
+			b.pathWalletSignJSON(),
+			b.pathWalletSignMessage(),
+			b.pathWalletVerify(),
 			b.pathWalletAddress(),
+			b.pathWalletAddresses(),
+			b.pathLookupAddress(),
+			b.pathValidateAddress(),
+			b.pathWalletXpub(),
+			b.pathXpubDerive(),
+			b.pathConfig(),
+			b.pathCoins(),
+			b.pathChains(),
+			b.pathChainList(),
+			b.pathKeysRotate(),
+			b.pathKeysConfig(),
+			b.pathKeysRewrap(),
+			b.pathKeysRewrapStatus(),
 			b.pathHealth(),
 		},
 	}
@@ -70,6 +95,30 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 	return b, nil
 }
 
+// initialize loads the data-encryption key, creating it on the first mount, and builds
+// the wallet indexes of wallets stored before they were maintained
+// This is called by Vault once the backend is set up and storage is writable
+func (b *TrustVaultBackend) initialize(ctx context.Context, req *logical.InitializationRequest) error {
+	if err := b.storageService.Initialize(ctx); err != nil {
+		b.logger.Error("failed to initialize keyring", "error", err)
+		return fmt.Errorf("failed to initialize keyring: %w", err)
+	}
+
+	b.logger.Debug("keyring initialized successfully")
+
+	// Performance standbys and read-only replicas leave the indexes to the active node
+	if !b.WriteSafeReplicationState() {
+		return nil
+	}
+
+	if err := b.storageService.BuildWalletIndexes(ctx); err != nil {
+		b.logger.Error("failed to build wallet indexes", "error", err)
+		return fmt.Errorf("failed to build wallet indexes: %w", err)
+	}
+
+	return nil
+}
+
 // pathHealth returns the path configuration for health check endpoint
 // GET /trust-vault/health
 func (b *TrustVaultBackend) pathHealth() *framework.Path {
@@ -0,0 +1,1297 @@
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/service"
+	"github.com/sina-haseli/trust_vault/storage"
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// testMnemonic is the BIP39 test mnemonic whose keys are published in the BIP44, BIP84 and BIP86 test vectors
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// inputRecorderEngine is the Go engine with SignInput returning the SigningInput it was given,
+// hex-encoded as the raw transaction, so tests can inspect the inputs built by the backend
+const inputRecorderEngine = "input-recorder"
+
+func init() {
+	wallet.RegisterEngine(inputRecorderEngine, func() wallet.Engine { return inputRecorder{wallet.NewGoEngine()} })
+}
+
+type inputRecorder struct {
+	*wallet.GoEngine
+}
+
+func (inputRecorder) SignInput(privateKey []byte, coinType uint32, input []byte) (*wallet.SignedTransaction, error) {
+	return &wallet.SignedTransaction{RawTx: hex.EncodeToString(input), CoinType: coinType}, nil
+}
+
+// testBackend mounts a backend over the given storage and runs its initializer
+func testBackend(t *testing.T, store logical.Storage) *TrustVaultBackend {
+	t.Helper()
+
+	return testBackendWithOptions(t, store, nil)
+}
+
+// testBackendWithOptions mounts a backend with mount options, e.g. the engine
+func testBackendWithOptions(t *testing.T, store logical.Storage, options map[string]string) *TrustVaultBackend {
+	t.Helper()
+
+	ctx := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = store
+	config.Config = options
+
+	b, err := Factory(ctx, config)
+	if err != nil {
+		t.Fatalf("Factory() error = %v", err)
+	}
+	if err := b.Initialize(ctx, &logical.InitializationRequest{Storage: store}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	return b.(*TrustVaultBackend)
+}
+
+func TestFactoryRestartKeepsWalletsReadable(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+
+	b := testBackend(t, store)
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/restart",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	// Re-run Factory against the same storage to simulate a plugin restart
+	restarted := testBackend(t, store)
+	resp, err = restarted.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "wallets/restart/sign",
+		Storage:   store,
+		Data: map[string]interface{}{
+			"tx_data": base64.StdEncoding.EncodeToString(make([]byte, 32)),
+		},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("sign after restart: resp = %#v, err = %v", resp, err)
+	}
+	if resp.Data["signed_tx"] == "" {
+		t.Fatalf("sign after restart returned an empty signature")
+	}
+}
+
+func TestSignMessageVerifyRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/signer",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "wallets/signer/sign-message",
+		Storage:   store,
+		Data:      map[string]interface{}{"message": "hello", "format": "eip191"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("sign message: resp = %#v, err = %v", resp, err)
+	}
+	signature := resp.Data["signature"].(string)
+
+	tests := []struct {
+		name    string
+		message string
+		valid   bool
+	}{
+		{name: "signed message", message: "hello", valid: true},
+		{name: "tampered message", message: "hello!", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "wallets/signer/verify",
+				Storage:   store,
+				Data:      map[string]interface{}{"message": tt.message, "signature": signature},
+			})
+			if err != nil || resp.IsError() {
+				t.Fatalf("verify: resp = %#v, err = %v", resp, err)
+			}
+			if resp.Data["valid"] != tt.valid {
+				t.Errorf("valid = %v, want %v", resp.Data["valid"], tt.valid)
+			}
+			if resp.Data["format"] != "eip191" {
+				t.Errorf("format = %v, want eip191", resp.Data["format"])
+			}
+		})
+	}
+}
+
+func TestVerifyChecksWalletAndCoin(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	requests := []*logical.Request{
+		{Operation: logical.CreateOperation, Path: "wallets/verifier", Data: map[string]interface{}{"coin_type": 60, "coin_types": "BTC"}},
+		{Operation: logical.UpdateOperation, Path: "wallets/verifier/sign-message", Data: map[string]interface{}{"message": "hello"}},
+	}
+	var signature string
+	for _, req := range requests {
+		req.Storage = store
+		resp, err := b.HandleRequest(ctx, req)
+		if err != nil || resp.IsError() {
+			t.Fatalf("%s: resp = %#v, err = %v", req.Path, resp, err)
+		}
+		if sig, ok := resp.Data["signature"].(string); ok {
+			signature = sig
+		}
+	}
+
+	verify := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		data["message"] = "hello"
+		data["signature"] = signature
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "wallets/verifier/verify",
+			Storage:   store,
+			Data:      data,
+		})
+		if err != nil {
+			t.Fatalf("verify: err = %v", err)
+		}
+		return resp
+	}
+
+	if resp := verify(map[string]interface{}{}); resp.IsError() || resp.Data["valid"] != true {
+		t.Fatalf("verify: resp = %#v, want a valid signature", resp)
+	}
+
+	// The creation key and derived keys are refused alike once the wallet or coin is off
+	setup := []struct {
+		name string
+		reqs []*logical.Request
+		want string
+	}{
+		{
+			name: "disabled wallet",
+			reqs: []*logical.Request{
+				{Operation: logical.PatchOperation, Path: "wallets/verifier", Data: map[string]interface{}{"enabled": false}},
+			},
+			want: "wallet is disabled",
+		},
+		{
+			name: "coin off the allow-list",
+			reqs: []*logical.Request{
+				{Operation: logical.PatchOperation, Path: "wallets/verifier", Data: map[string]interface{}{"enabled": true}},
+				{Operation: logical.UpdateOperation, Path: "config", Data: map[string]interface{}{"enabled_coin_types": "501"}},
+			},
+			want: "coin type disabled",
+		},
+	}
+	for _, tt := range setup {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, req := range tt.reqs {
+				req.Storage = store
+				if resp, err := b.HandleRequest(ctx, req); err != nil || resp.IsError() {
+					t.Fatalf("%s: resp = %#v, err = %v", req.Path, resp, err)
+				}
+			}
+
+			for _, data := range []map[string]interface{}{{}, {"address_index": 1}, {"coin_type": "BTC"}} {
+				resp := verify(data)
+				if !resp.IsError() || !strings.Contains(resp.Error().Error(), tt.want) {
+					t.Errorf("verify %v: resp = %#v, want error %q", data, resp, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSigningResponsesEchoCoin(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/echo",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60, "coin_types": "BTC"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	txData := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	transaction := map[string]interface{}{"chain_id": 1, "to": "0x3535353535353535353535353535353535353535", "value": "1", "nonce": "0", "gas": "21000", "gas_price": "1"}
+
+	tests := []struct {
+		name       string
+		path       string
+		data       map[string]interface{}
+		wantType   uint32
+		wantSymbol string
+	}{
+		{name: "sign", path: "sign", data: map[string]interface{}{"tx_data": txData}, wantType: 60, wantSymbol: "ETH"},
+		{name: "sign with bitcoin key", path: "sign", data: map[string]interface{}{"tx_data": txData, "coin_type": "BTC"}, wantType: 0, wantSymbol: "BTC"},
+		{name: "sign json", path: "sign/json", data: map[string]interface{}{"transaction": transaction}, wantType: 60, wantSymbol: "ETH"},
+		{name: "sign message", path: "sign-message", data: map[string]interface{}{"message": "hello"}, wantType: 60, wantSymbol: "ETH"},
+		{name: "sign message with bitcoin key", path: "sign-message", data: map[string]interface{}{"message": "hello", "coin_type": "bitcoin"}, wantType: 0, wantSymbol: "BTC"},
+		{name: "verify", path: "verify", data: map[string]interface{}{"message": txData, "signature": txData, "format": "raw"}, wantType: 60, wantSymbol: "ETH"},
+		{name: "verify with bitcoin key", path: "verify", data: map[string]interface{}{"message": txData, "signature": txData, "format": "raw", "coin_type": 0}, wantType: 0, wantSymbol: "BTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "wallets/echo/" + tt.path,
+				Storage:   store,
+				Data:      tt.data,
+			})
+			if err != nil || resp.IsError() {
+				t.Fatalf("%s: resp = %#v, err = %v", tt.path, resp, err)
+			}
+			if resp.Data["coin_type"] != tt.wantType || resp.Data["coin_symbol"] != tt.wantSymbol {
+				t.Errorf("coin_type = %v, coin_symbol = %v, want %d and %s", resp.Data["coin_type"], resp.Data["coin_symbol"], tt.wantType, tt.wantSymbol)
+			}
+		})
+	}
+}
+
+func TestSignRequiresEnabledCoinType(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/multi",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60, "coin_types": "501"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	tests := []struct {
+		name     string
+		coinType interface{}
+		wantErr  bool
+	}{
+		{name: "creation coin", coinType: nil},
+		{name: "enabled coin", coinType: 501},
+		{name: "disabled coin", coinType: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{
+				"tx_data": base64.StdEncoding.EncodeToString(make([]byte, 32)),
+			}
+			if tt.coinType != nil {
+				data["coin_type"] = tt.coinType
+			}
+
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "wallets/multi/sign",
+				Storage:   store,
+				Data:      data,
+			})
+			if err != nil {
+				t.Fatalf("sign: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Errorf("sign: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSigningKeySelection(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/deposits",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "derivation path", data: map[string]interface{}{"derivation_path": "m/44'/60'/0'/0/5"}},
+		{name: "address index", data: map[string]interface{}{"address_index": 5}},
+		{name: "invalid path", data: map[string]interface{}{"derivation_path": "44/60"}, wantErr: true},
+		{name: "path and index", data: map[string]interface{}{"derivation_path": "m/44'/60'/0'/0/5", "address_index": 5}, wantErr: true},
+		{name: "invalid change", data: map[string]interface{}{"change": 2}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data["tx_data"] = base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "wallets/deposits/sign",
+				Storage:   store,
+				Data:      tt.data,
+			})
+			if err != nil {
+				t.Fatalf("sign: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Errorf("sign: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnstoredPassphraseMustBeSupplied(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/cold",
+		Storage:   store,
+		Data: map[string]interface{}{
+			"coin_type":        60,
+			"passphrase":       "correct horse",
+			"store_passphrase": false,
+		},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+	if resp.Data["passphrase_required"] != true {
+		t.Fatalf("passphrase_required = %v, want true", resp.Data["passphrase_required"])
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		wantErr    bool
+	}{
+		{name: "missing passphrase", wantErr: true},
+		{name: "wrong passphrase", passphrase: "battery staple", wantErr: true},
+		{name: "correct passphrase", passphrase: "correct horse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{
+				"tx_data": base64.StdEncoding.EncodeToString(make([]byte, 32)),
+			}
+			if tt.passphrase != "" {
+				data["passphrase"] = tt.passphrase
+			}
+
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "wallets/cold/sign",
+				Storage:   store,
+				Data:      data,
+			})
+			if err != nil {
+				t.Fatalf("sign: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Errorf("sign: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+		})
+	}
+
+	// Read parameters are sent in the URL, so the passphrase is only accepted on writes
+	for _, path := range []string{"wallets/cold/addresses/ETH", "wallets/cold/xpub"} {
+		for _, operation := range []logical.Operation{logical.ReadOperation, logical.UpdateOperation} {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: operation,
+				Path:      path,
+				Storage:   store,
+				Data:      map[string]interface{}{"passphrase": "correct horse"},
+			})
+			if err != nil {
+				t.Fatalf("%s %s: err = %v", operation, path, err)
+			}
+			if wantErr := operation == logical.ReadOperation; resp.IsError() != wantErr {
+				t.Errorf("%s %s: resp = %#v, want error %v", operation, path, resp, wantErr)
+			}
+		}
+	}
+}
+
+func TestMnemonicWordCount(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   store,
+		Data:      map[string]interface{}{"default_word_count": 24},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("write config: resp = %#v, err = %v", resp, err)
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "mount default", data: map[string]interface{}{}, want: 24},
+		{name: "requested count", data: map[string]interface{}{"word_count": 15}, want: 15},
+		{name: "invalid count", data: map[string]interface{}{"word_count": 13}, wantErr: true},
+		{name: "unsupported wordlist", data: map[string]interface{}{"wordlist": "japanese"}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data["coin_type"] = 60
+
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.CreateOperation,
+				Path:      fmt.Sprintf("wallets/words-%d", i),
+				Storage:   store,
+				Data:      tt.data,
+			})
+			if err != nil {
+				t.Fatalf("create wallet: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Fatalf("create wallet: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+			if !tt.wantErr && resp.Data["word_count"] != tt.want {
+				t.Errorf("word_count = %v, want %d", resp.Data["word_count"], tt.want)
+			}
+		})
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   store,
+		Data:      map[string]interface{}{"default_word_count": 16},
+	})
+	if err != nil || !resp.IsError() {
+		t.Errorf("write invalid config: resp = %#v, err = %v, want error response", resp, err)
+	}
+}
+
+func TestExtendedPublicKeyExport(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/watch",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 0, "coin_types": "60,501"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "coin default purpose", data: map[string]interface{}{}, wantPath: "m/84'/0'/0'"},
+		{name: "nested segwit account", data: map[string]interface{}{"purpose": 49, "account": 2}, wantPath: "m/49'/0'/2'"},
+		{name: "ethereum", data: map[string]interface{}{"coin_type": 60}, wantPath: "m/44'/60'/0'"},
+		{name: "segwit purpose on ethereum", data: map[string]interface{}{"coin_type": 60, "purpose": 84}, wantErr: true},
+		{name: "unknown purpose", data: map[string]interface{}{"purpose": 45}, wantErr: true},
+		{name: "ed25519 coin", data: map[string]interface{}{"coin_type": 501}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      "wallets/watch/xpub",
+				Storage:   store,
+				Data:      tt.data,
+			})
+			if err != nil {
+				t.Fatalf("xpub: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Fatalf("xpub: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if resp.Data["derivation_path"] != tt.wantPath {
+				t.Errorf("derivation_path = %v, want %s", resp.Data["derivation_path"], tt.wantPath)
+			}
+
+			resp, err = b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "xpub/derive",
+				Storage:   store,
+				Data: map[string]interface{}{
+					"xpub":          resp.Data["xpub"],
+					"coin_type":     resp.Data["coin_type"],
+					"address_index": 3,
+				},
+			})
+			if err != nil || resp.IsError() {
+				t.Fatalf("derive: resp = %#v, err = %v", resp, err)
+			}
+			if resp.Data["address"] == "" {
+				t.Errorf("derive returned an empty address")
+			}
+		})
+	}
+}
+
+func TestBitcoinAddressTypes(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	tests := []struct {
+		name        string
+		data        map[string]interface{}
+		addressType string
+		purpose     int
+		wantErr     bool
+	}{
+		{name: "default", data: map[string]interface{}{"coin_type": 0}, addressType: "p2wpkh", purpose: 84},
+		{name: "legacy", data: map[string]interface{}{"coin_type": 0, "address_type": "p2pkh"}, addressType: "p2pkh", purpose: 44},
+		{name: "nested segwit", data: map[string]interface{}{"coin_type": 0, "address_type": "p2sh-p2wpkh"}, addressType: "p2sh-p2wpkh", purpose: 49},
+		{name: "taproot", data: map[string]interface{}{"coin_type": 0, "address_type": "p2tr"}, addressType: "p2tr", purpose: 86},
+		{name: "unknown type", data: map[string]interface{}{"coin_type": 0, "address_type": "p2wsh"}, wantErr: true},
+		{name: "ethereum", data: map[string]interface{}{"coin_type": 60, "address_type": "p2pkh"}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := fmt.Sprintf("wallets/btc-%d", i)
+
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.CreateOperation,
+				Path:      path,
+				Storage:   store,
+				Data:      tt.data,
+			})
+			if err != nil {
+				t.Fatalf("create wallet: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Fatalf("create wallet: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if resp.Data["address_type"] != tt.addressType {
+				t.Errorf("address_type = %v, want %s", resp.Data["address_type"], tt.addressType)
+			}
+
+			// The exported account key follows the wallet's address type
+			resp, err = b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      path + "/xpub",
+				Storage:   store,
+			})
+			if err != nil || resp.IsError() {
+				t.Fatalf("xpub: resp = %#v, err = %v", resp, err)
+			}
+			if resp.Data["purpose"] != uint32(tt.purpose) {
+				t.Errorf("purpose = %v, want %d", resp.Data["purpose"], tt.purpose)
+			}
+		})
+	}
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "wallets/btc-0/addresses/0",
+		Storage:   store,
+		Data:      map[string]interface{}{"address_type": "p2pkh"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("address: resp = %#v, err = %v", resp, err)
+	}
+
+	// sign/json spends UTXOs without a script from the wallet key's script in its address type;
+	// the scripts are those of the BIP44 and BIP86 test vectors of the test mnemonic
+	recorderStore := &logical.InmemStorage{}
+	recorder := testBackendWithOptions(t, recorderStore, map[string]string{EngineConfigKey: inputRecorderEngine})
+	transaction := map[string]interface{}{
+		"utxos":    []interface{}{map[string]interface{}{"txid": strings.Repeat("ab", 32), "vout": 0, "amount": 100000}},
+		"outputs":  []interface{}{map[string]interface{}{"address": "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", "amount": 50000}},
+		"fee_rate": 10,
+	}
+
+	scripts := []struct {
+		addressType string
+		script      string
+	}{
+		{addressType: "p2pkh", script: "76a914d986ed01b7a22225a70edbf2ba7cfb63a15cb3aa88ac"},
+		{addressType: "p2tr", script: "5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c"},
+	}
+
+	for _, tt := range scripts {
+		t.Run("sign json "+tt.addressType, func(t *testing.T) {
+			path := "wallets/spend-" + tt.addressType
+
+			resp, err := recorder.HandleRequest(ctx, &logical.Request{
+				Operation: logical.CreateOperation,
+				Path:      path,
+				Storage:   recorderStore,
+				Data:      map[string]interface{}{"coin_type": 0, "address_type": tt.addressType, "mnemonic": testMnemonic},
+			})
+			if err != nil || resp.IsError() {
+				t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+			}
+
+			resp, err = recorder.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      path + "/sign/json",
+				Storage:   recorderStore,
+				Data:      map[string]interface{}{"transaction": transaction},
+			})
+			if err != nil || resp.IsError() {
+				t.Fatalf("sign json: resp = %#v, err = %v", resp, err)
+			}
+			if input := resp.Data["raw_tx"].(string); !strings.Contains(input, tt.script) {
+				t.Errorf("signing input %s does not spend script %s", input, tt.script)
+			}
+		})
+	}
+}
+
+func TestAddressBatch(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/deposits",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	tests := []struct {
+		name        string
+		data        map[string]interface{}
+		wantCount   int
+		wantIndices []uint32
+		wantErr     bool
+	}{
+		{name: "range", data: map[string]interface{}{"start_index": 5, "count": 3}, wantCount: 3, wantIndices: []uint32{5, 6, 7}},
+		{name: "gap limit", data: map[string]interface{}{"mode": "gap_limit", "count": 3, "used_indices": "0,1,3"}, wantCount: 3, wantIndices: []uint32{2, 4, 5}},
+		{name: "gap limit without used", data: map[string]interface{}{"mode": "gap_limit", "count": 2}, wantCount: 2, wantIndices: []uint32{0, 1}},
+		{name: "default count", data: map[string]interface{}{}, wantCount: 20},
+		{name: "count too large", data: map[string]interface{}{"count": 1001}, wantErr: true},
+		{name: "zero count", data: map[string]interface{}{"count": 0}, wantErr: true},
+		{name: "used indices in range mode", data: map[string]interface{}{"used_indices": "1"}, wantErr: true},
+		{name: "unknown mode", data: map[string]interface{}{"mode": "scan"}, wantErr: true},
+		{name: "index overflow", data: map[string]interface{}{"start_index": 2147483647, "count": 2}, wantErr: true},
+		{name: "coin not enabled", data: map[string]interface{}{"coin_type": 0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "wallets/deposits/addresses",
+				Storage:   store,
+				Data:      tt.data,
+			})
+			if err != nil {
+				t.Fatalf("addresses: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Fatalf("addresses: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			addresses := resp.Data["addresses"].([]map[string]interface{})
+			if len(addresses) != tt.wantCount {
+				t.Fatalf("got %d addresses, want %d", len(addresses), tt.wantCount)
+			}
+			for i, address := range addresses {
+				if tt.wantIndices != nil && address["address_index"] != tt.wantIndices[i] {
+					t.Errorf("addresses[%d] index = %v, want %d", i, address["address_index"], tt.wantIndices[i])
+				}
+				wantPath := fmt.Sprintf("m/44'/60'/0'/0/%d", address["address_index"])
+				if address["derivation_path"] != wantPath {
+					t.Errorf("addresses[%d] derivation_path = %v, want %s", i, address["derivation_path"], wantPath)
+				}
+			}
+		})
+	}
+}
+
+func TestAddressRegistryLookup(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/reconcile",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "wallets/reconcile/addresses/60",
+		Storage:   store,
+		Data: map[string]interface{}{
+			"derivation_path": "m/44'/60'/0'/0/9",
+			"label":           "invoice-9",
+			"metadata":        map[string]interface{}{"customer": "acme"},
+		},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("address: resp = %#v, err = %v", resp, err)
+	}
+	address := resp.Data["address"].(string)
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "wallets/reconcile/addresses",
+		Storage:   store,
+		Data:      map[string]interface{}{"count": 2},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("addresses: resp = %#v, err = %v", resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "lookup/address/" + address,
+		Storage:   store,
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("lookup: resp = %#v, err = %v", resp, err)
+	}
+	if resp.Data["wallet"] != "reconcile" || resp.Data["derivation_path"] != "m/44'/60'/0'/0/9" || resp.Data["coin_type"] != uint32(60) {
+		t.Errorf("lookup = %#v", resp.Data)
+	}
+	if resp.Data["label"] != "invoice-9" || resp.Data["metadata"].(map[string]string)["customer"] != "acme" {
+		t.Errorf("lookup label and metadata = %v, %v", resp.Data["label"], resp.Data["metadata"])
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "wallets/reconcile/addresses/",
+		Storage:   store,
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("list: resp = %#v, err = %v", resp, err)
+	}
+	if keys := resp.Data["keys"].([]string); len(keys) != 3 {
+		t.Errorf("registry keys = %v, want 3", keys)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "lookup/address/unknown",
+		Storage:   store,
+	})
+	if err != nil || resp.Data["error"] != "address not found" || resp.Data["http_status_code"] != 404 {
+		t.Errorf("lookup of unknown address: resp = %#v, err = %v", resp, err)
+	}
+}
+
+func TestCoinAllowList(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/before",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60, "coin_types": "0"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   store,
+		Data:      map[string]interface{}{"enabled_coin_types": "60,501"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("write config: resp = %#v, err = %v", resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "coins",
+		Storage:   store,
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("read coins: resp = %#v, err = %v", resp, err)
+	}
+	enabled := map[uint32]bool{}
+	for _, coin := range resp.Data["coins"].([]map[string]interface{}) {
+		enabled[coin["coin_type"].(uint32)] = coin["enabled"].(bool)
+	}
+	if !enabled[60] || !enabled[501] || enabled[0] {
+		t.Errorf("enabled coins = %v, want only 60 and 501", enabled)
+	}
+
+	tests := []struct {
+		name    string
+		req     *logical.Request
+		wantErr bool
+	}{
+		{
+			name:    "create with disabled coin",
+			req:     &logical.Request{Operation: logical.CreateOperation, Path: "wallets/btc", Data: map[string]interface{}{"coin_type": 0}},
+			wantErr: true,
+		},
+		{
+			name: "create with enabled coin",
+			req:  &logical.Request{Operation: logical.CreateOperation, Path: "wallets/eth", Data: map[string]interface{}{"coin_type": 60}},
+		},
+		{
+			name:    "address of coin disabled after creation",
+			req:     &logical.Request{Operation: logical.ReadOperation, Path: "wallets/before/addresses/0"},
+			wantErr: true,
+		},
+		{
+			name: "address of enabled coin",
+			req:  &logical.Request{Operation: logical.ReadOperation, Path: "wallets/before/addresses/60"},
+		},
+		{
+			name:    "unknown coin in allow-list",
+			req:     &logical.Request{Operation: logical.UpdateOperation, Path: "config", Data: map[string]interface{}{"enabled_coin_types": "999999999"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Storage = store
+			resp, err := b.HandleRequest(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("request: err = %v", err)
+			}
+			if gotErr := resp.IsError() || resp.Data["error"] != nil; gotErr != tt.wantErr {
+				t.Errorf("request: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCoinResolution(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/symbols",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": "eth", "coin_types": "bitcoin,SOL"},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+	if resp.Data["coin_type"] != uint32(60) || resp.Data["coin_symbol"] != "ETH" {
+		t.Errorf("coin_type = %v, coin_symbol = %v, want 60 and ETH", resp.Data["coin_type"], resp.Data["coin_symbol"])
+	}
+	if fmt.Sprint(resp.Data["coin_types"]) != "[60 0 501]" {
+		t.Errorf("coin_types = %v, want [60 0 501]", resp.Data["coin_types"])
+	}
+
+	tests := []struct {
+		coin       string
+		wantType   uint32
+		wantSymbol string
+		wantErr    bool
+	}{
+		{coin: "0", wantType: 0, wantSymbol: "BTC"},
+		{coin: "BTC", wantType: 0, wantSymbol: "BTC"},
+		{coin: "solana", wantType: 501, wantSymbol: "SOL"},
+		{coin: "doge-not-a-coin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.coin, func(t *testing.T) {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      "wallets/symbols/addresses/" + tt.coin,
+				Storage:   store,
+			})
+			if err != nil {
+				t.Fatalf("address: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Fatalf("address: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+			if !tt.wantErr && (resp.Data["coin_type"] != tt.wantType || resp.Data["coin_symbol"] != tt.wantSymbol) {
+				t.Errorf("coin_type = %v, coin_symbol = %v, want %d and %s", resp.Data["coin_type"], resp.Data["coin_symbol"], tt.wantType, tt.wantSymbol)
+			}
+		})
+	}
+}
+
+func TestChainProfiles(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "chains/bsc",
+		Storage:   store,
+		Data:      map[string]interface{}{"chain_id": 56, "symbol": "BNB", "eip1559": false},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create chain: resp = %#v, err = %v", resp, err)
+	}
+
+	// Updates change only the supplied fields
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "chains/bsc",
+		Storage:   store,
+		Data:      map[string]interface{}{"eip1559": true},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("update chain: resp = %#v, err = %v", resp, err)
+	}
+	if resp.Data["chain_id"] != uint64(56) || resp.Data["symbol"] != "BNB" || resp.Data["eip1559"] != true {
+		t.Errorf("updated chain = %v", resp.Data)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "chains/missing-id",
+		Storage:   store,
+		Data:      map[string]interface{}{"symbol": "POL"},
+	})
+	if err != nil || !resp.IsError() {
+		t.Errorf("create chain without chain_id: resp = %#v, err = %v, want error response", resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "chains/",
+		Storage:   store,
+	})
+	if err != nil || resp.IsError() || fmt.Sprint(resp.Data["keys"]) != "[bsc]" {
+		t.Fatalf("list chains: resp = %#v, err = %v", resp, err)
+	}
+
+	for _, name := range []string{"evm", "sol"} {
+		coinType := 60
+		if name == "sol" {
+			coinType = 501
+		}
+		resp, err = b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.CreateOperation,
+			Path:      "wallets/" + name,
+			Storage:   store,
+			Data:      map[string]interface{}{"coin_type": coinType},
+		})
+		if err != nil || resp.IsError() {
+			t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+		}
+	}
+
+	tx := map[string]interface{}{
+		"to":        "0x3535353535353535353535353535353535353535",
+		"value":     "1",
+		"nonce":     "0",
+		"gas":       "21000",
+		"gas_price": "3000000000",
+	}
+
+	tests := []struct {
+		name    string
+		wallet  string
+		chain   string
+		chainID interface{}
+		wantErr bool
+	}{
+		{name: "chain id from profile", wallet: "evm", chain: "bsc"},
+		{name: "matching chain id", wallet: "evm", chain: "bsc", chainID: 56},
+		{name: "mismatched chain id", wallet: "evm", chain: "bsc", chainID: 1, wantErr: true},
+		{name: "unknown chain", wallet: "evm", chain: "nope", wantErr: true},
+		{name: "non-ethereum key", wallet: "sol", chain: "bsc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := map[string]interface{}{}
+			for k, v := range tx {
+				transaction[k] = v
+			}
+			if tt.chainID != nil {
+				transaction["chain_id"] = tt.chainID
+			}
+
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "wallets/" + tt.wallet + "/sign/json",
+				Storage:   store,
+				Data:      map[string]interface{}{"chain": tt.chain, "transaction": transaction},
+			})
+			if err != nil {
+				t.Fatalf("sign: err = %v", err)
+			}
+			if gotErr := resp.IsError() || resp.Data["error"] != nil; gotErr != tt.wantErr {
+				t.Errorf("sign: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+		})
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "chains/bsc",
+		Storage:   store,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("delete chain: resp = %#v, err = %v", resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "chains/bsc",
+		Storage:   store,
+	})
+	if err != nil || resp.Data["error"] != "chain profile not found" {
+		t.Errorf("read deleted chain: resp = %#v, err = %v", resp, err)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		wantValid bool
+		wantErr   bool
+	}{
+		{name: "lowercase hex", data: map[string]interface{}{"coin": "ETH", "address": "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}, wantValid: true},
+		{name: "malformed", data: map[string]interface{}{"coin": "60", "address": "0x5aaeb6"}},
+		{name: "unknown coin", data: map[string]interface{}{"coin": "nope", "address": "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"}, wantErr: true},
+		{name: "missing address", data: map[string]interface{}{"coin": "ETH"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := b.HandleRequest(ctx, &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "addresses/validate",
+				Storage:   store,
+				Data:      tt.data,
+			})
+			if err != nil {
+				t.Fatalf("validate: err = %v", err)
+			}
+			if resp.IsError() != tt.wantErr {
+				t.Fatalf("validate: resp = %#v, want error %v", resp, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if resp.Data["valid"] != tt.wantValid || resp.Data["coin_type"] != uint32(60) {
+				t.Errorf("validate: resp = %v", resp.Data)
+			}
+			if tt.wantValid && resp.Data["normalized_address"] == "" {
+				t.Error("validate: no normalized address")
+			}
+		})
+	}
+}
+
+func TestHandleErrorStatusMapping(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	b := testBackend(t, store)
+
+	// Serve wallets from an in-memory store that can be told to fail
+	engine, err := wallet.NewEngine("")
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	faults := storage.NewFaultStore(storage.NewMemoryStore())
+	b.walletService = service.NewWalletService(faults, engine, b.logger)
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "wallets/faulty",
+		Storage:   store,
+		Data:      map[string]interface{}{"coin_type": 60},
+	})
+	if err != nil || resp.IsError() {
+		t.Fatalf("create wallet: resp = %#v, err = %v", resp, err)
+	}
+
+	errUnavailable := errors.New("storage unavailable")
+	txData := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	transaction := map[string]interface{}{"to": "0x3535353535353535353535353535353535353535", "value": "1", "nonce": "0", "gas": "21000", "gas_price": "1"}
+
+	tests := []struct {
+		name       string
+		operation  string
+		err        error
+		request    *logical.Request
+		wantStatus interface{}
+		wantError  string
+		// wantInternal is set when the error is returned to Vault rather than as a response
+		wantInternal bool
+	}{
+		{
+			name:       "wallet exists",
+			operation:  "StoreWallet",
+			err:        storage.ErrWalletExists,
+			request:    &logical.Request{Operation: logical.CreateOperation, Path: "wallets/duplicate", Data: map[string]interface{}{"coin_type": 60}},
+			wantStatus: 409,
+			wantError:  "wallet already exists",
+		},
+		{
+			name:       "wallet not found for signing",
+			operation:  "GetWallet",
+			err:        storage.ErrWalletNotFound,
+			request:    &logical.Request{Operation: logical.UpdateOperation, Path: "wallets/faulty/sign", Data: map[string]interface{}{"tx_data": txData}},
+			wantStatus: 404,
+			wantError:  "wallet not found",
+		},
+		{
+			name:       "wallet not found for verification",
+			operation:  "GetWalletMetadata",
+			err:        storage.ErrWalletNotFound,
+			request:    &logical.Request{Operation: logical.UpdateOperation, Path: "wallets/faulty/verify", Data: map[string]interface{}{"message": "hello", "signature": "0x00"}},
+			wantStatus: 404,
+			wantError:  "wallet not found",
+		},
+		{
+			name:       "chain not found",
+			operation:  "GetChain",
+			err:        storage.ErrChainNotFound,
+			request:    &logical.Request{Operation: logical.UpdateOperation, Path: "wallets/faulty/sign/json", Data: map[string]interface{}{"chain": "polygon", "transaction": transaction}},
+			wantStatus: 404,
+			wantError:  "chain profile not found",
+		},
+		{
+			name:       "address not found",
+			operation:  "LookupAddress",
+			err:        storage.ErrAddressNotFound,
+			request:    &logical.Request{Operation: logical.ReadOperation, Path: "lookup/address/0x3535353535353535353535353535353535353535"},
+			wantStatus: 404,
+			wantError:  "address not found",
+		},
+		{
+			name:         "unknown key version",
+			operation:    "GetWallet",
+			err:          fmt.Errorf("failed to decrypt wallet: %w", storage.ErrUnknownKeyVersion),
+			request:      &logical.Request{Operation: logical.UpdateOperation, Path: "wallets/faulty/sign", Data: map[string]interface{}{"tx_data": txData}},
+			wantInternal: true,
+		},
+		{
+			name:       "retired key version",
+			operation:  "GetWallet",
+			err:        fmt.Errorf("failed to decrypt wallet: %w", storage.ErrKeyVersionRetired),
+			request:    &logical.Request{Operation: logical.UpdateOperation, Path: "wallets/faulty/sign", Data: map[string]interface{}{"tx_data": txData}},
+			wantStatus: 409,
+			wantError:  "wallet is encrypted with a retired key version; lower min_decryption_version to read it",
+		},
+		{
+			name:         "storage failure on list",
+			operation:    "FindWallets",
+			err:          errUnavailable,
+			request:      &logical.Request{Operation: logical.ListOperation, Path: "wallets/"},
+			wantInternal: true,
+		},
+		{
+			name:         "storage failure on address registry",
+			operation:    "RecordAddresses",
+			err:          errUnavailable,
+			request:      &logical.Request{Operation: logical.ReadOperation, Path: "wallets/faulty/addresses/60"},
+			wantInternal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faults.Reset()
+			faults.Fail(tt.operation, tt.err)
+
+			tt.request.Storage = store
+			resp, err := b.HandleRequest(ctx, tt.request)
+
+			if tt.wantInternal {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error = %v, want internal error wrapping %v (resp = %#v)", err, tt.err, resp)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error = %v, want error response", err)
+			}
+			if resp == nil || resp.Data["error"] != tt.wantError {
+				t.Fatalf("resp = %#v, want error %q", resp, tt.wantError)
+			}
+			if resp.Data["http_status_code"] != tt.wantStatus {
+				t.Errorf("http_status_code = %v, want %v", resp.Data["http_status_code"], tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+//go:build !integration || !trustwalletcore
+
+package backend
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// harnessEngine is the engine the test harness mounts; run with
+// -tags "integration trustwalletcore" to exercise Trust Wallet Core instead
+const harnessEngine = "fake"
+
+func init() {
+	wallet.RegisterEngine(harnessEngine, func() wallet.Engine { return fakeEngine{} })
+}
+
+// errFakeUnsupported is returned by the fake engine for operations the harness does not use
+var errFakeUnsupported = errors.New("not supported by the fake engine")
+
+// fakeEngine derives keys by hashing the seed material and path, so backend tests
+// run without any real cryptography; addresses look like fake60-<hex>
+type fakeEngine struct{}
+
+func (f fakeEngine) GenerateWallet(coinType uint32, wordCount int, passphrase string) (*wallet.WalletKeys, error) {
+	if _, err := wallet.MnemonicStrength(wordCount); err != nil {
+		return nil, err
+	}
+
+	mnemonic := strings.Repeat("abandon ", wordCount-1) + "about"
+	return f.ImportWallet(mnemonic, passphrase, coinType)
+}
+
+func (f fakeEngine) ImportWallet(mnemonic string, passphrase string, coinType uint32) (*wallet.WalletKeys, error) {
+	keys, err := f.DeriveKey(mnemonic, passphrase, coinType, "")
+	if err != nil {
+		return nil, err
+	}
+	keys.Mnemonic = mnemonic
+	return keys, nil
+}
+
+func (f fakeEngine) DeriveKey(mnemonic string, passphrase string, coinType uint32, derivationPath string) (*wallet.WalletKeys, error) {
+	if _, err := wallet.MnemonicStrength(len(wallet.MnemonicWords(mnemonic))); err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidMnemonic, err)
+	}
+
+	curve, err := wallet.Curve(coinType)
+	if err != nil {
+		return nil, err
+	}
+
+	if derivationPath == "" {
+		if derivationPath, err = wallet.DefaultDerivationPath(coinType); err != nil {
+			return nil, err
+		}
+	}
+
+	privateKey := sha256.Sum256([]byte(mnemonic + "|" + passphrase + "|" + derivationPath))
+	publicKey, _ := f.PublicKey(privateKey[:], coinType)
+	address, _ := f.AddressForType(publicKey, coinType, "")
+
+	return &wallet.WalletKeys{
+		PrivateKey: privateKey[:],
+		PublicKey:  publicKey,
+		Address:    address,
+		Curve:      curve,
+	}, nil
+}
+
+func (f fakeEngine) DeriveAddress(mnemonic string, passphrase string, coinType uint32, derivationPath string) (string, error) {
+	keys, err := f.DeriveKey(mnemonic, passphrase, coinType, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	return keys.Address, nil
+}
+
+func (f fakeEngine) DeriveAddresses(mnemonic string, passphrase string, coinType uint32, addressType string, paths []string) ([]wallet.DerivedAddress, error) {
+	addresses := make([]wallet.DerivedAddress, 0, len(paths))
+	for _, path := range paths {
+		keys, err := f.DeriveKey(mnemonic, passphrase, coinType, path)
+		if err != nil {
+			return nil, err
+		}
+		address, _ := f.AddressForType(keys.PublicKey, coinType, addressType)
+		addresses = append(addresses, wallet.DerivedAddress{Path: path, PublicKey: keys.PublicKey, Address: address})
+	}
+	return addresses, nil
+}
+
+func (fakeEngine) AddressForType(publicKey []byte, coinType uint32, addressType string) (string, error) {
+	hash := sha256.Sum256(append([]byte(addressType), publicKey...))
+	return fmt.Sprintf("fake%d-%x", coinType, hash[:10]), nil
+}
+
+func (fakeEngine) PublicKey(privateKey []byte, coinType uint32) ([]byte, error) {
+	publicKey := sha256.Sum256(privateKey)
+	return publicKey[:], nil
+}
+
+func (fakeEngine) SignTransaction(privateKey []byte, coinType uint32, txData []byte) ([]byte, error) {
+	if len(txData) == 0 {
+		return nil, fmt.Errorf("%w: empty transaction data", wallet.ErrSigningFailed)
+	}
+	signature := sha256.Sum256(append(append([]byte(nil), privateKey...), txData...))
+	return signature[:], nil
+}
+
+func (fakeEngine) SignInput(privateKey []byte, coinType uint32, input []byte) (*wallet.SignedTransaction, error) {
+	return nil, errFakeUnsupported
+}
+
+func (fakeEngine) SignMessage(privateKey []byte, coinType uint32, format string, message string, address string) (string, error) {
+	return "", errFakeUnsupported
+}
+
+func (fakeEngine) VerifyMessage(publicKey []byte, coinType uint32, format string, message string, signature string) (bool, error) {
+	return false, errFakeUnsupported
+}
+
+func (fakeEngine) ExtendedPublicKey(mnemonic string, passphrase string, coinType uint32, purpose uint32, account uint32) (string, error) {
+	return "", errFakeUnsupported
+}
+
+func (fakeEngine) DeriveFromExtendedKey(xpub string, coinType uint32, addressType string, change, addressIndex uint32) (*wallet.WalletKeys, error) {
+	return nil, errFakeUnsupported
+}
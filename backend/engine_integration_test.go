@@ -0,0 +1,13 @@
+//go:build integration && trustwalletcore
+
+package backend
+
+import (
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// harnessEngine is the engine the test harness mounts; integration runs use
+// Trust Wallet Core, which is only registered with the trustwalletcore tag:
+//
+//	go test -tags "integration trustwalletcore" ./backend/
+const harnessEngine = wallet.EngineTrustWalletCore
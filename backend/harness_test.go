@@ -0,0 +1,499 @@
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/storage"
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// testHarness is a backend mounted through Factory over in-memory storage with the harness engine
+type testHarness struct {
+	backend *TrustVaultBackend
+	storage logical.Storage
+}
+
+// newTestHarness mounts a fresh backend with the engine selected by the build tags
+func newTestHarness(t *testing.T) *testHarness {
+	t.Helper()
+
+	store := &logical.InmemStorage{}
+	return &testHarness{
+		backend: testBackendWithOptions(t, store, map[string]string{EngineConfigKey: harnessEngine}),
+		storage: store,
+	}
+}
+
+// request sends a request to the backend
+func (h *testHarness) request(operation logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+	return h.backend.HandleRequest(context.Background(), &logical.Request{
+		Operation: operation,
+		Path:      path,
+		Storage:   h.storage,
+		Data:      data,
+	})
+}
+
+// mustRequest sends a request that must succeed and returns its response
+func (h *testHarness) mustRequest(t *testing.T, operation logical.Operation, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+
+	resp, err := h.request(operation, path, data)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("%s %s: resp = %#v, err = %v", operation, path, resp, err)
+	}
+	return resp
+}
+
+// expectError sends a request that must fail with an error response
+// A zero status means the response carries no http_status_code (Vault answers 400)
+func (h *testHarness) expectError(t *testing.T, operation logical.Operation, path string, data map[string]interface{}, status int, contains string) {
+	t.Helper()
+
+	resp, err := h.request(operation, path, data)
+	if err != nil {
+		t.Fatalf("%s %s: err = %v, want error response", operation, path, err)
+	}
+	if resp == nil || resp.Data["error"] == nil {
+		t.Fatalf("%s %s: resp = %#v, want error response", operation, path, resp)
+	}
+
+	if message := resp.Data["error"].(string); !strings.Contains(message, contains) {
+		t.Errorf("%s %s: error = %q, want it to contain %q", operation, path, message, contains)
+	}
+
+	var want interface{}
+	if status != 0 {
+		want = status
+	}
+	if resp.Data["http_status_code"] != want {
+		t.Errorf("%s %s: http_status_code = %v, want %v", operation, path, resp.Data["http_status_code"], want)
+	}
+}
+
+func TestFactoryRejectsUnknownEngine(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.Config = map[string]string{EngineConfigKey: "openssl"}
+
+	if _, err := Factory(context.Background(), config); !errors.Is(err, wallet.ErrUnknownEngine) {
+		t.Errorf("Factory() error = %v, want %v", err, wallet.ErrUnknownEngine)
+	}
+}
+
+func TestHarnessHealth(t *testing.T) {
+	h := newTestHarness(t)
+
+	resp := h.mustRequest(t, logical.ReadOperation, "health", nil)
+	if resp.Data["status"] != "healthy" {
+		t.Errorf("health status = %v, want healthy", resp.Data["status"])
+	}
+}
+
+func TestHarnessWalletLifecycle(t *testing.T) {
+	h := newTestHarness(t)
+
+	resp := h.mustRequest(t, logical.CreateOperation, "wallets/treasury", map[string]interface{}{"coin_type": 60})
+	created := resp.Data
+	if created["name"] != "treasury" || created["coin_type"] != uint32(60) || created["address"] == "" || created["public_key"] == "" {
+		t.Fatalf("create wallet: data = %v", created)
+	}
+	for _, secret := range []string{"mnemonic", "private_key", "passphrase"} {
+		if _, ok := created[secret]; ok {
+			t.Errorf("create wallet: response exposes %s", secret)
+		}
+	}
+
+	h.expectError(t, logical.CreateOperation, "wallets/treasury", map[string]interface{}{"coin_type": 60}, 409, "wallet already exists")
+
+	resp = h.mustRequest(t, logical.ReadOperation, "wallets/treasury", nil)
+	if resp.Data["address"] != created["address"] || resp.Data["public_key"] != created["public_key"] {
+		t.Errorf("read wallet: data = %v, want %v", resp.Data, created)
+	}
+	h.expectError(t, logical.ReadOperation, "wallets/missing", nil, 404, "wallet not found")
+
+	h.mustRequest(t, logical.CreateOperation, "wallets/operations", map[string]interface{}{"coin_type": "SOL"})
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want []string
+	}{
+		{name: "all", want: []string{"operations", "treasury"}},
+		{name: "page", data: map[string]interface{}{"offset": 1, "limit": 1}, want: []string{"treasury"}},
+		{name: "past the end", data: map[string]interface{}{"offset": 5}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run("list "+tt.name, func(t *testing.T) {
+			resp := h.mustRequest(t, logical.ListOperation, "wallets/", tt.data)
+			if keys, _ := resp.Data["keys"].([]string); !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("list wallets = %v, want %v", resp.Data["keys"], tt.want)
+			}
+		})
+	}
+	h.expectError(t, logical.ListOperation, "wallets/", map[string]interface{}{"offset": -1}, 0, "offset must be non-negative")
+
+	resp = h.mustRequest(t, logical.DeleteOperation, "wallets/treasury", nil)
+	if resp.Data["deleted"] != true {
+		t.Errorf("delete wallet: data = %v", resp.Data)
+	}
+	h.expectError(t, logical.DeleteOperation, "wallets/treasury", nil, 404, "wallet not found")
+	h.expectError(t, logical.ReadOperation, "wallets/treasury", nil, 404, "wallet not found")
+
+	// The name is free again once the wallet is deleted
+	h.mustRequest(t, logical.CreateOperation, "wallets/treasury", map[string]interface{}{"coin_type": 60})
+}
+
+func TestHarnessWalletPatch(t *testing.T) {
+	h := newTestHarness(t)
+
+	created := h.mustRequest(t, logical.CreateOperation, "wallets/payroll", map[string]interface{}{
+		"coin_type":   60,
+		"description": "monthly payroll",
+		"tags":        map[string]interface{}{"env": "prod", "team": "finance"},
+	}).Data
+	if created["version"] != uint64(1) || created["enabled"] != true || created["description"] != "monthly payroll" {
+		t.Fatalf("create wallet: data = %v", created)
+	}
+
+	resp := h.mustRequest(t, logical.PatchOperation, "wallets/payroll", map[string]interface{}{
+		"owner":    "finance-ops",
+		"tags":     map[string]interface{}{"team": nil, "tier": "hot"},
+		"metadata": map[string]interface{}{"cost_center": "42"},
+		"cas":      1,
+	})
+	want := map[string]interface{}{
+		"description": "monthly payroll",
+		"owner":       "finance-ops",
+		"tags":        map[string]string{"env": "prod", "tier": "hot"},
+		"metadata":    map[string]string{"cost_center": "42"},
+		"version":     uint64(2),
+		"address":     created["address"],
+		"public_key":  created["public_key"],
+	}
+	for field, value := range want {
+		if !reflect.DeepEqual(resp.Data[field], value) {
+			t.Errorf("patch wallet: %s = %v, want %v", field, resp.Data[field], value)
+		}
+	}
+
+	h.expectError(t, logical.PatchOperation, "wallets/payroll", map[string]interface{}{"owner": "someone", "cas": 1}, 409, "version")
+	h.expectError(t, logical.PatchOperation, "wallets/missing", map[string]interface{}{"owner": "someone"}, 404, "wallet not found")
+	h.expectError(t, logical.PatchOperation, "wallets/payroll", map[string]interface{}{"description": strings.Repeat("x", 2000)}, 0, "description")
+
+	// A disabled wallet keeps its metadata readable but refuses to use its keys
+	txData := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	h.mustRequest(t, logical.PatchOperation, "wallets/payroll", map[string]interface{}{"enabled": false})
+	h.expectError(t, logical.UpdateOperation, "wallets/payroll/sign", map[string]interface{}{"tx_data": txData}, 0, "wallet is disabled")
+	h.expectError(t, logical.ReadOperation, "wallets/payroll/addresses/ETH", nil, 0, "wallet is disabled")
+
+	resp = h.mustRequest(t, logical.ReadOperation, "wallets/payroll", nil)
+	if resp.Data["enabled"] != false || resp.Data["owner"] != "finance-ops" || resp.Data["version"] != uint64(3) {
+		t.Errorf("read disabled wallet: data = %v", resp.Data)
+	}
+
+	h.mustRequest(t, logical.PatchOperation, "wallets/payroll", map[string]interface{}{"enabled": true})
+	h.mustRequest(t, logical.UpdateOperation, "wallets/payroll/sign", map[string]interface{}{"tx_data": txData})
+}
+
+func TestHarnessConcurrentPatches(t *testing.T) {
+	h := newTestHarness(t)
+	h.mustRequest(t, logical.CreateOperation, "wallets/shared", map[string]interface{}{"coin_type": 60})
+
+	// Updates without cas that race each other are re-applied, so every tag survives
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := h.request(logical.PatchOperation, "wallets/shared", map[string]interface{}{
+				"tags": map[string]interface{}{fmt.Sprintf("writer%d", i): "yes"},
+			})
+			if err == nil && resp.IsError() {
+				err = resp.Error()
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent patch error = %v", err)
+		}
+	}
+
+	resp := h.mustRequest(t, logical.ReadOperation, "wallets/shared", nil)
+	if tags := resp.Data["tags"].(map[string]string); len(tags) != writers || resp.Data["version"] != uint64(writers+1) {
+		t.Errorf("after concurrent patches: tags = %v, version = %v", tags, resp.Data["version"])
+	}
+}
+
+func TestHarnessWalletListFilters(t *testing.T) {
+	h := newTestHarness(t)
+
+	h.mustRequest(t, logical.CreateOperation, "wallets/hot-eth", map[string]interface{}{"coin_type": "ETH", "tags": "env=prod", "labels": "payouts", "owner": "payments"})
+	h.mustRequest(t, logical.CreateOperation, "wallets/hot-sol", map[string]interface{}{"coin_type": "SOL", "tags": "env=prod", "owner": "treasury"})
+	h.mustRequest(t, logical.CreateOperation, "wallets/cold-eth", map[string]interface{}{"coin_type": 60, "coin_types": "SOL", "tags": "env=staging"})
+
+	// Labels are replaced as a whole on PATCH
+	resp := h.mustRequest(t, logical.PatchOperation, "wallets/cold-eth", map[string]interface{}{"labels": "reserve,payouts,reserve"})
+	if labels := resp.Data["labels"]; !reflect.DeepEqual(labels, []string{"payouts", "reserve"}) {
+		t.Errorf("patch labels = %v, want [payouts reserve]", labels)
+	}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want []string
+	}{
+		{name: "coin", data: map[string]interface{}{"coin_type": "SOL"}, want: []string{"cold-eth", "hot-sol"}},
+		{name: "tag", data: map[string]interface{}{"tag": "env=prod"}, want: []string{"hot-eth", "hot-sol"}},
+		{name: "tag and coin", data: map[string]interface{}{"tag": "env=prod", "coin_type": 60}, want: []string{"hot-eth"}},
+		{name: "label", data: map[string]interface{}{"label": "payouts"}, want: []string{"cold-eth", "hot-eth"}},
+		{name: "owner", data: map[string]interface{}{"owner": "treasury"}, want: []string{"hot-sol"}},
+		{name: "owner and tag", data: map[string]interface{}{"owner": "payments", "tag": "env=staging"}, want: nil},
+		{name: "prefix", data: map[string]interface{}{"prefix": "hot-"}, want: []string{"hot-eth", "hot-sol"}},
+		{name: "created before", data: map[string]interface{}{"created_before": "2000-01-01T00:00:00Z"}, want: nil},
+		{name: "created after", data: map[string]interface{}{"created_after": "2000-01-01T00:00:00Z", "prefix": "cold"}, want: []string{"cold-eth"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := h.mustRequest(t, logical.ListOperation, "wallets/", tt.data)
+			if keys, _ := resp.Data["keys"].([]string); !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("list wallets = %v, want %v", resp.Data["keys"], tt.want)
+			}
+		})
+	}
+
+	resp = h.mustRequest(t, logical.ListOperation, "wallets/", map[string]interface{}{"tag": "env=prod", "include_metadata": true})
+	keyInfo, _ := resp.Data["key_info"].(map[string]interface{})
+	info, _ := keyInfo["hot-sol"].(map[string]interface{})
+	if !reflect.DeepEqual(resp.Data["keys"], []string{"hot-eth", "hot-sol"}) || info["coin_symbol"] != "SOL" {
+		t.Errorf("list wallets with metadata: data = %v", resp.Data)
+	}
+
+	h.expectError(t, logical.ListOperation, "wallets/", map[string]interface{}{"coin_type": "nope"}, 0, "nope")
+	h.expectError(t, logical.ListOperation, "wallets/", map[string]interface{}{"created_after": "yesterday"}, 0, "created_after")
+}
+
+func TestHarnessCreateValidation(t *testing.T) {
+	h := newTestHarness(t)
+
+	tests := []struct {
+		name     string
+		path     string
+		data     map[string]interface{}
+		contains string
+	}{
+		{name: "missing coin type", path: "wallets/validation", data: map[string]interface{}{}, contains: "coin_type is required"},
+		{name: "unknown coin", path: "wallets/validation", data: map[string]interface{}{"coin_type": "nope"}, contains: "nope"},
+		{name: "invalid word count", path: "wallets/validation", data: map[string]interface{}{"coin_type": 60, "word_count": 13}, contains: "word count"},
+		{name: "invalid mnemonic", path: "wallets/validation", data: map[string]interface{}{"coin_type": 60, "mnemonic": "abandon abandon"}, contains: "mnemonic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.expectError(t, logical.CreateOperation, tt.path, tt.data, 0, tt.contains)
+		})
+	}
+}
+
+func TestHarnessSign(t *testing.T) {
+	h := newTestHarness(t)
+	h.mustRequest(t, logical.CreateOperation, "wallets/signer", map[string]interface{}{"coin_type": 60})
+
+	txData := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	resp := h.mustRequest(t, logical.UpdateOperation, "wallets/signer/sign", map[string]interface{}{"tx_data": txData})
+	signature, _ := resp.Data["signed_tx"].(string)
+	if signature == "" {
+		t.Fatalf("sign: data = %v", resp.Data)
+	}
+
+	// Signing is deterministic for the same key and data
+	resp = h.mustRequest(t, logical.UpdateOperation, "wallets/signer/sign", map[string]interface{}{"tx_data": txData})
+	if resp.Data["signed_tx"] != signature {
+		t.Errorf("sign twice: %v, want %v", resp.Data["signed_tx"], signature)
+	}
+
+	// Another key of the wallet yields another signature
+	resp = h.mustRequest(t, logical.UpdateOperation, "wallets/signer/sign", map[string]interface{}{"tx_data": txData, "address_index": 1})
+	if resp.Data["signed_tx"] == signature {
+		t.Errorf("sign with address_index 1 reused the default key")
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		data     map[string]interface{}
+		status   int
+		contains string
+	}{
+		{name: "missing wallet", path: "wallets/missing/sign", data: map[string]interface{}{"tx_data": txData}, status: 404, contains: "wallet not found"},
+		{name: "missing data", path: "wallets/signer/sign", data: map[string]interface{}{}, contains: "tx_data"},
+		{name: "invalid base64", path: "wallets/signer/sign", data: map[string]interface{}{"tx_data": "not base64!"}, contains: "base64"},
+		{name: "invalid derivation path", path: "wallets/signer/sign", data: map[string]interface{}{"tx_data": txData, "derivation_path": "44'/60'"}, contains: "must start with 'm/'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.expectError(t, logical.UpdateOperation, tt.path, tt.data, tt.status, tt.contains)
+		})
+	}
+}
+
+func TestHarnessAddress(t *testing.T) {
+	h := newTestHarness(t)
+	resp := h.mustRequest(t, logical.CreateOperation, "wallets/deposits", map[string]interface{}{"coin_type": 60})
+	walletAddress := resp.Data["address"]
+
+	resp = h.mustRequest(t, logical.ReadOperation, "wallets/deposits/addresses/60", nil)
+	if resp.Data["address"] != walletAddress {
+		t.Errorf("default address = %v, want the wallet address %v", resp.Data["address"], walletAddress)
+	}
+
+	resp = h.mustRequest(t, logical.ReadOperation, "wallets/deposits/addresses/ETH", map[string]interface{}{"derivation_path": "m/44'/60'/0'/0/7"})
+	if resp.Data["address"] == walletAddress || resp.Data["derivation_path"] != "m/44'/60'/0'/0/7" {
+		t.Errorf("address at index 7: data = %v", resp.Data)
+	}
+
+	// Both addresses were recorded in the wallet's registry
+	resp = h.mustRequest(t, logical.ListOperation, "wallets/deposits/addresses/", nil)
+	if keys, _ := resp.Data["keys"].([]string); len(keys) != 2 {
+		t.Errorf("recorded addresses = %v, want 2", resp.Data["keys"])
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		data     map[string]interface{}
+		status   int
+		contains string
+	}{
+		{name: "missing wallet", path: "wallets/missing/addresses/60", status: 404, contains: "wallet not found"},
+		{name: "unknown coin", path: "wallets/deposits/addresses/nope", contains: "nope"},
+		{name: "coin not enabled", path: "wallets/deposits/addresses/BTC", contains: "coin type not enabled for wallet: 0"},
+		{name: "invalid derivation path", path: "wallets/deposits/addresses/60", data: map[string]interface{}{"derivation_path": "m/44'/60'/0'/0/x"}, contains: "invalid character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.expectError(t, logical.ReadOperation, tt.path, tt.data, tt.status, tt.contains)
+		})
+	}
+}
+
+func TestHarnessRetiredKeyVersion(t *testing.T) {
+	h := newTestHarness(t)
+	ctx := context.Background()
+
+	h.mustRequest(t, logical.CreateOperation, "wallets/legacy", map[string]interface{}{"coin_type": 60})
+	backup, err := h.storage.Get(ctx, "wallets/legacy")
+	if err != nil || backup == nil {
+		t.Fatalf("Get(wallets/legacy) = %v, %v", backup, err)
+	}
+
+	// Rotate, rewrap every wallet onto version 2 and retire version 1
+	h.mustRequest(t, logical.UpdateOperation, "keys/rotate", nil)
+	h.mustRequest(t, logical.UpdateOperation, "keys/rewrap", nil)
+	for i := 0; i < 10; i++ {
+		resp := h.mustRequest(t, logical.ReadOperation, "keys/rewrap/status", nil)
+		if resp.Data["status"] == storage.RewrapStatusCompleted {
+			break
+		}
+		if err := h.backend.periodicFunc(ctx, &logical.Request{Storage: h.storage}); err != nil {
+			t.Fatalf("periodicFunc() error = %v", err)
+		}
+	}
+	h.mustRequest(t, logical.UpdateOperation, "keys/config", map[string]interface{}{"min_decryption_version": 2})
+	h.expectError(t, logical.UpdateOperation, "keys/config", map[string]interface{}{"min_decryption_version": 7}, 400, "invalid key version: 7")
+
+	// A wallet restored from a backup taken before the rewrap is still encrypted with version 1
+	if err := h.storage.Put(ctx, backup); err != nil {
+		t.Fatalf("Put(wallets/legacy) error = %v", err)
+	}
+
+	// Metadata stays readable, but nothing that needs the key material
+	h.mustRequest(t, logical.ReadOperation, "wallets/legacy", nil)
+	h.expectError(t, logical.ReadOperation, "wallets/legacy/addresses/60", nil, 409, "retired key version")
+
+	resp, _ := h.request(logical.ReadOperation, "wallets/legacy/addresses/60", nil)
+	if message, _ := resp.Data["error"].(string); strings.Contains(message, "failed to") || strings.Contains(message, ": 1") {
+		t.Errorf("error = %q, want it without the internal error chain", message)
+	}
+}
+
+func TestValidateWalletName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "simple", input: "treasury"},
+		{name: "dashes and dots", input: "cold-storage.v2"},
+		{name: "empty", input: "", wantErr: "wallet name is required"},
+		{name: "too long", input: strings.Repeat("a", 256), wantErr: "maximum length"},
+		{name: "path traversal", input: "a..b", wantErr: "invalid characters"},
+		{name: "slash", input: "a/b", wantErr: "invalid characters"},
+		{name: "backslash", input: `a\b`, wantErr: "invalid characters"},
+		{name: "control character", input: "a\tb", wantErr: "control characters"},
+		{name: "delete character", input: "a\x7fb", wantErr: "control characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWalletName(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateWalletName(%q) error = %v", tt.input, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateWalletName(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDerivationPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty uses the default", input: ""},
+		{name: "bip44", input: "m/44'/60'/0'/0/0"},
+		{name: "hardened solana", input: "m/44'/501'/0'/0'"},
+		{name: "missing root", input: "44'/60'/0'/0/0", wantErr: "must start with 'm/'"},
+		{name: "h notation", input: "m/44h/60h", wantErr: "invalid character: h"},
+		{name: "negative index", input: "m/44'/-1", wantErr: "invalid character: -"},
+		{name: "too long", input: "m/" + strings.Repeat("0/", 50), wantErr: "maximum length"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDerivationPath(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateDerivationPath(%q) error = %v", tt.input, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateDerivationPath(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
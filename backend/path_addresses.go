@@ -0,0 +1,331 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/service"
+	"github.com/sina-haseli/trust_vault/storage"
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// Address batch modes
+const (
+	batchModeRange    = "range"
+	batchModeGapLimit = "gap_limit"
+)
+
+// pathWalletAddresses returns the path configuration for batch address derivation
+// POST /trust-vault/wallets/:name/addresses
+// LIST /trust-vault/wallets/:name/addresses
+func (b *TrustVaultBackend) pathWalletAddresses() *framework.Path {
+	return &framework.Path{
+		Pattern: "wallets/" + framework.GenericNameRegex("name") + "/addresses/?$",
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the wallet",
+				Required:    true,
+			},
+			"coin_type": {
+				Type:        framework.TypeString,
+				Description: "Coin type, symbol or ID of the addresses (must be enabled for the wallet; defaults to the wallet's creation coin)",
+				Required:    false,
+			},
+			"address_type": {
+				Type:        framework.TypeString,
+				Description: "Bitcoin address type: p2pkh, p2sh-p2wpkh, p2wpkh or p2tr (default: the wallet's address type)",
+				Required:    false,
+			},
+			"account": {
+				Type:        framework.TypeInt,
+				Description: "BIP44 account (default: 0)",
+				Required:    false,
+			},
+			"change": {
+				Type:        framework.TypeInt,
+				Description: "BIP44 change level (0 external, 1 internal; default: 0)",
+				Required:    false,
+			},
+			"start_index": {
+				Type:        framework.TypeInt,
+				Description: "First address index (default: 0)",
+				Required:    false,
+			},
+			"count": {
+				Type:        framework.TypeInt,
+				Description: fmt.Sprintf("Number of addresses to derive, at most %d", service.MaxAddressBatch),
+				Default:     service.GapLimit,
+			},
+			"mode": {
+				Type:        framework.TypeString,
+				Description: "range returns count consecutive indices; gap_limit returns the first count indices not listed in used_indices",
+				Default:     batchModeRange,
+			},
+			"used_indices": {
+				Type:        framework.TypeCommaIntSlice,
+				Description: "Address indices already in use, skipped in gap_limit mode",
+				Required:    false,
+			},
+			"passphrase": {
+				Type:        framework.TypeString,
+				Description: "BIP39 passphrase, required for wallets created with store_passphrase=false",
+				Required:    false,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleWalletAddresses,
+				Summary:  "Derive a batch of addresses",
+			},
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.handleWalletAddressList,
+				Summary:  "List the registry indexes of addresses handed out by the wallet",
+			},
+		},
+		HelpSynopsis:    "Derive many addresses of a wallet in one request",
+		HelpDescription: "Derives the addresses at start_index onwards on one account/change chain, decrypting the wallet once for the whole batch. In gap_limit mode the indices listed in used_indices are skipped, returning the next unused addresses. Every address is recorded in the wallet's address registry; LIST returns the registry indexes.",
+	}
+}
+
+// handleWalletAddresses handles batch address derivation requests
+func (b *TrustVaultBackend) handleWalletAddresses(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Validate wallet name
+	if err := validateWalletName(name); err != nil {
+		b.logger.Warn("invalid wallet name provided for address derivation", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	batch := service.AddressBatch{
+		CoinType:    service.DefaultCoinType,
+		AddressType: data.Get("address_type").(string),
+		Count:       data.Get("count").(int),
+		Passphrase:  data.Get("passphrase").(string),
+	}
+
+	coinType, ok, err := coinField(data, "coin_type")
+	if err != nil {
+		b.logger.Warn("invalid coin type provided", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if ok {
+		batch.CoinType = coinType
+	}
+
+	if batch.AddressType != "" && batch.CoinType != service.DefaultCoinType {
+		if _, err := wallet.AddressTypePurpose(batch.CoinType, batch.AddressType); err != nil {
+			b.logger.Warn("invalid address type provided", "coin_type", batch.CoinType, "address_type", batch.AddressType)
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	for field, target := range map[string]*uint32{
+		"account":     &batch.Account,
+		"change":      &batch.Change,
+		"start_index": &batch.StartIndex,
+	} {
+		if *target, err = indexField(data, field); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	if batch.Count < 1 || batch.Count > service.MaxAddressBatch {
+		return logical.ErrorResponse("count must be between 1 and %d", service.MaxAddressBatch), nil
+	}
+
+	switch mode := data.Get("mode").(string); mode {
+	case batchModeRange:
+		if _, ok := data.GetOk("used_indices"); ok {
+			return logical.ErrorResponse("used_indices requires mode=%s", batchModeGapLimit), nil
+		}
+	case batchModeGapLimit:
+		batch.SkipUsed = true
+		for _, index := range data.Get("used_indices").([]int) {
+			if index < 0 || index > math.MaxInt32 {
+				return logical.ErrorResponse("used_indices must be between 0 and %d", math.MaxInt32), nil
+			}
+			batch.UsedIndices = append(batch.UsedIndices, uint32(index))
+		}
+	default:
+		return logical.ErrorResponse("invalid mode: %q (must be %s or %s)", mode, batchModeRange, batchModeGapLimit), nil
+	}
+
+	addresses, err := b.walletService.DeriveAddresses(ctx, name, batch)
+	if err != nil {
+		b.logger.Error("failed to derive address batch", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	results := make([]map[string]interface{}, 0, len(addresses))
+	for _, address := range addresses {
+		results = append(results, map[string]interface{}{
+			"address_index":   address.Index,
+			"coin_type":       address.CoinType,
+			"coin_symbol":     wallet.CoinSymbol(address.CoinType),
+			"address":         address.Address,
+			"public_key":      address.PublicKey,
+			"derivation_path": address.DerivationPath,
+			"registry_index":  address.RegistryIndex,
+		})
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"addresses": results,
+			"account":   batch.Account,
+			"change":    batch.Change,
+		},
+	}, nil
+}
+
+// handleWalletAddressList handles listing a wallet's address registry
+func (b *TrustVaultBackend) handleWalletAddressList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Validate wallet name
+	if err := validateWalletName(name); err != nil {
+		b.logger.Warn("invalid wallet name provided for address listing", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	indexes, err := b.walletService.ListAddresses(ctx, name)
+	if err != nil {
+		b.logger.Error("failed to list addresses", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	return logical.ListResponse(indexes), nil
+}
+
+// pathLookupAddress returns the path configuration for the address reverse index
+// GET /trust-vault/lookup/address/:address
+func (b *TrustVaultBackend) pathLookupAddress() *framework.Path {
+	return &framework.Path{
+		Pattern: "lookup/address/" + framework.GenericNameRegex("address"),
+		Fields: map[string]*framework.FieldSchema{
+			"address": {
+				Type:        framework.TypeString,
+				Description: "Address to look up",
+				Required:    true,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleLookupAddress,
+				Summary:  "Find the wallet and derivation path of an address",
+			},
+		},
+		HelpSynopsis:    "Find which wallet handed out an address",
+		HelpDescription: "Returns the wallet name, coin type, derivation path, label and metadata recorded when the address was handed out. Hex addresses are matched case-insensitively. If wallets sharing a seed handed out the same address, the first wallet by name is returned and all of them are listed in wallets.",
+	}
+}
+
+// handleLookupAddress handles address reverse lookups
+func (b *TrustVaultBackend) handleLookupAddress(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	address := data.Get("address").(string)
+	if address == "" {
+		return logical.ErrorResponse("address is required"), nil
+	}
+
+	records, err := b.walletService.LookupAddress(ctx, address)
+	if err != nil {
+		b.logger.Debug("address lookup failed", "error", err)
+		return b.handleError(err)
+	}
+
+	wallets := make([]string, 0, len(records))
+	for _, record := range records {
+		wallets = append(wallets, record.Wallet)
+	}
+
+	respData := addressRecordResponseData(records[0])
+	respData["wallet"] = records[0].Wallet
+	respData["wallets"] = wallets
+
+	return &logical.Response{
+		Data: respData,
+	}, nil
+}
+
+// pathValidateAddress returns the path configuration for address validation
+// POST /trust-vault/addresses/validate
+func (b *TrustVaultBackend) pathValidateAddress() *framework.Path {
+	return &framework.Path{
+		Pattern: "addresses/validate$",
+		Fields: map[string]*framework.FieldSchema{
+			"coin": {
+				Type:        framework.TypeString,
+				Description: "Coin type, ticker symbol or Trust Wallet Core coin ID of the address's chain",
+				Required:    true,
+			},
+			"address": {
+				Type:        framework.TypeString,
+				Description: "Address to validate",
+				Required:    true,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleValidateAddress,
+				Summary:  "Validate an address for a chain",
+			},
+		},
+		HelpSynopsis:    "Check that a destination address is well-formed for a chain",
+		HelpDescription: "Validates an address with Trust Wallet Core and returns its normalized form (EIP-55 checksummed for EVM chains) and, for Bitcoin, its address type. Mixed-case EVM addresses must carry a correct EIP-55 checksum. An invalid address is not an error: valid is false and reason explains why.",
+	}
+}
+
+// handleValidateAddress handles address validation requests
+func (b *TrustVaultBackend) handleValidateAddress(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	address := data.Get("address").(string)
+	if address == "" {
+		return logical.ErrorResponse("address is required"), nil
+	}
+
+	coinStr := data.Get("coin").(string)
+	if coinStr == "" {
+		return logical.ErrorResponse("coin is required"), nil
+	}
+	coinType, err := resolveCoin(coinStr)
+	if err != nil {
+		b.logger.Warn("invalid coin provided for address validation", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	coin, _ := wallet.LookupCoin(coinType)
+
+	result := coin.ValidateAddress(address)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"coin_type":          coin.CoinType,
+			"coin_symbol":        coin.Symbol,
+			"address":            address,
+			"valid":              result.Valid,
+			"normalized_address": result.Normalized,
+			"address_type":       result.AddressType,
+			"reason":             result.Reason,
+		},
+	}, nil
+}
+
+// addressRecordResponseData returns an address registry record for API responses
+func addressRecordResponseData(record *storage.AddressRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"address":         record.Address,
+		"coin_type":       record.CoinType,
+		"coin_symbol":     wallet.CoinSymbol(record.CoinType),
+		"derivation_path": record.DerivationPath,
+		"registry_index":  record.Index,
+		"label":           record.Label,
+		"metadata":        record.Metadata,
+		"created_at":      record.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
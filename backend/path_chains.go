@@ -0,0 +1,210 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/storage"
+)
+
+// defaultChainSymbol is the native symbol of chain profiles created without one
+const defaultChainSymbol = "ETH"
+
+// pathChains returns the path configuration for managing EVM chain profiles
+// GET/POST/DELETE /trust-vault/chains/:name
+func (b *TrustVaultBackend) pathChains() *framework.Path {
+	return &framework.Path{
+		Pattern: "chains/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the chain profile (e.g., bsc, polygon, arbitrum)",
+				Required:    true,
+			},
+			"chain_id": {
+				Type:        framework.TypeInt,
+				Description: "EIP-155 chain ID (e.g., 56 for BNB Smart Chain, 137 for Polygon)",
+				Required:    false,
+			},
+			"symbol": {
+				Type:        framework.TypeString,
+				Description: "Native currency symbol (default: ETH)",
+				Required:    false,
+			},
+			"eip1559": {
+				Type:        framework.TypeBool,
+				Description: "Whether the chain accepts EIP-1559 fee fields (default: true)",
+				Required:    false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.CreateOperation: &framework.PathOperation{
+				Callback: b.handleChainWrite,
+				Summary:  "Create an EVM chain profile",
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleChainWrite,
+				Summary:  "Update an EVM chain profile",
+			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleChainRead,
+				Summary:  "Read an EVM chain profile",
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.handleChainDelete,
+				Summary:  "Delete an EVM chain profile",
+			},
+		},
+		ExistenceCheck:  b.handleChainExistenceCheck,
+		HelpSynopsis:    "Manage EVM chain profiles",
+		HelpDescription: "Chain profiles name the EVM chains that Ethereum (coin type 60) keys sign for. Pass chain=<name> to wallets/:name/sign/json to sign a replay-protected transaction with the profile's chain ID. Updates change only the supplied fields.",
+	}
+}
+
+// pathChainList returns the path configuration for listing EVM chain profiles
+// LIST /trust-vault/chains
+func (b *TrustVaultBackend) pathChainList() *framework.Path {
+	return &framework.Path{
+		Pattern: "chains/?$",
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.handleChainList,
+				Summary:  "List EVM chain profiles",
+			},
+		},
+		HelpSynopsis:    "List EVM chain profiles",
+		HelpDescription: "Returns the names of all EVM chain profiles on this mount.",
+	}
+}
+
+// handleChainExistenceCheck checks if a chain profile exists
+func (b *TrustVaultBackend) handleChainExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	_, err := b.storageService.GetChain(ctx, data.Get("name").(string))
+	if errors.Is(err, storage.ErrChainNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// handleChainWrite handles chain profile create and update requests
+// Only the supplied fields of an existing profile are changed
+func (b *TrustVaultBackend) handleChainWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	if err := validateChainName(name); err != nil {
+		b.logger.Warn("invalid chain name provided", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	chain, err := b.storageService.GetChain(ctx, name)
+	switch {
+	case errors.Is(err, storage.ErrChainNotFound):
+		if _, ok := data.GetOk("chain_id"); !ok {
+			return logical.ErrorResponse("chain_id is required"), nil
+		}
+		chain = &storage.ChainProfile{Name: name, Symbol: defaultChainSymbol, EIP1559: true}
+	case err != nil:
+		b.logger.Error("failed to read chain profile", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	if raw, ok := data.GetOk("chain_id"); ok {
+		chainID := raw.(int)
+		if chainID <= 0 {
+			return logical.ErrorResponse("chain_id must be positive"), nil
+		}
+		chain.ChainID = uint64(chainID)
+	}
+	if raw, ok := data.GetOk("symbol"); ok {
+		symbol := strings.TrimSpace(raw.(string))
+		if symbol == "" {
+			return logical.ErrorResponse("symbol cannot be empty"), nil
+		}
+		chain.Symbol = symbol
+	}
+	if raw, ok := data.GetOk("eip1559"); ok {
+		chain.EIP1559 = raw.(bool)
+	}
+
+	if err := b.storageService.PutChain(ctx, chain); err != nil {
+		b.logger.Error("failed to store chain profile", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	return &logical.Response{
+		Data: chainResponseData(chain),
+	}, nil
+}
+
+// handleChainRead handles chain profile read requests
+func (b *TrustVaultBackend) handleChainRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	chain, err := b.storageService.GetChain(ctx, name)
+	if err != nil {
+		b.logger.Debug("failed to read chain profile", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	return &logical.Response{
+		Data: chainResponseData(chain),
+	}, nil
+}
+
+// handleChainDelete handles chain profile delete requests
+func (b *TrustVaultBackend) handleChainDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	if err := b.storageService.DeleteChain(ctx, name); err != nil {
+		b.logger.Warn("failed to delete chain profile", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	return nil, nil
+}
+
+// handleChainList handles chain profile list requests
+func (b *TrustVaultBackend) handleChainList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	names, err := b.storageService.ListChains(ctx)
+	if err != nil {
+		b.logger.Error("failed to list chain profiles", "error", err)
+		return b.handleError(err)
+	}
+
+	return logical.ListResponse(names), nil
+}
+
+// chainResponseData returns a chain profile for API responses
+func chainResponseData(chain *storage.ChainProfile) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       chain.Name,
+		"chain_id":   chain.ChainID,
+		"symbol":     chain.Symbol,
+		"eip1559":    chain.EIP1559,
+		"created_at": chain.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"updated_at": chain.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// validateChainName validates a chain profile name
+func validateChainName(name string) error {
+	if name == "" {
+		return errors.New("chain name is required")
+	}
+
+	if len(name) > 64 {
+		return errors.New("chain name exceeds maximum length of 64 characters")
+	}
+
+	if strings.ContainsAny(name, "/\\") || strings.Contains(name, "..") {
+		return errors.New("chain name contains invalid characters")
+	}
+
+	return nil
+}
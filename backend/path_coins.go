@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// pathCoins returns the path configuration for the coin registry
+// GET /trust-vault/coins
+func (b *TrustVaultBackend) pathCoins() *framework.Path {
+	return &framework.Path{
+		Pattern: "coins/?$",
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleCoinsRead,
+				Summary:  "List supported coins",
+			},
+		},
+		HelpSynopsis:    "List the coins supported by Trust Wallet Core",
+		HelpDescription: "Returns every coin type known to the bundled Trust Wallet Core with its symbol, curve, default derivation path and decimals, and whether the mount's enabled_coin_types allow-list permits it.",
+	}
+}
+
+// handleCoinsRead handles coin registry read requests
+func (b *TrustVaultBackend) handleCoinsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.storageService.GetMountConfig(ctx)
+	if err != nil {
+		b.logger.Error("failed to read mount config", "error", err)
+		return b.handleError(err)
+	}
+
+	coins := wallet.Coins()
+	results := make([]map[string]interface{}, 0, len(coins))
+	for _, coin := range coins {
+		results = append(results, map[string]interface{}{
+			"coin_type":       coin.CoinType,
+			"id":              coin.ID,
+			"name":            coin.Name,
+			"symbol":          coin.Symbol,
+			"decimals":        coin.Decimals,
+			"curve":           coin.Curve,
+			"derivation_path": coin.DerivationPath,
+			"enabled":         config.CoinEnabled(coin.CoinType),
+		})
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"coins": results,
+		},
+	}, nil
+}
+
+// resolveCoin resolves a coin type, ticker symbol or Trust Wallet Core coin ID
+func resolveCoin(coin string) (uint32, error) {
+	info, err := wallet.ResolveCoin(coin)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported coin type: %q (use a coin type, symbol or ID from GET /trust-vault/coins)", coin)
+	}
+
+	return info.CoinType, nil
+}
+
+// coinField resolves an optional coin field; ok is false if the field is not set
+func coinField(data *framework.FieldData, field string) (coinType uint32, ok bool, err error) {
+	raw, ok := data.GetOk(field)
+	if !ok {
+		return 0, false, nil
+	}
+
+	coinType, err = resolveCoin(raw.(string))
+	return coinType, true, err
+}
+
+// coinListField resolves a comma-separated list of coins
+func coinListField(data *framework.FieldData, field string) ([]uint32, error) {
+	var coinTypes []uint32
+	for _, coin := range data.Get(field).([]string) {
+		coinType, err := resolveCoin(coin)
+		if err != nil {
+			return nil, err
+		}
+		coinTypes = append(coinTypes, coinType)
+	}
+
+	return coinTypes, nil
+}
@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// pathConfig returns the path configuration for mount-level settings
+// GET/POST /trust-vault/config
+func (b *TrustVaultBackend) pathConfig() *framework.Path {
+	return &framework.Path{
+		Pattern: "config$",
+		Fields: map[string]*framework.FieldSchema{
+			"default_word_count": {
+				Type:        framework.TypeInt,
+				Description: "Number of mnemonic words generated when a wallet is created without word_count: 12, 15, 18, 21 or 24",
+				Required:    false,
+			},
+			"enabled_coin_types": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Coin types, symbols or IDs usable on this mount; an empty list enables every coin in GET /trust-vault/coins",
+				Required:    false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleConfigRead,
+				Summary:  "Read mount settings",
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleConfigWrite,
+				Summary:  "Update mount settings",
+			},
+		},
+		HelpSynopsis:    "Manage mount-level defaults for new wallets",
+		HelpDescription: "Reads and sets defaults applied to wallets created on this mount, such as the number of words of generated mnemonics, and the allow-list of coin types that wallets may be created, derived and signed for.",
+	}
+}
+
+// handleConfigRead handles mount settings read requests
+func (b *TrustVaultBackend) handleConfigRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.storageService.GetMountConfig(ctx)
+	if err != nil {
+		b.logger.Error("failed to read mount config", "error", err)
+		return b.handleError(err)
+	}
+
+	defaultWordCount := config.DefaultWordCount
+	if defaultWordCount == 0 {
+		defaultWordCount = wallet.DefaultWordCount
+	}
+
+	enabledCoinTypes := config.EnabledCoinTypes
+	if enabledCoinTypes == nil {
+		enabledCoinTypes = []uint32{}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"default_word_count": defaultWordCount,
+			"enabled_coin_types": enabledCoinTypes,
+		},
+	}, nil
+}
+
+// handleConfigWrite handles mount settings update requests
+// Only the supplied settings are changed
+func (b *TrustVaultBackend) handleConfigWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.storageService.GetMountConfig(ctx)
+	if err != nil {
+		b.logger.Error("failed to read mount config", "error", err)
+		return b.handleError(err)
+	}
+
+	if wordCountRaw, ok := data.GetOk("default_word_count"); ok {
+		wordCount := wordCountRaw.(int)
+		if _, err := wallet.MnemonicStrength(wordCount); err != nil {
+			b.logger.Warn("invalid default word count provided", "word_count", wordCount)
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		config.DefaultWordCount = wordCount
+	}
+
+	if _, ok := data.GetOk("enabled_coin_types"); ok {
+		coinTypes, err := coinListField(data, "enabled_coin_types")
+		if err != nil {
+			b.logger.Warn("invalid enabled coin type provided", "error", err)
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		config.EnabledCoinTypes = coinTypes
+	}
+
+	if err := b.storageService.PutMountConfig(ctx, config); err != nil {
+		b.logger.Error("failed to update mount config", "error", err)
+		return b.handleError(err)
+	}
+
+	return b.handleConfigRead(ctx, req, data)
+}
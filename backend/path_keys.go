@@ -0,0 +1,209 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/storage"
+)
+
+// pathKeysRotate returns the path configuration for rotating the storage encryption key
+// POST /trust-vault/keys/rotate
+func (b *TrustVaultBackend) pathKeysRotate() *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/rotate$",
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleKeysRotate,
+				Summary:  "Rotate the storage encryption key",
+			},
+		},
+		HelpSynopsis:    "Add a new active version of the storage encryption key",
+		HelpDescription: "Generates a new data-encryption key version used for all new writes. Existing wallets stay readable with their original key version until they are rewritten.",
+	}
+}
+
+// handleKeysRotate handles encryption key rotation requests
+func (b *TrustVaultBackend) handleKeysRotate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.logger.Info("rotating encryption key")
+
+	version, err := b.storageService.RotateKey(ctx)
+	if err != nil {
+		b.logger.Error("failed to rotate encryption key", "error", err)
+		return b.handleError(err)
+	}
+
+	b.logger.Info("encryption key rotated successfully", "version", version)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"latest_version": version,
+		},
+	}, nil
+}
+
+// pathKeysConfig returns the path configuration for keyring settings
+// GET/POST /trust-vault/keys/config
+func (b *TrustVaultBackend) pathKeysConfig() *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/config$",
+		Fields: map[string]*framework.FieldSchema{
+			"min_decryption_version": {
+				Type:        framework.TypeInt,
+				Description: "Minimum key version allowed for decryption. Can only be raised after a rewrap onto that version has completed.",
+				Required:    false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleKeysConfigRead,
+				Summary:  "Read keyring settings",
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleKeysConfigWrite,
+				Summary:  "Update keyring settings",
+			},
+		},
+		HelpSynopsis:    "Manage storage encryption keyring settings",
+		HelpDescription: "Reads the latest key version and sets min_decryption_version, which refuses decryption with retired key versions.",
+	}
+}
+
+// handleKeysConfigRead handles keyring settings read requests
+func (b *TrustVaultBackend) handleKeysConfigRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := b.storageService.GetKeyConfig(ctx)
+	if err != nil {
+		b.logger.Error("failed to read keyring settings", "error", err)
+		return b.handleError(err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"latest_version":         config.LatestVersion,
+			"min_decryption_version": config.MinDecryptionVersion,
+		},
+	}, nil
+}
+
+// handleKeysConfigWrite handles keyring settings update requests
+func (b *TrustVaultBackend) handleKeysConfigWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	minVersionRaw, ok := data.GetOk("min_decryption_version")
+	if !ok {
+		return logical.ErrorResponse("min_decryption_version is required"), nil
+	}
+	minVersion := minVersionRaw.(int)
+
+	b.logger.Info("updating minimum decryption version", "version", minVersion)
+
+	if err := b.storageService.SetMinDecryptionVersion(ctx, minVersion); err != nil {
+		b.logger.Error("failed to update minimum decryption version", "version", minVersion, "error", err)
+		return b.handleError(err)
+	}
+
+	return b.handleKeysConfigRead(ctx, req, data)
+}
+
+// pathKeysRewrap returns the path configuration for starting a rewrap job
+// POST /trust-vault/keys/rewrap
+func (b *TrustVaultBackend) pathKeysRewrap() *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/rewrap$",
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleKeysRewrap,
+				Summary:  "Rewrap all wallets onto the latest encryption key",
+			},
+		},
+		HelpSynopsis:    "Start re-encrypting all wallets with the latest key version",
+		HelpDescription: "Starts a background job that re-encrypts every stored wallet with the latest key version. Progress is checkpointed, so the job resumes after restarts. If a job is already running, its status is returned.",
+	}
+}
+
+// handleKeysRewrap handles rewrap start requests
+func (b *TrustVaultBackend) handleKeysRewrap(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.logger.Info("starting wallet rewrap")
+
+	state, err := b.storageService.StartRewrap(ctx)
+	if err != nil {
+		b.logger.Error("failed to start wallet rewrap", "error", err)
+		return b.handleError(err)
+	}
+
+	return rewrapStateResponse(state), nil
+}
+
+// pathKeysRewrapStatus returns the path configuration for rewrap progress
+// GET /trust-vault/keys/rewrap/status
+func (b *TrustVaultBackend) pathKeysRewrapStatus() *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/rewrap/status$",
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleKeysRewrapStatus,
+				Summary:  "Read rewrap progress",
+			},
+		},
+		HelpSynopsis:    "Read the progress of the wallet rewrap job",
+		HelpDescription: "Returns the status, target key version and counters of the current or last rewrap job.",
+	}
+}
+
+// handleKeysRewrapStatus handles rewrap progress requests
+func (b *TrustVaultBackend) handleKeysRewrapStatus(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	state, err := b.storageService.GetRewrapState(ctx)
+	if err != nil {
+		b.logger.Error("failed to read rewrap status", "error", err)
+		return b.handleError(err)
+	}
+
+	return rewrapStateResponse(state), nil
+}
+
+// rewrapStateResponse formats rewrap progress for API responses
+func rewrapStateResponse(state *storage.RewrapState) *logical.Response {
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"status":         state.Status,
+			"target_version": state.TargetVersion,
+			"processed":      state.Processed,
+			"rewrapped":      state.Rewrapped,
+			"failed":         state.Failed,
+		},
+	}
+
+	if state.LastError != "" {
+		resp.Data["last_error"] = state.LastError
+	}
+	if !state.StartedAt.IsZero() {
+		resp.Data["started_at"] = state.StartedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if !state.CompletedAt.IsZero() {
+		resp.Data["completed_at"] = state.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return resp
+}
+
+// periodicFunc advances a running rewrap job by one batch
+// Vault calls this roughly once a minute
+func (b *TrustVaultBackend) periodicFunc(ctx context.Context, req *logical.Request) error {
+	if !b.WriteSafeReplicationState() {
+		return nil
+	}
+
+	if err := b.storageService.RunRewrapBatch(ctx); err != nil {
+		b.logger.Error("wallet rewrap batch failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// invalidate drops cached state when storage is changed by another node
+func (b *TrustVaultBackend) invalidate(ctx context.Context, key string) {
+	if key == storage.KeyringPath {
+		b.logger.Debug("keyring invalidated")
+		b.storageService.InvalidateKeyring()
+	}
+}
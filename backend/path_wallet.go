@@ -3,19 +3,25 @@ package backend
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/sina-haseli/trust_vault/service"
 	"github.com/sina-haseli/trust_vault/storage"
+	"github.com/sina-haseli/trust_vault/wallet"
 )
 
-// pathWalletCreate returns the path configuration for creating wallets
-// POST /trust-vault/wallets/:name
-func (b *TrustVaultBackend) pathWalletCreate() *framework.Path {
+// pathWallet returns the path configuration for creating, reading, updating and deleting wallets
+// POST|GET|PATCH|DELETE /trust-vault/wallets/:name
+// Vault routes a request to the first path whose pattern matches, so all operations
+// on a wallet must share one path
+func (b *TrustVaultBackend) pathWallet() *framework.Path {
 	return &framework.Path{
 		Pattern: "wallets/" + framework.GenericNameRegex("name"),
 		Fields: map[string]*framework.FieldSchema{
@@ -25,8 +31,8 @@ func (b *TrustVaultBackend) pathWalletCreate() *framework.Path {
 				Required:    true,
 			},
 			"coin_type": {
-				Type:        framework.TypeInt,
-				Description: "Coin type (e.g., 0=Bitcoin, 60=Ethereum, 501=Solana)",
+				Type:        framework.TypeString,
+				Description: "Coin type, ticker symbol or Trust Wallet Core coin ID (e.g., 60, ETH or ethereum)",
 				Required:    true,
 			},
 			"mnemonic": {
@@ -34,6 +40,76 @@ func (b *TrustVaultBackend) pathWalletCreate() *framework.Path {
 				Description: "Optional mnemonic phrase for importing an existing wallet",
 				Required:    false,
 			},
+			"coin_types": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Additional coins the wallet's seed can sign for, as coin types, symbols or IDs (coin_type is always enabled)",
+				Required:    false,
+			},
+			"passphrase": {
+				Type:        framework.TypeString,
+				Description: "Optional BIP39 passphrase (the \"25th word\") mixed into the seed",
+				Required:    false,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+			"store_passphrase": {
+				Type:        framework.TypeBool,
+				Description: "Store the passphrase encrypted with the wallet (default: true). If false, it must be supplied with every request that derives keys.",
+				Required:    false,
+				Default:     true,
+			},
+			"word_count": {
+				Type:        framework.TypeInt,
+				Description: "Number of words of a generated mnemonic: 12, 15, 18, 21 or 24 (default: mount default_word_count)",
+				Required:    false,
+			},
+			"wordlist": {
+				Type:        framework.TypeString,
+				Description: "BIP39 wordlist of the mnemonic (only english is supported)",
+				Required:    false,
+				Default:     wallet.WordlistEnglish,
+			},
+			"address_type": {
+				Type:        framework.TypeString,
+				Description: "Bitcoin address type of the default key: p2pkh, p2sh-p2wpkh, p2wpkh or p2tr (default: p2wpkh)",
+				Required:    false,
+			},
+			"description": {
+				Type:        framework.TypeString,
+				Description: "Free-form description of the wallet",
+				Required:    false,
+			},
+			"owner": {
+				Type:        framework.TypeString,
+				Description: "Owner of the wallet, e.g. a team or service",
+				Required:    false,
+			},
+			"tags": {
+				Type:        framework.TypeKVPairs,
+				Description: "Key-value tags of the wallet. On PATCH they are merged into the stored tags; an empty or null value removes a tag.",
+				Required:    false,
+			},
+			"metadata": {
+				Type:        framework.TypeKVPairs,
+				Description: "Custom key-value metadata of the wallet. On PATCH it is merged into the stored metadata; an empty or null value removes a key.",
+				Required:    false,
+			},
+			"labels": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Labels the wallet can be listed by. On PATCH they replace the stored labels; an empty list removes them all.",
+				Required:    false,
+			},
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: "PATCH only: whether the wallet may be used for signing and address derivation",
+				Required:    false,
+			},
+			"cas": {
+				Type:        framework.TypeInt,
+				Description: "PATCH only: check-and-set; the update is applied only if the wallet is at this version",
+				Required:    false,
+			},
 		},
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.CreateOperation: &framework.PathOperation{
@@ -44,10 +120,22 @@ func (b *TrustVaultBackend) pathWalletCreate() *framework.Path {
 				Callback: b.handleWalletCreate,
 				Summary:  "Create a new cryptocurrency wallet",
 			},
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleWalletRead,
+				Summary:  "Read wallet metadata",
+			},
+			logical.PatchOperation: &framework.PathOperation{
+				Callback: b.handleWalletPatch,
+				Summary:  "Update a wallet's description, owner, tags, metadata or enabled flag",
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.handleWalletDelete,
+				Summary:  "Delete a wallet",
+			},
 		},
 		ExistenceCheck:  b.handleWalletExistenceCheck,
-		HelpSynopsis:    "Create a new cryptocurrency wallet for the specified blockchain",
-		HelpDescription: "Creates a new HD wallet using Trust Wallet Core. If a mnemonic is provided, it imports the wallet; otherwise, it generates a new one.",
+		HelpSynopsis:    "Create, read, update or delete a cryptocurrency wallet",
+		HelpDescription: "Writing creates a new HD wallet for the specified blockchain: if a mnemonic is provided, it imports the wallet; otherwise, it generates a new one. Reading returns the wallet's address and public key, but never its private keys or mnemonic phrase. Patching updates the wallet's mutable metadata and never touches its keys. Deleting permanently removes the wallet and its key material.",
 	}
 }
 
@@ -77,20 +165,61 @@ func (b *TrustVaultBackend) handleWalletCreate(ctx context.Context, req *logical
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
-	coinTypeRaw, ok := data.GetOk("coin_type")
+	coinType, ok, err := coinField(data, "coin_type")
+	if err != nil {
+		b.logger.Warn("invalid coin type provided", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
 	if !ok {
 		b.logger.Warn("coin_type not provided in wallet creation request")
 		return logical.ErrorResponse("coin_type is required"), nil
 	}
-	coinType := uint32(coinTypeRaw.(int))
 
-	// Validate coin type
-	if err := validateCoinType(coinType); err != nil {
-		b.logger.Warn("invalid coin type provided", "coin_type", coinType, "error", err)
+	mnemonic := data.Get("mnemonic").(string)
+
+	coinTypes, err := coinListField(data, "coin_types")
+	if err != nil {
+		b.logger.Warn("invalid coin type provided", "error", err)
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
-	mnemonic := data.Get("mnemonic").(string)
+	opts := service.WalletOptions{
+		CoinTypes:        coinTypes,
+		Passphrase:       data.Get("passphrase").(string),
+		ForgetPassphrase: !data.Get("store_passphrase").(bool),
+		WordCount:        data.Get("word_count").(int),
+		Wordlist:         data.Get("wordlist").(string),
+		AddressType:      data.Get("address_type").(string),
+		Description:      data.Get("description").(string),
+		Owner:            data.Get("owner").(string),
+		Tags:             data.Get("tags").(map[string]string),
+		Metadata:         data.Get("metadata").(map[string]string),
+		Labels:           data.Get("labels").([]string),
+	}
+
+	if opts.AddressType != "" {
+		if _, err := wallet.AddressTypePurpose(coinType, opts.AddressType); err != nil {
+			b.logger.Warn("invalid address type provided", "coin_type", coinType, "address_type", opts.AddressType)
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	if opts.WordCount != 0 {
+		if _, err := wallet.MnemonicStrength(opts.WordCount); err != nil {
+			b.logger.Warn("invalid word count provided", "word_count", opts.WordCount)
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	if err := wallet.ValidateWordlist(opts.Wordlist); err != nil {
+		b.logger.Warn("unsupported wordlist provided", "name", sanitizeWalletName(name))
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if opts.ForgetPassphrase && opts.Passphrase == "" {
+		b.logger.Warn("store_passphrase disabled without a passphrase", "name", sanitizeWalletName(name))
+		return logical.ErrorResponse("store_passphrase=false requires a passphrase"), nil
+	}
 
 	// Log operation (without sensitive data)
 	if mnemonic != "" {
@@ -100,7 +229,7 @@ func (b *TrustVaultBackend) handleWalletCreate(ctx context.Context, req *logical
 	}
 
 	// Create wallet
-	wallet, err := b.walletService.CreateWallet(ctx, name, coinType, mnemonic)
+	wallet, err := b.walletService.CreateWallet(ctx, name, coinType, mnemonic, opts)
 	if err != nil {
 		b.logger.Error("failed to create wallet", "name", sanitizeWalletName(name), "coin_type", coinType, "error", err)
 		return b.handleError(err)
@@ -110,39 +239,10 @@ func (b *TrustVaultBackend) handleWalletCreate(ctx context.Context, req *logical
 
 	// Return wallet metadata (no sensitive data)
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"name":       wallet.Name,
-			"coin_type":  wallet.CoinType,
-			"address":    wallet.Address,
-			"public_key": wallet.PublicKey,
-			"created_at": wallet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		},
+		Data: walletResponseData(wallet),
 	}, nil
 }
 
-// pathWalletRead returns the path configuration for reading wallet metadata
-// GET /trust-vault/wallets/:name
-func (b *TrustVaultBackend) pathWalletRead() *framework.Path {
-	return &framework.Path{
-		Pattern: "wallets/" + framework.GenericNameRegex("name"),
-		Fields: map[string]*framework.FieldSchema{
-			"name": {
-				Type:        framework.TypeString,
-				Description: "Name of the wallet to retrieve",
-				Required:    true,
-			},
-		},
-		Operations: map[logical.Operation]framework.OperationHandler{
-			logical.ReadOperation: &framework.PathOperation{
-				Callback: b.handleWalletRead,
-				Summary:  "Read wallet metadata",
-			},
-		},
-		HelpSynopsis:    "Retrieve wallet metadata without exposing private keys",
-		HelpDescription: "Returns wallet information including address and public key, but never exposes private keys or mnemonic phrases.",
-	}
-}
-
 // handleWalletRead handles wallet read requests
 func (b *TrustVaultBackend) handleWalletRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	name := data.Get("name").(string)
@@ -166,37 +266,70 @@ func (b *TrustVaultBackend) handleWalletRead(ctx context.Context, req *logical.R
 
 	// Return wallet metadata (no sensitive data)
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"name":       wallet.Name,
-			"coin_type":  wallet.CoinType,
-			"address":    wallet.Address,
-			"public_key": wallet.PublicKey,
-			"created_at": wallet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		},
+		Data: walletResponseData(wallet),
 	}, nil
 }
 
-// pathWalletDelete returns the path configuration for deleting wallets
-// DELETE /trust-vault/wallets/:name
-func (b *TrustVaultBackend) pathWalletDelete() *framework.Path {
-	return &framework.Path{
-		Pattern: "wallets/" + framework.GenericNameRegex("name"),
-		Fields: map[string]*framework.FieldSchema{
-			"name": {
-				Type:        framework.TypeString,
-				Description: "Name of the wallet to delete",
-				Required:    true,
-			},
-		},
-		Operations: map[logical.Operation]framework.OperationHandler{
-			logical.DeleteOperation: &framework.PathOperation{
-				Callback: b.handleWalletDelete,
-				Summary:  "Delete a wallet",
-			},
-		},
-		HelpSynopsis:    "Delete a wallet and all associated key material",
-		HelpDescription: "Permanently removes a wallet from storage. This operation cannot be undone.",
+// handleWalletPatch handles wallet metadata updates
+// Only the fields present in the request are changed; the wallet's keys are never touched
+func (b *TrustVaultBackend) handleWalletPatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Validate wallet name
+	if err := validateWalletName(name); err != nil {
+		b.logger.Warn("invalid wallet name provided for update", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	var update service.WalletUpdate
+	if raw, ok := data.GetOk("description"); ok {
+		description := raw.(string)
+		update.Description = &description
+	}
+	if raw, ok := data.GetOk("owner"); ok {
+		owner := raw.(string)
+		update.Owner = &owner
+	}
+	if raw, ok := data.GetOk("enabled"); ok {
+		enabled := raw.(bool)
+		update.Enabled = &enabled
+	}
+	if raw, ok := data.GetOk("tags"); ok {
+		update.Tags = raw.(map[string]string)
+	}
+	if raw, ok := data.GetOk("metadata"); ok {
+		update.Metadata = raw.(map[string]string)
+	}
+	if raw, ok := data.GetOk("labels"); ok {
+		update.Labels = raw.([]string)
+		if update.Labels == nil {
+			update.Labels = []string{}
+		}
+	}
+	if raw, ok := data.GetOk("cas"); ok {
+		cas := raw.(int)
+		if cas < 0 {
+			b.logger.Warn("invalid cas provided", "cas", cas)
+			return logical.ErrorResponse("cas must be non-negative"), nil
+		}
+		version := uint64(cas)
+		update.CAS = &version
+	}
+
+	b.logger.Info("updating wallet", "name", sanitizeWalletName(name))
+
+	wallet, err := b.walletService.UpdateWallet(ctx, name, update)
+	if err != nil {
+		b.logger.Error("failed to update wallet", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
 	}
+
+	b.logger.Info("wallet updated successfully", "name", sanitizeWalletName(name), "version", wallet.Version)
+
+	// Return wallet metadata (no sensitive data)
+	return &logical.Response{
+		Data: walletResponseData(wallet),
+	}, nil
 }
 
 // handleWalletDelete handles wallet deletion requests
@@ -232,6 +365,46 @@ func (b *TrustVaultBackend) pathWalletList() *framework.Path {
 	return &framework.Path{
 		Pattern: "wallets/?$",
 		Fields: map[string]*framework.FieldSchema{
+			"coin_type": {
+				Type:        framework.TypeString,
+				Description: "Only list wallets enabled for this coin type, symbol or ID",
+				Required:    false,
+			},
+			"tag": {
+				Type:        framework.TypeKVPairs,
+				Description: "Only list wallets carrying these tags, as key=value pairs",
+				Required:    false,
+			},
+			"label": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Only list wallets carrying all of these labels",
+				Required:    false,
+			},
+			"owner": {
+				Type:        framework.TypeString,
+				Description: "Only list wallets with this owner",
+				Required:    false,
+			},
+			"prefix": {
+				Type:        framework.TypeString,
+				Description: "Only list wallets whose name starts with this prefix",
+				Required:    false,
+			},
+			"created_after": {
+				Type:        framework.TypeTime,
+				Description: "Only list wallets created after this time (RFC 3339 or Unix seconds)",
+				Required:    false,
+			},
+			"created_before": {
+				Type:        framework.TypeTime,
+				Description: "Only list wallets created before this time (RFC 3339 or Unix seconds)",
+				Required:    false,
+			},
+			"include_metadata": {
+				Type:        framework.TypeBool,
+				Description: "Return each wallet's metadata in key_info (default: false)",
+				Required:    false,
+			},
 			"offset": {
 				Type:        framework.TypeInt,
 				Description: "Pagination offset (default: 0)",
@@ -251,8 +424,8 @@ func (b *TrustVaultBackend) pathWalletList() *framework.Path {
 				Summary:  "List all wallets",
 			},
 		},
-		HelpSynopsis:    "List all wallet names",
-		HelpDescription: "Returns a list of all wallet names stored in the plugin. Supports pagination.",
+		HelpSynopsis:    "List wallet names",
+		HelpDescription: "Returns the names of the wallets stored in the plugin, optionally filtered by coin type, tags, labels, owner, name prefix and creation time. Filters are combined, so a wallet must match all of them. With include_metadata, each wallet's metadata is returned in key_info. Supports pagination.",
 	}
 }
 
@@ -271,10 +444,45 @@ func (b *TrustVaultBackend) handleWalletList(ctx context.Context, req *logical.R
 		return logical.ErrorResponse("limit must be non-negative"), nil
 	}
 
+	filter := storage.WalletFilter{
+		Tags:   data.Get("tag").(map[string]string),
+		Labels: data.Get("label").([]string),
+		Owner:  data.Get("owner").(string),
+		Prefix: data.Get("prefix").(string),
+	}
+
+	coinType, ok, err := coinField(data, "coin_type")
+	if err != nil {
+		b.logger.Warn("invalid coin type provided", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if ok {
+		filter.CoinType = &coinType
+	}
+
+	if raw, ok := data.GetOk("created_after"); ok {
+		filter.CreatedAfter = raw.(time.Time)
+	}
+	if raw, ok := data.GetOk("created_before"); ok {
+		filter.CreatedBefore = raw.(time.Time)
+	}
+
 	b.logger.Debug("listing wallets", "offset", offset, "limit", limit)
 
-	// List wallets
-	wallets, err := b.walletService.ListWallets(ctx, offset, limit)
+	if !data.Get("include_metadata").(bool) {
+		// List wallets
+		wallets, err := b.walletService.ListWallets(ctx, filter, offset, limit)
+		if err != nil {
+			b.logger.Error("failed to list wallets", "error", err)
+			return b.handleError(err)
+		}
+
+		b.logger.Debug("wallets listed successfully", "count", len(wallets))
+
+		return logical.ListResponse(wallets), nil
+	}
+
+	wallets, err := b.walletService.ListWalletsWithMetadata(ctx, filter, offset, limit)
 	if err != nil {
 		b.logger.Error("failed to list wallets", "error", err)
 		return b.handleError(err)
@@ -282,7 +490,14 @@ func (b *TrustVaultBackend) handleWalletList(ctx context.Context, req *logical.R
 
 	b.logger.Debug("wallets listed successfully", "count", len(wallets))
 
-	return logical.ListResponse(wallets), nil
+	names := make([]string, 0, len(wallets))
+	keyInfo := make(map[string]interface{}, len(wallets))
+	for _, w := range wallets {
+		names = append(names, w.Name)
+		keyInfo[w.Name] = walletResponseData(w)
+	}
+
+	return logical.ListResponseWithInfo(names, keyInfo), nil
 }
 
 // pathWalletSign returns the path configuration for signing transactions
@@ -290,7 +505,7 @@ func (b *TrustVaultBackend) handleWalletList(ctx context.Context, req *logical.R
 func (b *TrustVaultBackend) pathWalletSign() *framework.Path {
 	return &framework.Path{
 		Pattern: "wallets/" + framework.GenericNameRegex("name") + "/sign$",
-		Fields: map[string]*framework.FieldSchema{
+		Fields: withSigningKeyFields(map[string]*framework.FieldSchema{
 			"name": {
 				Type:        framework.TypeString,
 				Description: "Name of the wallet to use for signing",
@@ -299,9 +514,14 @@ func (b *TrustVaultBackend) pathWalletSign() *framework.Path {
 			"tx_data": {
 				Type:        framework.TypeString,
 				Description: "Base64-encoded transaction data to sign",
-				Required:    true,
+				Required:    false,
 			},
-		},
+			"signing_input": {
+				Type:        framework.TypeString,
+				Description: "Base64-encoded Trust Wallet Core SigningInput protobuf for the selected chain. The wallet's private key is injected by the plugin.",
+				Required:    false,
+			},
+		}),
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.UpdateOperation: &framework.PathOperation{
 				Callback: b.handleWalletSign,
@@ -309,7 +529,7 @@ func (b *TrustVaultBackend) pathWalletSign() *framework.Path {
 			},
 		},
 		HelpSynopsis:    "Sign a transaction using the wallet's private key",
-		HelpDescription: "Signs transaction data using Trust Wallet Core. The transaction data should be base64-encoded and formatted according to the blockchain's requirements.",
+		HelpDescription: "Signs transaction data using Trust Wallet Core. Either tx_data (raw bytes signed with the wallet's key) or signing_input (a chain-specific SigningInput protobuf signed with AnySigner, returning a broadcast-ready transaction) must be provided.",
 	}
 }
 
@@ -323,7 +543,23 @@ func (b *TrustVaultBackend) handleWalletSign(ctx context.Context, req *logical.R
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
+	key, err := signingKey(data)
+	if err != nil {
+		b.logger.Warn("invalid signing key provided for signing", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	txDataEncoded := data.Get("tx_data").(string)
+	signingInputEncoded := data.Get("signing_input").(string)
+
+	if signingInputEncoded != "" {
+		if txDataEncoded != "" {
+			b.logger.Warn("both tx_data and signing_input provided in signing request")
+			return logical.ErrorResponse("only one of tx_data or signing_input may be provided"), nil
+		}
+		return b.handleWalletSignInput(ctx, name, key, signingInputEncoded)
+	}
+
 	if txDataEncoded == "" {
 		b.logger.Warn("tx_data not provided in signing request")
 		return logical.ErrorResponse("tx_data is required"), nil
@@ -351,7 +587,7 @@ func (b *TrustVaultBackend) handleWalletSign(ctx context.Context, req *logical.R
 	b.logger.Info("signing transaction", "name", sanitizeWalletName(name), "tx_size", len(txData))
 
 	// Sign transaction
-	signature, err := b.walletService.SignTransaction(ctx, name, txData)
+	signature, coinType, err := b.walletService.SignTransaction(ctx, name, key, txData)
 	if err != nil {
 		b.logger.Error("failed to sign transaction", "name", sanitizeWalletName(name), "error", err)
 		return b.handleError(err)
@@ -362,7 +598,305 @@ func (b *TrustVaultBackend) handleWalletSign(ctx context.Context, req *logical.R
 	// Return base64-encoded signature
 	return &logical.Response{
 		Data: map[string]interface{}{
-			"signed_tx": base64.StdEncoding.EncodeToString(signature),
+			"signed_tx":   base64.StdEncoding.EncodeToString(signature),
+			"coin_type":   coinType,
+			"coin_symbol": wallet.CoinSymbol(coinType),
+		},
+	}, nil
+}
+
+// handleWalletSignInput signs a chain-specific SigningInput protobuf
+func (b *TrustVaultBackend) handleWalletSignInput(ctx context.Context, name string, key service.SigningKey, signingInputEncoded string) (*logical.Response, error) {
+	// Validate signing input length
+	if len(signingInputEncoded) > 1024*1024 { // 1MB limit
+		b.logger.Warn("signing input too large", "size", len(signingInputEncoded))
+		return logical.ErrorResponse("signing input exceeds maximum size of 1MB"), nil
+	}
+
+	// Decode base64 signing input
+	input, err := base64.StdEncoding.DecodeString(signingInputEncoded)
+	if err != nil {
+		b.logger.Warn("invalid base64 signing input", "error", err)
+		return logical.ErrorResponse("invalid signing_input: must be base64-encoded"), nil
+	}
+
+	if len(input) == 0 {
+		b.logger.Warn("empty signing input after decoding")
+		return logical.ErrorResponse("signing input cannot be empty"), nil
+	}
+
+	b.logger.Info("signing transaction input", "name", sanitizeWalletName(name), "input_size", len(input))
+
+	// Sign with the chain's AnySigner
+	signed, err := b.walletService.SignInput(ctx, name, key, input)
+	if err != nil {
+		b.logger.Error("failed to sign transaction input", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	b.logger.Info("transaction input signed successfully", "name", sanitizeWalletName(name), "tx_hash", signed.TxHash)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"signing_output": base64.StdEncoding.EncodeToString(signed.Output),
+			"raw_tx":         signed.RawTx,
+			"tx_hash":        signed.TxHash,
+			"coin_type":      signed.CoinType,
+			"coin_symbol":    wallet.CoinSymbol(signed.CoinType),
+		},
+	}, nil
+}
+
+// pathWalletSignJSON returns the path configuration for signing JSON transaction descriptions
+// POST /trust-vault/wallets/:name/sign/json
+func (b *TrustVaultBackend) pathWalletSignJSON() *framework.Path {
+	return &framework.Path{
+		Pattern: "wallets/" + framework.GenericNameRegex("name") + "/sign/json$",
+		Fields: withSigningKeyFields(map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the wallet to use for signing",
+				Required:    true,
+			},
+			"transaction": {
+				Type:        framework.TypeMap,
+				Description: "Chain-specific transaction description. Ethereum: to, value, nonce, gas, gas_price or max_fee_per_gas/max_priority_fee_per_gas, chain_id, data. Bitcoin: utxos, outputs (one or more address and amount), fee_rate, change_address. Solana: recipient, lamports, recent_blockhash, memo.",
+				Required:    true,
+			},
+			"chain": {
+				Type:        framework.TypeString,
+				Description: "EVM chain profile to sign an Ethereum transaction for (see trust-vault/chains); sets chain_id and checks EIP-1559 support",
+				Required:    false,
+			},
+		}),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleWalletSignJSON,
+				Summary:  "Sign a JSON transaction description",
+			},
+		},
+		HelpSynopsis:    "Sign a transaction described as JSON",
+		HelpDescription: "Validates a chain-specific JSON transaction description, converts it to a Trust Wallet Core SigningInput and signs it with the wallet's private key. Returns the raw signed transaction and its hash.",
+	}
+}
+
+// handleWalletSignJSON handles JSON transaction signing requests
+func (b *TrustVaultBackend) handleWalletSignJSON(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Validate wallet name
+	if err := validateWalletName(name); err != nil {
+		b.logger.Warn("invalid wallet name provided for json signing", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	key, err := signingKey(data)
+	if err != nil {
+		b.logger.Warn("invalid signing key provided for json signing", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	transaction := data.Get("transaction").(map[string]interface{})
+	if len(transaction) == 0 {
+		b.logger.Warn("transaction not provided in json signing request")
+		return logical.ErrorResponse("transaction is required"), nil
+	}
+
+	description, err := json.Marshal(transaction)
+	if err != nil {
+		b.logger.Warn("invalid transaction description", "error", err)
+		return logical.ErrorResponse("invalid transaction: must be a JSON object"), nil
+	}
+
+	chain := data.Get("chain").(string)
+	if chain != "" {
+		if err := validateChainName(chain); err != nil {
+			b.logger.Warn("invalid chain name provided for json signing", "error", err)
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	b.logger.Info("signing json transaction", "name", sanitizeWalletName(name), "chain", sanitizeWalletName(chain))
+
+	signed, err := b.walletService.SignJSONTransaction(ctx, name, key, chain, description)
+	if err != nil {
+		b.logger.Error("failed to sign json transaction", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	b.logger.Info("json transaction signed successfully", "name", sanitizeWalletName(name), "tx_hash", signed.TxHash)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"raw_tx":      signed.RawTx,
+			"tx_hash":     signed.TxHash,
+			"coin_type":   signed.CoinType,
+			"coin_symbol": wallet.CoinSymbol(signed.CoinType),
+		},
+	}, nil
+}
+
+// pathWalletSignMessage returns the path configuration for signing off-chain messages
+// POST /trust-vault/wallets/:name/sign-message
+func (b *TrustVaultBackend) pathWalletSignMessage() *framework.Path {
+	return &framework.Path{
+		Pattern: "wallets/" + framework.GenericNameRegex("name") + "/sign-message$",
+		Fields: withSigningKeyFields(map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the wallet to use for signing",
+				Required:    true,
+			},
+			"message": {
+				Type:        framework.TypeString,
+				Description: "Message to sign. For eip712 this is the typed data JSON document.",
+				Required:    true,
+			},
+			"format": {
+				Type:          framework.TypeString,
+				Description:   "Message format: eip191, eip712, bitcoin or solana-offchain. Defaults to the chain's format (eip191, bitcoin or solana-offchain).",
+				Required:      false,
+				AllowedValues: []interface{}{wallet.MessageFormatEIP191, wallet.MessageFormatEIP712, wallet.MessageFormatBitcoin, wallet.MessageFormatSolanaOffchain},
+			},
+		}),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleWalletSignMessage,
+				Summary:  "Sign an off-chain message",
+			},
+		},
+		HelpSynopsis:    "Sign an off-chain message with the wallet's private key",
+		HelpDescription: "Signs a message with the prefix and hash of the requested format so the signature cannot be replayed as a transaction. eip191 and eip712 return 0x-prefixed r||s||v hex, bitcoin returns a base64 compact signature, and solana-offchain returns a base58 Ed25519 signature.",
+	}
+}
+
+// handleWalletSignMessage handles message signing requests
+func (b *TrustVaultBackend) handleWalletSignMessage(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Validate wallet name
+	if err := validateWalletName(name); err != nil {
+		b.logger.Warn("invalid wallet name provided for message signing", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	key, err := signingKey(data)
+	if err != nil {
+		b.logger.Warn("invalid signing key provided for message signing", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	message := data.Get("message").(string)
+	if message == "" {
+		b.logger.Warn("message not provided in signing request")
+		return logical.ErrorResponse("message is required"), nil
+	}
+
+	format := strings.ToLower(data.Get("format").(string))
+
+	b.logger.Info("signing message", "name", sanitizeWalletName(name), "format", format)
+
+	signed, err := b.walletService.SignMessage(ctx, name, key, format, message)
+	if err != nil {
+		b.logger.Error("failed to sign message", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	b.logger.Info("message signed successfully", "name", sanitizeWalletName(name), "format", signed.Format)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"signature":   signed.Signature,
+			"format":      signed.Format,
+			"coin_type":   signed.CoinType,
+			"coin_symbol": wallet.CoinSymbol(signed.CoinType),
+		},
+	}, nil
+}
+
+// pathWalletVerify returns the path configuration for verifying signatures
+// POST /trust-vault/wallets/:name/verify
+func (b *TrustVaultBackend) pathWalletVerify() *framework.Path {
+	return &framework.Path{
+		Pattern: "wallets/" + framework.GenericNameRegex("name") + "/verify$",
+		Fields: withSigningKeyFields(map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the wallet whose public key verifies the signature",
+				Required:    true,
+			},
+			"message": {
+				Type:        framework.TypeString,
+				Description: "Signed message. For eip712 this is the typed data JSON document; for raw it is the base64-encoded tx_data passed to the sign endpoint.",
+				Required:    true,
+			},
+			"signature": {
+				Type:        framework.TypeString,
+				Description: "Signature in the format's encoding, as returned by the sign-message or sign endpoint",
+				Required:    true,
+			},
+			"format": {
+				Type:          framework.TypeString,
+				Description:   "Message format: eip191, eip712, bitcoin, solana-offchain or raw. Defaults to the wallet chain's message format.",
+				Required:      false,
+				AllowedValues: []interface{}{wallet.MessageFormatEIP191, wallet.MessageFormatEIP712, wallet.MessageFormatBitcoin, wallet.MessageFormatSolanaOffchain, wallet.MessageFormatRaw},
+			},
+		}),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleWalletVerify,
+				Summary:  "Verify a signature against the wallet's public key",
+			},
+		},
+		HelpSynopsis:    "Verify a signature against the wallet's public key",
+		HelpDescription: "Recomputes the signed payload for the requested format and verifies the signature with the wallet's stored public key. Returns valid: true or false. Use format raw to verify signatures returned by the sign endpoint.",
+	}
+}
+
+// handleWalletVerify handles signature verification requests
+func (b *TrustVaultBackend) handleWalletVerify(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Validate wallet name
+	if err := validateWalletName(name); err != nil {
+		b.logger.Warn("invalid wallet name provided for verification", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	key, err := signingKey(data)
+	if err != nil {
+		b.logger.Warn("invalid signing key provided for verification", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	message := data.Get("message").(string)
+	if message == "" {
+		b.logger.Warn("message not provided in verification request")
+		return logical.ErrorResponse("message is required"), nil
+	}
+
+	signature := data.Get("signature").(string)
+	if signature == "" {
+		b.logger.Warn("signature not provided in verification request")
+		return logical.ErrorResponse("signature is required"), nil
+	}
+
+	format := strings.ToLower(data.Get("format").(string))
+
+	b.logger.Debug("verifying signature", "name", sanitizeWalletName(name), "format", format)
+
+	verified, err := b.walletService.VerifyMessage(ctx, name, key, format, message, signature)
+	if err != nil {
+		b.logger.Error("failed to verify signature", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"valid":       verified.Valid,
+			"format":      verified.Format,
+			"coin_type":   verified.CoinType,
+			"coin_symbol": wallet.CoinSymbol(verified.CoinType),
 		},
 	}, nil
 }
@@ -380,7 +914,7 @@ func (b *TrustVaultBackend) pathWalletAddress() *framework.Path {
 			},
 			"coin": {
 				Type:        framework.TypeString,
-				Description: "Coin type (e.g., 0=Bitcoin, 60=Ethereum, 501=Solana)",
+				Description: "Coin type, ticker symbol or Trust Wallet Core coin ID (e.g., 60, ETH or ethereum)",
 				Required:    true,
 			},
 			"derivation_path": {
@@ -388,15 +922,42 @@ func (b *TrustVaultBackend) pathWalletAddress() *framework.Path {
 				Description: "Optional custom derivation path (e.g., m/44'/60'/0'/0/0)",
 				Required:    false,
 			},
+			"address_type": {
+				Type:        framework.TypeString,
+				Description: "Bitcoin address type: p2pkh, p2sh-p2wpkh, p2wpkh or p2tr (default: the wallet's address type)",
+				Required:    false,
+			},
+			"label": {
+				Type:        framework.TypeString,
+				Description: "Optional label stored with the address in the wallet's address registry",
+				Required:    false,
+			},
+			"metadata": {
+				Type:        framework.TypeKVPairs,
+				Description: "Optional key-value metadata stored with the address in the wallet's address registry",
+				Required:    false,
+			},
+			"passphrase": {
+				Type:        framework.TypeString,
+				Description: "BIP39 passphrase, required for wallets created with store_passphrase=false; only accepted on write, as read parameters are sent in the URL",
+				Required:    false,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
 		},
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ReadOperation: &framework.PathOperation{
 				Callback: b.handleWalletAddress,
 				Summary:  "Derive an address for a specific coin type",
 			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleWalletAddress,
+				Summary:  "Derive an address for a specific coin type, with the BIP39 passphrase in the request body",
+			},
 		},
 		HelpSynopsis:    "Derive a cryptocurrency address from the wallet",
-		HelpDescription: "Derives an address for the specified coin type using the wallet's mnemonic. Optionally accepts a custom derivation path.",
+		HelpDescription: "Derives an address for the specified coin type using the wallet's mnemonic. Optionally accepts a custom derivation path. Every address handed out is recorded in the wallet's address registry, so it can be found again with lookup/address.",
 	}
 }
 
@@ -416,16 +977,10 @@ func (b *TrustVaultBackend) handleWalletAddress(ctx context.Context, req *logica
 		return logical.ErrorResponse("coin type is required"), nil
 	}
 
-	// Parse coin type
-	var coinType uint32
-	if _, err := fmt.Sscanf(coinStr, "%d", &coinType); err != nil {
-		b.logger.Warn("invalid coin type format", "coin", coinStr, "error", err)
-		return logical.ErrorResponse("invalid coin type: must be a number"), nil
-	}
-
-	// Validate coin type
-	if err := validateCoinType(coinType); err != nil {
-		b.logger.Warn("invalid coin type provided", "coin_type", coinType, "error", err)
+	// Resolve coin type, symbol or ID
+	coinType, err := resolveCoin(coinStr)
+	if err != nil {
+		b.logger.Warn("invalid coin type provided", "error", err)
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
@@ -439,22 +994,36 @@ func (b *TrustVaultBackend) handleWalletAddress(ctx context.Context, req *logica
 		}
 	}
 
+	addressType := data.Get("address_type").(string)
+	if addressType != "" {
+		if _, err := wallet.AddressTypePurpose(coinType, addressType); err != nil {
+			b.logger.Warn("invalid address type provided", "coin_type", coinType, "address_type", addressType)
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
 	b.logger.Debug("deriving address", "name", sanitizeWalletName(name), "coin_type", coinType, "has_custom_path", derivationPath != "")
 
-	// Derive address
-	address, err := b.walletService.GetAddress(ctx, name, coinType, derivationPath)
+	passphrase, err := requestPassphrase(req, data)
+	if err != nil {
+		b.logger.Warn("passphrase sent in a read request", "name", sanitizeWalletName(name))
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	label := data.Get("label").(string)
+	metadata := data.Get("metadata").(map[string]string)
+
+	// Derive and record address
+	record, err := b.walletService.GetAddress(ctx, name, coinType, derivationPath, addressType, passphrase, label, metadata)
 	if err != nil {
 		b.logger.Error("failed to derive address", "name", sanitizeWalletName(name), "coin_type", coinType, "error", err)
 		return b.handleError(err)
 	}
 
-	b.logger.Debug("address derived successfully", "name", sanitizeWalletName(name), "coin_type", coinType, "address", address)
+	b.logger.Debug("address derived successfully", "name", sanitizeWalletName(name), "coin_type", coinType, "address", record.Address)
 
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"address":   address,
-			"coin_type": coinType,
-		},
+		Data: addressRecordResponseData(record),
 	}, nil
 }
 
@@ -469,21 +1038,103 @@ func (b *TrustVaultBackend) handleError(err error) (*logical.Response, error) {
 		resp := logical.ErrorResponse("wallet already exists")
 		resp.Data["http_status_code"] = 409
 		return resp, nil
+	case errors.Is(err, service.ErrVersionConflict), errors.Is(err, storage.ErrVersionConflict):
+		resp := logical.ErrorResponse(err.Error())
+		resp.Data["http_status_code"] = 409
+		return resp, nil
+	case errors.Is(err, service.ErrWalletDisabled), errors.Is(err, service.ErrInvalidMetadata):
+		return logical.ErrorResponse(err.Error()), nil
 	case errors.Is(err, service.ErrInvalidCoinType):
 		return logical.ErrorResponse("invalid coin type"), nil
-	case errors.Is(err, service.ErrInvalidMnemonic):
-		return logical.ErrorResponse("invalid mnemonic phrase"), nil
+	case errors.Is(err, service.ErrInvalidMnemonic), errors.Is(err, service.ErrInvalidWordCount):
+		return logical.ErrorResponse(err.Error()), nil
 	case errors.Is(err, service.ErrInvalidTxData):
-		return logical.ErrorResponse("invalid transaction data"), nil
+		return logical.ErrorResponse(err.Error()), nil
+	case errors.Is(err, service.ErrInvalidMessage), errors.Is(err, service.ErrUnsupportedMessageFormat):
+		return logical.ErrorResponse(err.Error()), nil
+	case errors.Is(err, service.ErrCoinNotEnabled), errors.Is(err, service.ErrCoinDisabled), errors.Is(err, service.ErrInvalidDerivationPath):
+		return logical.ErrorResponse(err.Error()), nil
+	case errors.Is(err, service.ErrPassphraseRequired), errors.Is(err, service.ErrInvalidPassphrase):
+		return logical.ErrorResponse(err.Error()), nil
+	case errors.Is(err, service.ErrInvalidExtendedKey), errors.Is(err, service.ErrInvalidAddressType):
+		return logical.ErrorResponse(err.Error()), nil
+	case errors.Is(err, service.ErrInvalidBatch):
+		return logical.ErrorResponse(err.Error()), nil
+	case errors.Is(err, storage.ErrChainNotFound):
+		resp := logical.ErrorResponse("chain profile not found")
+		resp.Data["http_status_code"] = 404
+		return resp, nil
+	case errors.Is(err, service.ErrAddressNotFound):
+		resp := logical.ErrorResponse("address not found")
+		resp.Data["http_status_code"] = 404
+		return resp, nil
 	case errors.Is(err, service.ErrSigningFailed):
 		return logical.ErrorResponse("transaction signing failed"), nil
 	case errors.Is(err, service.ErrInvalidWalletName):
 		return logical.ErrorResponse("invalid wallet name"), nil
+	case errors.Is(err, storage.ErrRewrapIncomplete):
+		return logical.ErrorResponse(err.Error()), nil
+	case errors.Is(err, storage.ErrKeyVersionRetired):
+		resp := logical.ErrorResponse("wallet is encrypted with a retired key version; lower min_decryption_version to read it")
+		resp.Data["http_status_code"] = 409
+		return resp, nil
+	case errors.Is(err, storage.ErrInvalidKeyVersion):
+		resp := logical.ErrorResponse(err.Error())
+		resp.Data["http_status_code"] = 400
+		return resp, nil
 	default:
 		return nil, fmt.Errorf("internal error: %w", err)
 	}
 }
 
+// walletResponseData returns the public metadata of a wallet for API responses
+// Wallets that were never updated report their creation time as updated_at
+func walletResponseData(w *storage.Wallet) map[string]interface{} {
+	updatedAt := w.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = w.CreatedAt
+	}
+
+	return map[string]interface{}{
+		"name":                w.Name,
+		"coin_type":           w.CoinType,
+		"coin_symbol":         wallet.CoinSymbol(w.CoinType),
+		"address":             w.Address,
+		"public_key":          w.PublicKey,
+		"curve":               w.Curve,
+		"coin_types":          w.EnabledCoinTypes(),
+		"passphrase_required": w.PassphraseRequired,
+		"word_count":          w.WordCount,
+		"address_type":        w.AddressType,
+		"wordlist":            wallet.WordlistEnglish,
+		"created_at":          w.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"description":         w.Description,
+		"owner":               w.Owner,
+		"tags":                entriesOrEmpty(w.Tags),
+		"metadata":            entriesOrEmpty(w.Metadata),
+		"labels":              labelsOrEmpty(w.Labels),
+		"enabled":             !w.Disabled,
+		"version":             w.Version,
+		"updated_at":          updatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// labelsOrEmpty returns labels, or an empty list so responses never carry null labels
+func labelsOrEmpty(labels []string) []string {
+	if labels == nil {
+		return []string{}
+	}
+	return labels
+}
+
+// entriesOrEmpty returns entries, or an empty map so responses never carry null tags or metadata
+func entriesOrEmpty(entries map[string]string) map[string]string {
+	if entries == nil {
+		return map[string]string{}
+	}
+	return entries
+}
+
 // validateWalletName validates wallet name to prevent path traversal and ensure valid format
 func validateWalletName(name string) error {
 	if name == "" {
@@ -509,20 +1160,102 @@ func validateWalletName(name string) error {
 	return nil
 }
 
-// validateCoinType validates that the coin type is supported
-func validateCoinType(coinType uint32) error {
-	// Supported coin types: Bitcoin (0), Ethereum (60), Solana (501)
-	supportedTypes := map[uint32]bool{
-		0:   true, // Bitcoin
-		60:  true, // Ethereum
-		501: true, // Solana
+// withSigningKeyFields adds the fields that select a wallet key to a path's fields
+func withSigningKeyFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
+	fields["coin_type"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Coin type, symbol or ID of the key (must be enabled for the wallet; defaults to the wallet's creation coin)",
+		Required:    false,
+	}
+	fields["derivation_path"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Optional derivation path of the key (e.g., m/44'/60'/0'/0/5)",
+		Required:    false,
+	}
+	fields["account"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "BIP44 account of the key; use with change and address_index instead of derivation_path",
+		Required:    false,
 	}
+	fields["change"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "BIP44 change level of the key (0 external, 1 internal)",
+		Required:    false,
+	}
+	fields["address_index"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Description: "BIP44 address index of the key",
+		Required:    false,
+	}
+	fields["passphrase"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "BIP39 passphrase, required for wallets created with store_passphrase=false",
+		Required:    false,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Sensitive: true,
+		},
+	}
+	return fields
+}
 
-	if !supportedTypes[coinType] {
-		return fmt.Errorf("unsupported coin type: %d (supported: 0=Bitcoin, 60=Ethereum, 501=Solana)", coinType)
+// requestPassphrase returns the BIP39 passphrase of a request
+// Read parameters travel in the URL query string, where proxies and access logs record them,
+// so the passphrase is only accepted on writes
+func requestPassphrase(req *logical.Request, data *framework.FieldData) (string, error) {
+	passphrase := data.Get("passphrase").(string)
+	if passphrase != "" && req.Operation == logical.ReadOperation {
+		return "", errors.New("passphrase is not accepted on read requests, whose parameters are sent in the URL; send it with a write request instead")
 	}
+	return passphrase, nil
+}
 
-	return nil
+// signingKey returns the wallet key selected by a request
+// Without coin_type, service.DefaultCoinType selects the wallet's creation coin
+func signingKey(data *framework.FieldData) (service.SigningKey, error) {
+	key := service.DefaultSigningKey
+
+	coinType, ok, err := coinField(data, "coin_type")
+	if err != nil {
+		return key, err
+	}
+	if ok {
+		key.CoinType = coinType
+	}
+
+	key.Passphrase = data.Get("passphrase").(string)
+
+	key.DerivationPath = data.Get("derivation_path").(string)
+	if err := validateDerivationPath(key.DerivationPath); err != nil {
+		return key, err
+	}
+
+	var index service.KeyIndex
+	indexed := false
+	for field, target := range map[string]*uint32{
+		"account":       &index.Account,
+		"change":        &index.Change,
+		"address_index": &index.AddressIndex,
+	} {
+		raw, ok := data.GetOk(field)
+		if !ok {
+			continue
+		}
+		value := raw.(int)
+		if value < 0 || value > math.MaxInt32 {
+			return key, fmt.Errorf("%s must be between 0 and %d", field, math.MaxInt32)
+		}
+		*target = uint32(value)
+		indexed = true
+	}
+
+	if indexed {
+		if key.DerivationPath != "" {
+			return key, errors.New("derivation_path cannot be combined with account, change or address_index")
+		}
+		key.Index = &index
+	}
+
+	return key, nil
 }
 
 // validateDerivationPath validates the derivation path format
@@ -0,0 +1,228 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sina-haseli/trust_vault/service"
+	"github.com/sina-haseli/trust_vault/wallet"
+)
+
+// pathWalletXpub returns the path configuration for exporting extended public keys
+// GET /trust-vault/wallets/:name/xpub
+func (b *TrustVaultBackend) pathWalletXpub() *framework.Path {
+	return &framework.Path{
+		Pattern: "wallets/" + framework.GenericNameRegex("name") + "/xpub$",
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the wallet",
+				Required:    true,
+			},
+			"coin_type": {
+				Type:        framework.TypeString,
+				Description: "Coin type, symbol or ID of the key (must be enabled for the wallet; defaults to the wallet's creation coin)",
+				Required:    false,
+			},
+			"purpose": {
+				Type:        framework.TypeInt,
+				Description: "Derivation purpose: 44, 49, 84 or 86 (defaults to the coin's default path, e.g. 84 for Bitcoin)",
+				Required:    false,
+			},
+			"account": {
+				Type:        framework.TypeInt,
+				Description: "BIP44 account (default: 0)",
+				Required:    false,
+			},
+			"passphrase": {
+				Type:        framework.TypeString,
+				Description: "BIP39 passphrase, required for wallets created with store_passphrase=false; only accepted on write, as read parameters are sent in the URL",
+				Required:    false,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleWalletXpub,
+				Summary:  "Export an account-level extended public key",
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleWalletXpub,
+				Summary:  "Export an account-level extended public key, with the BIP39 passphrase in the request body",
+			},
+		},
+		HelpSynopsis:    "Export the extended public key of a wallet account",
+		HelpDescription: "Returns the account-level extended public key (xpub, ypub or zpub depending on the purpose) so watch-only systems can derive receive addresses without calling Vault. Ed25519 chains such as Solana have no extended public keys.",
+	}
+}
+
+// handleWalletXpub handles extended public key export requests
+func (b *TrustVaultBackend) handleWalletXpub(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Validate wallet name
+	if err := validateWalletName(name); err != nil {
+		b.logger.Warn("invalid wallet name provided for xpub export", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	coinType, ok, err := coinField(data, "coin_type")
+	if err != nil {
+		b.logger.Warn("invalid coin type provided", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if !ok {
+		coinType = service.DefaultCoinType
+	}
+
+	var purpose uint32
+	if raw, ok := data.GetOk("purpose"); ok {
+		switch p := raw.(int); p {
+		case 44, 49, 84, 86:
+			purpose = uint32(p)
+		default:
+			return logical.ErrorResponse("invalid purpose: %d (must be 44, 49, 84 or 86)", p), nil
+		}
+	}
+
+	account, err := indexField(data, "account")
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	passphrase, err := requestPassphrase(req, data)
+	if err != nil {
+		b.logger.Warn("passphrase sent in a read request", "name", sanitizeWalletName(name))
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	b.logger.Debug("exporting extended public key", "name", sanitizeWalletName(name), "coin_type", coinType, "purpose", purpose, "account", account)
+
+	key, err := b.walletService.GetExtendedPublicKey(ctx, name, coinType, purpose, account, passphrase)
+	if err != nil {
+		b.logger.Error("failed to export extended public key", "name", sanitizeWalletName(name), "error", err)
+		return b.handleError(err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"xpub":            key.Xpub,
+			"coin_type":       key.CoinType,
+			"coin_symbol":     wallet.CoinSymbol(key.CoinType),
+			"purpose":         key.Purpose,
+			"account":         key.Account,
+			"derivation_path": key.DerivationPath,
+		},
+	}, nil
+}
+
+// pathXpubDerive returns the path configuration for deriving addresses from an extended public key
+// POST /trust-vault/xpub/derive
+func (b *TrustVaultBackend) pathXpubDerive() *framework.Path {
+	return &framework.Path{
+		Pattern: "xpub/derive$",
+		Fields: map[string]*framework.FieldSchema{
+			"xpub": {
+				Type:        framework.TypeString,
+				Description: "Account-level extended public key (xpub, ypub or zpub)",
+				Required:    true,
+			},
+			"coin_type": {
+				Type:        framework.TypeString,
+				Description: "Coin type, symbol or ID the extended public key belongs to",
+				Required:    true,
+			},
+			"address_type": {
+				Type:        framework.TypeString,
+				Description: "Bitcoin address type: p2pkh, p2sh-p2wpkh, p2wpkh or p2tr (ypub and zpub keys imply p2sh-p2wpkh and p2wpkh; required for Bitcoin xpub keys)",
+				Required:    false,
+			},
+			"change": {
+				Type:        framework.TypeInt,
+				Description: "BIP44 change level (0 external, 1 internal; default: 0)",
+				Required:    false,
+			},
+			"address_index": {
+				Type:        framework.TypeInt,
+				Description: "BIP44 address index (default: 0)",
+				Required:    false,
+			},
+		},
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handleXpubDerive,
+				Summary:  "Derive an address from an extended public key",
+			},
+		},
+		HelpSynopsis:    "Derive a public key and address from an extended public key",
+		HelpDescription: "Derives the public key and address at change/address_index below an account-level extended public key. No wallet is read; this lets watch-only integrations check their own derivation against Vault.",
+	}
+}
+
+// handleXpubDerive handles address derivation from extended public keys
+func (b *TrustVaultBackend) handleXpubDerive(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	xpub := data.Get("xpub").(string)
+	if xpub == "" {
+		return logical.ErrorResponse("xpub is required"), nil
+	}
+
+	coinType, ok, err := coinField(data, "coin_type")
+	if err != nil {
+		b.logger.Warn("invalid coin type provided", "error", err)
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if !ok {
+		return logical.ErrorResponse("coin_type is required"), nil
+	}
+
+	change, err := indexField(data, "change")
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	addressIndex, err := indexField(data, "address_index")
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	addressType := data.Get("address_type").(string)
+	if addressType != "" {
+		if _, err := wallet.AddressTypePurpose(coinType, addressType); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	keys, err := b.walletService.DeriveFromExtendedKey(ctx, xpub, coinType, addressType, change, addressIndex)
+	if err != nil {
+		b.logger.Warn("failed to derive from extended public key", "coin_type", coinType, "error", err)
+		return b.handleError(err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"address":       keys.Address,
+			"public_key":    wallet.GetPublicKeyHex(keys.PublicKey),
+			"coin_type":     coinType,
+			"coin_symbol":   wallet.CoinSymbol(coinType),
+			"change":        change,
+			"address_index": addressIndex,
+		},
+	}, nil
+}
+
+// indexField returns a non-hardened BIP32 index field, or 0 if it is not set
+func indexField(data *framework.FieldData, field string) (uint32, error) {
+	raw, ok := data.GetOk(field)
+	if !ok {
+		return 0, nil
+	}
+	value := raw.(int)
+	if value < 0 || value > math.MaxInt32 {
+		return 0, fmt.Errorf("%s must be between 0 and %d", field, math.MaxInt32)
+	}
+	return uint32(value), nil
+}
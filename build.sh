@@ -52,8 +52,9 @@ OUTPUT_FILE="$BUILD_DIR/$PLUGIN_NAME"
 
 echo -e "${YELLOW}Building for $OS/$ARCH...${NC}"
 
-# Build the plugin
-go build -v -ldflags="$LDFLAGS" -o "$OUTPUT_FILE" ./cmd/trust-vault
+# Build the plugin; set TAGS="" for the pure-Go engine without Trust Wallet Core
+TAGS=${TAGS-trustwalletcore}
+go build -v -tags "$TAGS" -ldflags="$LDFLAGS" -o "$OUTPUT_FILE" ./cmd/trust-vault
 
 if [ $? -eq 0 ]; then
     echo -e "${GREEN}✓ Build successful: $OUTPUT_FILE${NC}"
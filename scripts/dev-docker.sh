@@ -33,8 +33,8 @@ echo "Changes you make locally will be reflected immediately"
 echo ""
 echo -e "${YELLOW}Quick commands:${NC}"
 echo "  go run test_wallet.go              - Run wallet tests"
-echo "  go build ./wallet                  - Build wallet package"
-echo "  go build -o test-plugin ./cmd/trust-vault - Build plugin"
+echo "  go build -tags trustwalletcore ./wallet - Build wallet package"
+echo "  go build -tags trustwalletcore -o test-plugin ./cmd/trust-vault - Build plugin"
 echo "  go test ./...                      - Run all tests"
 echo "  go vet ./...                       - Check for issues"
 echo ""
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
@@ -27,43 +30,176 @@ var (
 	ErrSigningFailed = errors.New("transaction signing failed")
 	// ErrInvalidWalletName is returned when wallet name is empty or invalid
 	ErrInvalidWalletName = errors.New("invalid wallet name")
+	// ErrInvalidMessage is returned when a message cannot be signed
+	ErrInvalidMessage = errors.New("invalid message")
+	// ErrUnsupportedMessageFormat is returned when a message format does not apply to the wallet
+	ErrUnsupportedMessageFormat = errors.New("unsupported message format")
+	// ErrCoinNotEnabled is returned when a wallet is used for a coin type it is not enabled for
+	ErrCoinNotEnabled = errors.New("coin type not enabled for wallet")
+	// ErrInvalidDerivationPath is returned when a key cannot be derived at the requested path
+	ErrInvalidDerivationPath = errors.New("invalid derivation path")
+	// ErrPassphraseRequired is returned when a wallet's passphrase is not stored and was not supplied
+	ErrPassphraseRequired = errors.New("passphrase required")
+	// ErrInvalidPassphrase is returned when a supplied passphrase does not match the wallet
+	ErrInvalidPassphrase = errors.New("invalid passphrase")
+	// ErrInvalidWordCount is returned when a mnemonic word count is not a BIP39 length
+	ErrInvalidWordCount = errors.New("invalid mnemonic word count")
+	// ErrInvalidExtendedKey is returned when an extended public key cannot be exported or parsed
+	ErrInvalidExtendedKey = errors.New("invalid extended public key")
+	// ErrInvalidAddressType is returned when an address type is unknown or not defined for a coin
+	ErrInvalidAddressType = errors.New("invalid address type")
+	// ErrAddressNotFound is returned when an address was not handed out by any wallet
+	ErrAddressNotFound = errors.New("address not found")
+	// ErrCoinDisabled is returned when a coin type is not in the mount's allow-list
+	ErrCoinDisabled = errors.New("coin type disabled on this mount")
+	// ErrWalletDisabled is returned when a disabled wallet is used for an operation that needs its keys
+	ErrWalletDisabled = errors.New("wallet is disabled")
+	// ErrVersionConflict is returned when a wallet update is based on an outdated version
+	ErrVersionConflict = errors.New("wallet version conflict")
+	// ErrInvalidMetadata is returned when a wallet's description, owner, tags or metadata are invalid
+	ErrInvalidMetadata = errors.New("invalid wallet metadata")
 )
 
+// DefaultCoinType selects the coin type a wallet was created with
+const DefaultCoinType = math.MaxUint32
+
+// SigningKey selects which key of a wallet's seed is used for an operation
+type SigningKey struct {
+	// CoinType is the chain to use; DefaultCoinType selects the wallet's creation coin
+	CoinType uint32
+	// DerivationPath is an explicit BIP32 path; empty uses the coin's default path
+	DerivationPath string
+	// Index, when set, selects m/purpose'/coin'/account'/change/address_index
+	Index *KeyIndex
+	// Passphrase is the BIP39 passphrase of wallets that do not store theirs
+	Passphrase string
+}
+
+// KeyIndex identifies a key by its BIP44 account, change and address index
+type KeyIndex struct {
+	Account      uint32
+	Change       uint32
+	AddressIndex uint32
+}
+
+// DefaultSigningKey selects the key at the default path of the wallet's creation coin
+var DefaultSigningKey = SigningKey{CoinType: DefaultCoinType}
+
+// isDefault reports whether the key is the one recorded when the wallet was created
+func (k SigningKey) isDefault(creationCoin uint32) bool {
+	return (k.CoinType == DefaultCoinType || k.CoinType == creationCoin) && k.DerivationPath == "" && k.Index == nil
+}
+
+// MessageSignature is a signed off-chain message with the format and chain that were applied
+type MessageSignature struct {
+	Signature string
+	Format    string
+	CoinType  uint32
+}
+
+// MessageVerification is the outcome of a signature check with the format and chain that were applied
+type MessageVerification struct {
+	Valid    bool
+	Format   string
+	CoinType uint32
+}
+
+// WalletOptions holds the optional settings of a new wallet
+type WalletOptions struct {
+	// CoinTypes lists additional chains the seed is enabled for
+	CoinTypes []uint32
+	// Passphrase is the optional BIP39 passphrase mixed into the seed
+	Passphrase string
+	// ForgetPassphrase keeps the passphrase out of storage; callers then supply it per request
+	ForgetPassphrase bool
+	// WordCount is the length of a generated mnemonic; 0 uses the mount default
+	// For imports it is optional and must match the supplied mnemonic
+	WordCount int
+	// Wordlist is the BIP39 wordlist; only English is supported
+	Wordlist string
+	// AddressType is the Bitcoin address type of the default key; empty uses p2wpkh
+	AddressType string
+	// Description, Owner, Tags, Metadata and Labels are the wallet's initial mutable metadata
+	Description string
+	Owner       string
+	Tags        map[string]string
+	Metadata    map[string]string
+	Labels      []string
+}
+
 // WalletService provides business logic for wallet operations
 type WalletService struct {
-	storage     *storage.StorageService
-	trustWallet *wallet.TrustWalletCore
-	logger      hclog.Logger
+	storage storage.WalletStore
+	engine  wallet.Engine
+	logger  hclog.Logger
 }
 
-// NewWalletService creates a new wallet service instance
-func NewWalletService(storageService *storage.StorageService, logger hclog.Logger) *WalletService {
+// NewWalletService creates a new wallet service instance over a wallet store
+// Keys are derived and signed with engine
+func NewWalletService(store storage.WalletStore, engine wallet.Engine, logger hclog.Logger) *WalletService {
 	return &WalletService{
-		storage:     storageService,
-		trustWallet: wallet.NewTrustWalletCore(),
-		logger:      logger,
+		storage: store,
+		engine:  engine,
+		logger:  logger,
 	}
 }
 
-// CreateWallet generates a new wallet via Trust Wallet Core and stores it
+// CreateWallet generates a new wallet via the wallet engine and stores it
 // If mnemonic is provided, it imports the wallet instead of generating a new one
-func (ws *WalletService) CreateWallet(ctx context.Context, name string, coinType uint32, mnemonic string) (*storage.Wallet, error) {
+// coinType is always enabled; opts may enable more chains and set a passphrase
+func (ws *WalletService) CreateWallet(ctx context.Context, name string, coinType uint32, mnemonic string, opts WalletOptions) (*storage.Wallet, error) {
 	if name == "" {
 		ws.logger.Warn("attempted to create wallet with empty name")
 		return nil, ErrInvalidWalletName
 	}
 
+	enabled, err := ws.enabledCoinTypes(coinType, opts.CoinTypes)
+	if err != nil {
+		ws.logger.Warn("invalid coin types for wallet", "name", sanitizeName(name), "error", err)
+		return nil, err
+	}
+
+	if err := ws.checkCoinsEnabled(ctx, enabled...); err != nil {
+		ws.logger.Warn("coin type disabled for wallet", "name", sanitizeName(name), "error", err)
+		return nil, err
+	}
+
+	if err := wallet.ValidateWordlist(opts.Wordlist); err != nil {
+		ws.logger.Warn("unsupported wordlist", "name", sanitizeName(name))
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
+	}
+
+	if err := validateWalletMetadata(opts.Description, opts.Owner, opts.Tags, opts.Metadata, opts.Labels); err != nil {
+		ws.logger.Warn("invalid wallet metadata", "name", sanitizeName(name), "error", err)
+		return nil, err
+	}
+
+	addressType := opts.AddressType
+	if addressType == "" {
+		addressType = wallet.DefaultAddressType(coinType)
+	}
+	if addressType != "" {
+		if _, err := wallet.AddressTypePurpose(coinType, addressType); err != nil {
+			ws.logger.Warn("invalid address type for wallet", "name", sanitizeName(name), "coin_type", coinType, "address_type", addressType)
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddressType, err)
+		}
+	}
+
 	var keys *wallet.WalletKeys
-	var err error
 
 	// Generate or import wallet based on whether mnemonic is provided
 	if mnemonic != "" {
+		if words := len(wallet.MnemonicWords(mnemonic)); opts.WordCount != 0 && words != opts.WordCount {
+			ws.logger.Warn("mnemonic word count mismatch", "name", sanitizeName(name), "word_count", opts.WordCount)
+			return nil, fmt.Errorf("%w: mnemonic has %d words, expected %d", ErrInvalidMnemonic, words, opts.WordCount)
+		}
+
 		ws.logger.Debug("importing wallet from mnemonic", "name", sanitizeName(name), "coin_type", coinType)
-		keys, err = ws.trustWallet.ImportWallet(mnemonic, coinType)
+		keys, err = ws.engine.ImportWallet(mnemonic, opts.Passphrase, coinType)
 		if err != nil {
 			if errors.Is(err, wallet.ErrInvalidMnemonic) {
-				ws.logger.Warn("invalid mnemonic provided", "name", sanitizeName(name))
-				return nil, ErrInvalidMnemonic
+				ws.logger.Warn("invalid mnemonic provided", "name", sanitizeName(name), "error", sanitizeError(err))
+				return nil, fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
 			}
 			if errors.Is(err, wallet.ErrInvalidCoinType) {
 				ws.logger.Warn("invalid coin type for import", "name", sanitizeName(name), "coin_type", coinType)
@@ -73,9 +209,19 @@ func (ws *WalletService) CreateWallet(ctx context.Context, name string, coinType
 			return nil, fmt.Errorf("failed to import wallet: %w", err)
 		}
 	} else {
-		ws.logger.Debug("generating new wallet", "name", sanitizeName(name), "coin_type", coinType)
-		keys, err = ws.trustWallet.GenerateWallet(coinType)
+		wordCount, err := ws.wordCount(ctx, opts.WordCount)
+		if err != nil {
+			ws.logger.Error("failed to read mount config", "error", err)
+			return nil, err
+		}
+
+		ws.logger.Debug("generating new wallet", "name", sanitizeName(name), "coin_type", coinType, "word_count", wordCount)
+		keys, err = ws.engine.GenerateWallet(coinType, wordCount, opts.Passphrase)
 		if err != nil {
+			if errors.Is(err, wallet.ErrInvalidWordCount) {
+				ws.logger.Warn("invalid mnemonic word count", "name", sanitizeName(name), "word_count", wordCount)
+				return nil, fmt.Errorf("%w: %v", ErrInvalidWordCount, err)
+			}
 			if errors.Is(err, wallet.ErrInvalidCoinType) {
 				ws.logger.Warn("invalid coin type for generation", "name", sanitizeName(name), "coin_type", coinType)
 				return nil, ErrInvalidCoinType
@@ -87,15 +233,60 @@ func (ws *WalletService) CreateWallet(ctx context.Context, name string, coinType
 
 	ws.logger.Debug("wallet keys generated successfully", "name", sanitizeName(name))
 
+	// Signing keys are derived from the seed on demand, so no private key is stored
+	for i := range keys.PrivateKey {
+		keys.PrivateKey[i] = 0
+	}
+
+	// The default key of a wallet with an address type sits at the purpose paired with it
+	if addressType != "" {
+		path, err := ws.keyPath(coinType, addressType, nil)
+		if err != nil {
+			ws.logger.Error("failed to build default key path", "name", sanitizeName(name), "address_type", addressType, "error", err)
+			return nil, fmt.Errorf("failed to build default key path: %w", err)
+		}
+
+		typed, err := ws.deriveKey(keys.Mnemonic, opts.Passphrase, coinType, path, addressType)
+		if err != nil {
+			ws.logger.Error("failed to derive default key", "name", sanitizeName(name), "address_type", addressType, "error", sanitizeError(err))
+			return nil, fmt.Errorf("failed to derive default key: %w", err)
+		}
+		for i := range typed.PrivateKey {
+			typed.PrivateKey[i] = 0
+		}
+
+		keys.PublicKey = typed.PublicKey
+		keys.Address = typed.Address
+	}
+
 	// Create wallet object
+	now := time.Now().UTC()
 	walletObj := &storage.Wallet{
-		Name:       name,
-		CoinType:   coinType,
-		Mnemonic:   keys.Mnemonic,
-		PrivateKey: keys.PrivateKey,
-		PublicKey:  wallet.GetPublicKeyHex(keys.PublicKey),
-		Address:    keys.Address,
-		CreatedAt:  time.Now().UTC(),
+		Name:        name,
+		CoinType:    coinType,
+		Mnemonic:    keys.Mnemonic,
+		PublicKey:   wallet.GetPublicKeyHex(keys.PublicKey),
+		Address:     keys.Address,
+		Curve:       keys.Curve,
+		CoinTypes:   enabled,
+		WordCount:   len(wallet.MnemonicWords(keys.Mnemonic)),
+		AddressType: addressType,
+		CreatedAt:   now,
+		Description: opts.Description,
+		Owner:       opts.Owner,
+		Tags:        nonEmptyEntries(opts.Tags),
+		Metadata:    nonEmptyEntries(opts.Metadata),
+		Labels:      normalizeLabels(opts.Labels),
+		Version:     1,
+		UpdatedAt:   now,
+	}
+
+	if opts.Passphrase != "" {
+		if opts.ForgetPassphrase {
+			walletObj.PassphraseRequired = true
+		} else {
+			walletObj.Passphrase = opts.Passphrase
+		}
 	}
 
 	// Store wallet
@@ -112,14 +303,80 @@ func (ws *WalletService) CreateWallet(ctx context.Context, name string, coinType
 
 	// Return wallet without sensitive fields
 	return &storage.Wallet{
-		Name:      walletObj.Name,
-		CoinType:  walletObj.CoinType,
-		PublicKey: walletObj.PublicKey,
-		Address:   walletObj.Address,
-		CreatedAt: walletObj.CreatedAt,
+		Name:               walletObj.Name,
+		CoinType:           walletObj.CoinType,
+		PublicKey:          walletObj.PublicKey,
+		Address:            walletObj.Address,
+		Curve:              walletObj.Curve,
+		CoinTypes:          walletObj.CoinTypes,
+		WordCount:          walletObj.WordCount,
+		AddressType:        walletObj.AddressType,
+		PassphraseRequired: walletObj.PassphraseRequired,
+		CreatedAt:          walletObj.CreatedAt,
+		Description:        walletObj.Description,
+		Owner:              walletObj.Owner,
+		Tags:               walletObj.Tags,
+		Metadata:           walletObj.Metadata,
+		Labels:             walletObj.Labels,
+		Version:            walletObj.Version,
+		UpdatedAt:          walletObj.UpdatedAt,
 	}, nil
 }
 
+// wordCount resolves the length of a generated mnemonic
+// A requested count wins over the mount default, which wins over wallet.DefaultWordCount
+func (ws *WalletService) wordCount(ctx context.Context, requested int) (int, error) {
+	if requested != 0 {
+		return requested, nil
+	}
+
+	config, err := ws.storage.GetMountConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if config.DefaultWordCount != 0 {
+		return config.DefaultWordCount, nil
+	}
+
+	return wallet.DefaultWordCount, nil
+}
+
+// checkCoinsEnabled checks coin types against the mount's allow-list
+func (ws *WalletService) checkCoinsEnabled(ctx context.Context, coinTypes ...uint32) error {
+	config, err := ws.storage.GetMountConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, coinType := range coinTypes {
+		if !config.CoinEnabled(coinType) {
+			return fmt.Errorf("%w: %d", ErrCoinDisabled, coinType)
+		}
+	}
+
+	return nil
+}
+
+// enabledCoinTypes validates the coin types a wallet is enabled for
+// The creation coin type comes first, followed by the others without duplicates
+func (ws *WalletService) enabledCoinTypes(coinType uint32, coinTypes []uint32) ([]uint32, error) {
+	enabled := []uint32{coinType}
+	seen := map[uint32]bool{coinType: true}
+
+	for _, ct := range coinTypes {
+		if seen[ct] {
+			continue
+		}
+		if _, err := wallet.Curve(ct); err != nil {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, ct)
+		}
+		seen[ct] = true
+		enabled = append(enabled, ct)
+	}
+
+	return enabled, nil
+}
+
 // GetWallet retrieves wallet metadata without exposing private keys or mnemonic
 func (ws *WalletService) GetWallet(ctx context.Context, name string) (*storage.Wallet, error) {
 	if name == "" {
@@ -140,6 +397,13 @@ func (ws *WalletService) GetWallet(ctx context.Context, name string) (*storage.W
 		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
 	}
 
+	// Wallets stored before curves were recorded report the coin's curve
+	if walletObj.Curve == "" {
+		if curve, err := wallet.Curve(walletObj.CoinType); err == nil {
+			walletObj.Curve = curve
+		}
+	}
+
 	ws.logger.Debug("wallet metadata retrieved successfully", "name", sanitizeName(name))
 
 	return walletObj, nil
@@ -169,130 +433,1226 @@ func (ws *WalletService) DeleteWallet(ctx context.Context, name string) error {
 	return nil
 }
 
-// ListWallets returns a list of all wallet names with pagination support
-func (ws *WalletService) ListWallets(ctx context.Context, offset, limit int) ([]string, error) {
+// WalletUpdate holds the changes of a wallet metadata update; nil fields are left unchanged
+type WalletUpdate struct {
+	Description *string
+	Owner       *string
+	Enabled     *bool
+	// Tags and Metadata are merged into the stored maps; an entry with an empty value is removed
+	Tags     map[string]string
+	Metadata map[string]string
+	// Labels, when not nil, replaces the stored labels; an empty slice removes them all
+	Labels []string
+	// CAS, when set, is the version the wallet must be at for the update to apply
+	CAS *uint64
+}
+
+// maxUpdateAttempts bounds how often an update without CAS is re-applied after a concurrent update
+const maxUpdateAttempts = 10
+
+// UpdateWallet applies an update to a wallet's mutable metadata; key material is never touched
+// Without CAS, an update that races another one is re-applied to the newer version,
+// so neither update is lost
+func (ws *WalletService) UpdateWallet(ctx context.Context, name string, update WalletUpdate) (*storage.Wallet, error) {
+	if name == "" {
+		ws.logger.Warn("attempted to update wallet with empty name")
+		return nil, ErrInvalidWalletName
+	}
+
+	for attempt := 1; ; attempt++ {
+		walletObj, err := ws.GetWallet(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if update.CAS != nil && *update.CAS != walletObj.Version {
+			ws.logger.Warn("wallet version conflict", "name", sanitizeName(name), "version", walletObj.Version, "cas", *update.CAS)
+			return nil, fmt.Errorf("%w: current version is %d, cas is %d", ErrVersionConflict, walletObj.Version, *update.CAS)
+		}
+
+		if update.Description != nil {
+			walletObj.Description = *update.Description
+		}
+		if update.Owner != nil {
+			walletObj.Owner = *update.Owner
+		}
+		if update.Enabled != nil {
+			walletObj.Disabled = !*update.Enabled
+		}
+		walletObj.Tags = mergeEntries(walletObj.Tags, update.Tags)
+		walletObj.Metadata = mergeEntries(walletObj.Metadata, update.Metadata)
+		if update.Labels != nil {
+			walletObj.Labels = normalizeLabels(update.Labels)
+		}
+
+		if err := validateWalletMetadata(walletObj.Description, walletObj.Owner, walletObj.Tags, walletObj.Metadata, walletObj.Labels); err != nil {
+			ws.logger.Warn("invalid wallet metadata", "name", sanitizeName(name), "error", err)
+			return nil, err
+		}
+
+		err = ws.storage.UpdateWallet(ctx, walletObj)
+		if err == nil {
+			ws.logger.Info("wallet updated successfully", "name", sanitizeName(name), "version", walletObj.Version)
+			return walletObj, nil
+		}
+
+		switch {
+		case errors.Is(err, storage.ErrWalletNotFound):
+			ws.logger.Warn("wallet not found for update", "name", sanitizeName(name))
+			return nil, ErrWalletNotFound
+		case errors.Is(err, storage.ErrVersionConflict):
+			if update.CAS != nil || attempt == maxUpdateAttempts {
+				ws.logger.Warn("wallet version conflict", "name", sanitizeName(name), "error", err)
+				return nil, fmt.Errorf("%w: %v", ErrVersionConflict, err)
+			}
+			ws.logger.Debug("wallet changed during update, retrying", "name", sanitizeName(name), "attempt", attempt)
+		default:
+			ws.logger.Error("failed to update wallet", "name", sanitizeName(name), "error", err)
+			return nil, fmt.Errorf("failed to update wallet: %w", err)
+		}
+	}
+}
+
+// checkWalletEnabled refuses operations on a disabled wallet
+func (ws *WalletService) checkWalletEnabled(walletObj *storage.Wallet) error {
+	if walletObj.Disabled {
+		ws.logger.Warn("attempted to use disabled wallet", "name", sanitizeName(walletObj.Name))
+		return ErrWalletDisabled
+	}
+	return nil
+}
+
+// ListWallets returns a page of the names of the wallets matching a filter
+func (ws *WalletService) ListWallets(ctx context.Context, filter storage.WalletFilter, offset, limit int) ([]string, error) {
 	ws.logger.Debug("listing wallets", "offset", offset, "limit", limit)
 
-	wallets, err := ws.storage.ListWallets(ctx, offset, limit)
+	wallets, err := ws.storage.FindWallets(ctx, filter, offset, limit)
+	if err != nil {
+		ws.logger.Error("failed to list wallets", "error", err)
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+
+	ws.logger.Debug("wallets listed successfully", "count", len(wallets))
+
+	return wallets, nil
+}
+
+// ListWalletsWithMetadata returns the metadata of a page of the wallets matching a filter
+func (ws *WalletService) ListWalletsWithMetadata(ctx context.Context, filter storage.WalletFilter, offset, limit int) ([]*storage.Wallet, error) {
+	ws.logger.Debug("listing wallets with metadata", "offset", offset, "limit", limit)
+
+	wallets, err := ws.storage.ListWalletsWithMetadata(ctx, filter, offset, limit)
 	if err != nil {
 		ws.logger.Error("failed to list wallets", "error", err)
 		return nil, fmt.Errorf("failed to list wallets: %w", err)
 	}
 
+	// Wallets stored before curves were recorded report the coin's curve
+	for _, walletObj := range wallets {
+		if walletObj.Curve == "" {
+			if curve, err := wallet.Curve(walletObj.CoinType); err == nil {
+				walletObj.Curve = curve
+			}
+		}
+	}
+
 	ws.logger.Debug("wallets listed successfully", "count", len(wallets))
 
 	return wallets, nil
 }
 
 // SignTransaction retrieves a wallet, signs the transaction, and clears sensitive data from memory
-func (ws *WalletService) SignTransaction(ctx context.Context, name string, txData []byte) ([]byte, error) {
+// key selects the chain and derivation path of the signing key; the chain's coin type is returned
+func (ws *WalletService) SignTransaction(ctx context.Context, name string, key SigningKey, txData []byte) ([]byte, uint32, error) {
 	if name == "" {
 		ws.logger.Warn("attempted to sign transaction with empty wallet name")
-		return nil, ErrInvalidWalletName
+		return nil, 0, ErrInvalidWalletName
 	}
 
 	if len(txData) == 0 {
 		ws.logger.Warn("attempted to sign empty transaction data", "name", sanitizeName(name))
-		return nil, ErrInvalidTxData
+		return nil, 0, ErrInvalidTxData
 	}
 
 	ws.logger.Debug("signing transaction", "name", sanitizeName(name), "tx_size", len(txData))
 
 	// Retrieve wallet with decrypted private key
-	walletObj, err := ws.storage.GetWallet(ctx, name)
+	walletObj, cleanup, err := ws.loadSigningWallet(ctx, name, key)
 	if err != nil {
-		if errors.Is(err, storage.ErrWalletNotFound) {
-			ws.logger.Warn("wallet not found for signing", "name", sanitizeName(name))
-			return nil, ErrWalletNotFound
-		}
-		ws.logger.Error("failed to retrieve wallet for signing", "name", sanitizeName(name), "error", err)
-		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+		return nil, 0, err
 	}
-
-	// Ensure private key is cleared from memory after use
-	defer func() {
-		// Clear private key from memory
-		for i := range walletObj.PrivateKey {
-			walletObj.PrivateKey[i] = 0
-		}
-		// Clear mnemonic from memory
-		walletObj.Mnemonic = ""
-		// Force garbage collection to clear memory
-		runtime.GC()
-		ws.logger.Debug("sensitive data cleared from memory", "name", sanitizeName(name))
-	}()
+	defer cleanup()
 
 	// Sign transaction
-	signature, err := ws.trustWallet.SignTransaction(walletObj.PrivateKey, walletObj.CoinType, txData)
+	signature, err := ws.engine.SignTransaction(walletObj.PrivateKey, walletObj.CoinType, txData)
 	if err != nil {
 		if errors.Is(err, wallet.ErrSigningFailed) {
 			ws.logger.Error("transaction signing failed", "name", sanitizeName(name), "error", sanitizeError(err))
-			return nil, ErrSigningFailed
+			return nil, 0, ErrSigningFailed
 		}
 		if errors.Is(err, wallet.ErrInvalidCoinType) {
 			ws.logger.Warn("invalid coin type for signing", "name", sanitizeName(name), "coin_type", walletObj.CoinType)
-			return nil, ErrInvalidCoinType
+			return nil, 0, ErrInvalidCoinType
 		}
 		ws.logger.Error("failed to sign transaction", "name", sanitizeName(name), "error", sanitizeError(err))
-		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		return nil, 0, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	ws.logger.Info("transaction signed successfully", "name", sanitizeName(name), "signature_size", len(signature))
 
-	return signature, nil
+	return signature, walletObj.CoinType, nil
 }
 
-// GetAddress derives an address for a specific coin type and optional derivation path
-func (ws *WalletService) GetAddress(ctx context.Context, name string, coinType uint32, derivationPath string) (string, error) {
+// SignInput signs a chain-specific Trust Wallet Core SigningInput protobuf
+// The wallet's private key is injected into the input and cleared from memory afterwards
+func (ws *WalletService) SignInput(ctx context.Context, name string, key SigningKey, input []byte) (*wallet.SignedTransaction, error) {
 	if name == "" {
-		ws.logger.Warn("attempted to get address with empty wallet name")
-		return "", ErrInvalidWalletName
+		ws.logger.Warn("attempted to sign input with empty wallet name")
+		return nil, ErrInvalidWalletName
 	}
 
-	ws.logger.Debug("deriving address", "name", sanitizeName(name), "coin_type", coinType, "has_custom_path", derivationPath != "")
+	if len(input) == 0 {
+		ws.logger.Warn("attempted to sign empty signing input", "name", sanitizeName(name))
+		return nil, ErrInvalidTxData
+	}
 
-	// Retrieve wallet with decrypted mnemonic
-	walletObj, err := ws.storage.GetWallet(ctx, name)
+	ws.logger.Debug("signing transaction input", "name", sanitizeName(name), "input_size", len(input))
+
+	// Retrieve wallet with decrypted private key
+	walletObj, cleanup, err := ws.loadSigningWallet(ctx, name, key)
 	if err != nil {
-		if errors.Is(err, storage.ErrWalletNotFound) {
-			ws.logger.Warn("wallet not found for address derivation", "name", sanitizeName(name))
-			return "", ErrWalletNotFound
+		return nil, err
+	}
+	defer cleanup()
+
+	return ws.signInput(name, walletObj, input)
+}
+
+// SignJSONTransaction converts a chain-specific JSON transaction description into a
+// Trust Wallet Core SigningInput and signs it with the wallet's private key
+// If chain names an EVM chain profile, the Ethereum transaction is signed for that chain
+func (ws *WalletService) SignJSONTransaction(ctx context.Context, name string, key SigningKey, chain string, description []byte) (*wallet.SignedTransaction, error) {
+	if name == "" {
+		ws.logger.Warn("attempted to sign json transaction with empty wallet name")
+		return nil, ErrInvalidWalletName
+	}
+
+	if len(description) == 0 {
+		ws.logger.Warn("attempted to sign empty json transaction", "name", sanitizeName(name))
+		return nil, ErrInvalidTxData
+	}
+
+	ws.logger.Debug("signing json transaction", "name", sanitizeName(name), "chain", sanitizeName(chain))
+
+	var profile *storage.ChainProfile
+	if chain != "" {
+		var err error
+		if profile, err = ws.storage.GetChain(ctx, chain); err != nil {
+			ws.logger.Warn("failed to load chain profile", "name", sanitizeName(name), "chain", sanitizeName(chain), "error", err)
+			return nil, err
 		}
-		ws.logger.Error("failed to retrieve wallet for address derivation", "name", sanitizeName(name), "error", err)
-		return "", fmt.Errorf("failed to retrieve wallet: %w", err)
 	}
 
-	// Ensure mnemonic is cleared from memory after use
-	defer func() {
-		// Clear mnemonic from memory
-		walletObj.Mnemonic = ""
-		// Clear private key from memory
-		for i := range walletObj.PrivateKey {
-			walletObj.PrivateKey[i] = 0
+	// Retrieve wallet with decrypted private key
+	walletObj, cleanup, err := ws.loadSigningWallet(ctx, name, key)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	publicKey, err := hex.DecodeString(walletObj.PublicKey)
+	if err != nil {
+		ws.logger.Error("failed to decode wallet public key", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to decode wallet public key: %w", err)
+	}
+
+	// Convert the description into the chain's SigningInput
+	var input []byte
+	if profile != nil {
+		if walletObj.CoinType != wallet.CoinTypeEthereum {
+			ws.logger.Warn("chain profile used with non-ethereum key", "name", sanitizeName(name), "coin_type", walletObj.CoinType)
+			return nil, fmt.Errorf("%w: chain profiles require an Ethereum key (coin type %d)", ErrInvalidTxData, wallet.CoinTypeEthereum)
 		}
-		// Force garbage collection to clear memory
-		runtime.GC()
-		ws.logger.Debug("sensitive data cleared from memory", "name", sanitizeName(name))
-	}()
+		input, err = wallet.BuildEVMSigningInput(description, wallet.EVMChain{ChainID: profile.ChainID, EIP1559: profile.EIP1559})
+	} else {
+		input, err = wallet.BuildSigningInput(walletObj.CoinType, description, publicKey, walletObj.Address, walletObj.AddressType)
+	}
+	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidTransaction) {
+			ws.logger.Warn("invalid json transaction", "name", sanitizeName(name), "error", sanitizeError(err))
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTxData, err)
+		}
+		if errors.Is(err, wallet.ErrInvalidCoinType) {
+			ws.logger.Warn("json transactions not supported for coin type", "name", sanitizeName(name), "coin_type", walletObj.CoinType)
+			return nil, ErrInvalidCoinType
+		}
+		ws.logger.Error("failed to build signing input", "name", sanitizeName(name), "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to build signing input: %w", err)
+	}
 
-	// Derive address
-	address, err := ws.trustWallet.DeriveAddress(walletObj.Mnemonic, coinType, derivationPath)
+	return ws.signInput(name, walletObj, input)
+}
+
+// signInput signs a SigningInput with the chain's AnySigner using a loaded wallet
+func (ws *WalletService) signInput(name string, walletObj *storage.Wallet, input []byte) (*wallet.SignedTransaction, error) {
+	signed, err := ws.engine.SignInput(walletObj.PrivateKey, walletObj.CoinType, input)
 	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidSigningInput) {
+			ws.logger.Warn("invalid signing input", "name", sanitizeName(name), "error", sanitizeError(err))
+			return nil, ErrInvalidTxData
+		}
+		if errors.Is(err, wallet.ErrSigningFailed) {
+			ws.logger.Error("transaction signing failed", "name", sanitizeName(name), "error", sanitizeError(err))
+			return nil, ErrSigningFailed
+		}
 		if errors.Is(err, wallet.ErrInvalidCoinType) {
-			ws.logger.Warn("invalid coin type for address derivation", "name", sanitizeName(name), "coin_type", coinType)
-			return "", ErrInvalidCoinType
+			ws.logger.Warn("invalid coin type for signing input", "name", sanitizeName(name), "coin_type", walletObj.CoinType)
+			return nil, ErrInvalidCoinType
 		}
-		if errors.Is(err, wallet.ErrAddressDerivation) {
-			ws.logger.Error("address derivation failed", "name", sanitizeName(name), "error", sanitizeError(err))
-			return "", fmt.Errorf("address derivation failed: %w", err)
+		ws.logger.Error("failed to sign transaction input", "name", sanitizeName(name), "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to sign transaction input: %w", err)
+	}
+
+	ws.logger.Info("transaction input signed successfully", "name", sanitizeName(name), "tx_hash", signed.TxHash)
+
+	return signed, nil
+}
+
+// SignMessage signs an off-chain message using the domain separation of the given format
+// If format is empty, the wallet chain's default format is used
+// It returns the encoded signature with the format and coin type that were applied
+func (ws *WalletService) SignMessage(ctx context.Context, name string, key SigningKey, format string, message string) (*MessageSignature, error) {
+	if name == "" {
+		ws.logger.Warn("attempted to sign message with empty wallet name")
+		return nil, ErrInvalidWalletName
+	}
+
+	if message == "" {
+		ws.logger.Warn("attempted to sign empty message", "name", sanitizeName(name))
+		return nil, ErrInvalidMessage
+	}
+
+	ws.logger.Debug("signing message", "name", sanitizeName(name), "format", format, "message_size", len(message))
+
+	// Retrieve wallet with decrypted private key
+	walletObj, cleanup, err := ws.loadSigningWallet(ctx, name, key)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if format == "" {
+		format = wallet.DefaultMessageFormat(walletObj.CoinType)
+	}
+
+	signature, err := ws.engine.SignMessage(walletObj.PrivateKey, walletObj.CoinType, format, message, walletObj.Address)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedMessageFormat) {
+			ws.logger.Warn("unsupported message format", "name", sanitizeName(name), "format", format, "coin_type", walletObj.CoinType)
+			return nil, fmt.Errorf("%w: %s is not supported for coin type %d", ErrUnsupportedMessageFormat, format, walletObj.CoinType)
 		}
-		ws.logger.Error("failed to derive address", "name", sanitizeName(name), "error", sanitizeError(err))
-		return "", fmt.Errorf("failed to derive address: %w", err)
+		if errors.Is(err, wallet.ErrInvalidMessage) {
+			ws.logger.Warn("invalid message", "name", sanitizeName(name), "format", format, "error", sanitizeError(err))
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+		if errors.Is(err, wallet.ErrSigningFailed) {
+			ws.logger.Error("message signing failed", "name", sanitizeName(name), "error", sanitizeError(err))
+			return nil, ErrSigningFailed
+		}
+		ws.logger.Error("failed to sign message", "name", sanitizeName(name), "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	ws.logger.Info("message signed successfully", "name", sanitizeName(name), "format", format)
+
+	return &MessageSignature{Signature: signature, Format: format, CoinType: walletObj.CoinType}, nil
+}
+
+// VerifyMessage checks a signature against the public key of one of the wallet's keys
+// If format is empty, the chain's default message format is used
+// It returns whether the signature is valid with the format and coin type that were applied
+func (ws *WalletService) VerifyMessage(ctx context.Context, name string, key SigningKey, format string, message string, signature string) (*MessageVerification, error) {
+	if name == "" {
+		ws.logger.Warn("attempted to verify signature with empty wallet name")
+		return nil, ErrInvalidWalletName
+	}
+
+	if message == "" || signature == "" {
+		ws.logger.Warn("attempted to verify empty message or signature", "name", sanitizeName(name))
+		return nil, ErrInvalidMessage
+	}
+
+	ws.logger.Debug("verifying signature", "name", sanitizeName(name), "format", format)
+
+	// Only the public key is needed, so the wallet is not decrypted
+	walletObj, err := ws.storage.GetWalletMetadata(ctx, name)
+	if err != nil {
+		if errors.Is(err, storage.ErrWalletNotFound) {
+			ws.logger.Warn("wallet not found for verification", "name", sanitizeName(name))
+			return nil, ErrWalletNotFound
+		}
+		ws.logger.Error("failed to retrieve wallet for verification", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+
+	// The same checks apply to the creation key and to keys derived from the seed
+	coinType, err := ws.checkSigningKey(ctx, name, walletObj, key)
+	if err != nil {
+		return nil, err
 	}
 
-	ws.logger.Debug("address derived successfully", "name", sanitizeName(name), "coin_type", coinType)
+	// Public keys other than the creation key are derived from the seed
+	if !key.isDefault(walletObj.CoinType) {
+		seedWallet, cleanup, err := ws.loadSeedWallet(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		err = ws.deriveSigningKey(name, seedWallet, key, coinType)
+		derived := &storage.Wallet{CoinType: coinType, PublicKey: seedWallet.PublicKey}
+		cleanup()
+		if err != nil {
+			return nil, err
+		}
+		walletObj = derived
+	}
 
-	return address, nil
+	publicKey, err := hex.DecodeString(walletObj.PublicKey)
+	if err != nil {
+		ws.logger.Error("failed to decode wallet public key", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to decode wallet public key: %w", err)
+	}
+
+	if format == "" {
+		format = wallet.DefaultMessageFormat(walletObj.CoinType)
+	}
+
+	valid, err := ws.engine.VerifyMessage(publicKey, walletObj.CoinType, format, message, signature)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedMessageFormat) {
+			ws.logger.Warn("unsupported message format", "name", sanitizeName(name), "format", format, "coin_type", walletObj.CoinType)
+			return nil, fmt.Errorf("%w: %s is not supported for coin type %d", ErrUnsupportedMessageFormat, format, walletObj.CoinType)
+		}
+		if errors.Is(err, wallet.ErrInvalidMessage) {
+			ws.logger.Warn("invalid message or signature", "name", sanitizeName(name), "format", format, "error", sanitizeError(err))
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+		if errors.Is(err, wallet.ErrInvalidCoinType) {
+			ws.logger.Warn("invalid coin type for verification", "name", sanitizeName(name), "coin_type", walletObj.CoinType)
+			return nil, ErrInvalidCoinType
+		}
+		ws.logger.Error("failed to verify signature", "name", sanitizeName(name), "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	ws.logger.Info("signature verified", "name", sanitizeName(name), "format", format, "valid", valid)
+
+	return &MessageVerification{Valid: valid, Format: format, CoinType: walletObj.CoinType}, nil
+}
+
+// loadSigningWallet retrieves a wallet and derives the selected key from the seed
+// The returned wallet carries the key's private key, public key and address
+// The returned cleanup function clears the key material and must always be called
+func (ws *WalletService) loadSigningWallet(ctx context.Context, name string, key SigningKey) (*storage.Wallet, func(), error) {
+	walletObj, cleanup, err := ws.loadSeedWallet(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coinType, err := ws.checkSigningKey(ctx, name, walletObj, key)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	if err := ws.deriveSigningKey(name, walletObj, key, coinType); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return walletObj, cleanup, nil
+}
+
+// loadSeedWallet retrieves a wallet with its decrypted seed
+// The returned cleanup function clears the key material and must always be called
+func (ws *WalletService) loadSeedWallet(ctx context.Context, name string) (*storage.Wallet, func(), error) {
+	walletObj, err := ws.storage.GetWallet(ctx, name)
+	if err != nil {
+		if errors.Is(err, storage.ErrWalletNotFound) {
+			ws.logger.Warn("wallet not found for signing", "name", sanitizeName(name))
+			return nil, nil, ErrWalletNotFound
+		}
+		ws.logger.Error("failed to retrieve wallet for signing", "name", sanitizeName(name), "error", err)
+		return nil, nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+
+	cleanup := func() {
+		// Clear private key from memory
+		for i := range walletObj.PrivateKey {
+			walletObj.PrivateKey[i] = 0
+		}
+		// Clear mnemonic and passphrase from memory
+		walletObj.Mnemonic = ""
+		walletObj.Passphrase = ""
+		// Force garbage collection to clear memory
+		runtime.GC()
+		ws.logger.Debug("sensitive data cleared from memory", "name", sanitizeName(name))
+	}
+
+	return walletObj, cleanup, nil
+}
+
+// checkSigningKey checks that a wallet is enabled and may use the selected key's coin type
+// on this mount, and returns that coin type; only the wallet's metadata is read
+func (ws *WalletService) checkSigningKey(ctx context.Context, name string, walletObj *storage.Wallet, key SigningKey) (uint32, error) {
+	if err := ws.checkWalletEnabled(walletObj); err != nil {
+		return 0, err
+	}
+
+	coinType := key.CoinType
+	if coinType == DefaultCoinType {
+		coinType = walletObj.CoinType
+	}
+
+	if !walletObj.HasCoinType(coinType) {
+		ws.logger.Warn("coin type not enabled for wallet", "name", sanitizeName(name), "coin_type", coinType)
+		return 0, fmt.Errorf("%w: %d", ErrCoinNotEnabled, coinType)
+	}
+
+	if err := ws.checkCoinsEnabled(ctx, coinType); err != nil {
+		ws.logger.Warn("coin type disabled on mount", "name", sanitizeName(name), "coin_type", coinType)
+		return 0, err
+	}
+
+	return coinType, nil
+}
+
+// deriveSigningKey derives the selected key of a coin type from a wallet's seed
+// and replaces the wallet's key, public key and address with it
+func (ws *WalletService) deriveSigningKey(name string, walletObj *storage.Wallet, key SigningKey, coinType uint32) error {
+	passphrase, err := ws.seedPassphrase(name, walletObj, key.Passphrase)
+	if err != nil {
+		return err
+	}
+
+	addressType := keyAddressType(walletObj, coinType)
+
+	derivationPath := key.DerivationPath
+	if derivationPath == "" {
+		derivationPath, err = ws.keyPath(coinType, addressType, key.Index)
+		if err != nil {
+			if errors.Is(err, wallet.ErrInvalidDerivationPath) {
+				ws.logger.Warn("invalid key index", "name", sanitizeName(name), "error", err)
+				return fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
+			}
+			ws.logger.Error("failed to build derivation path", "name", sanitizeName(name), "coin_type", coinType, "error", err)
+			return fmt.Errorf("failed to build derivation path: %w", err)
+		}
+	}
+
+	// Derive the signing key from the seed
+	keys, err := ws.deriveKey(walletObj.Mnemonic, passphrase, coinType, derivationPath, addressType)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidCoinType) {
+			ws.logger.Warn("invalid coin type for signing", "name", sanitizeName(name), "coin_type", coinType)
+			return ErrInvalidCoinType
+		}
+		if derivationPath != "" && errors.Is(err, wallet.ErrAddressDerivation) {
+			ws.logger.Warn("failed to derive key at path", "name", sanitizeName(name), "path", derivationPath)
+			return fmt.Errorf("%w: %s", ErrInvalidDerivationPath, derivationPath)
+		}
+		ws.logger.Error("failed to derive signing key", "name", sanitizeName(name), "coin_type", coinType, "has_custom_path", derivationPath != "", "error", sanitizeError(err))
+		return fmt.Errorf("failed to derive signing key: %w", err)
+	}
+
+	// Wallets created before keys were derived on demand also carry a stored key
+	for i := range walletObj.PrivateKey {
+		walletObj.PrivateKey[i] = 0
+	}
+
+	walletObj.CoinType = coinType
+	walletObj.AddressType = addressType
+	walletObj.PrivateKey = keys.PrivateKey
+	walletObj.PublicKey = wallet.GetPublicKeyHex(keys.PublicKey)
+	walletObj.Address = keys.Address
+	walletObj.Curve = keys.Curve
+
+	return nil
+}
+
+// keyAddressType returns the Bitcoin address type of a wallet's keys for a coin type
+// The wallet's address type applies to its creation coin; other coins use their default
+func keyAddressType(walletObj *storage.Wallet, coinType uint32) string {
+	if coinType == walletObj.CoinType {
+		return walletObj.AddressType
+	}
+	return wallet.DefaultAddressType(coinType)
+}
+
+// keyPath returns the derivation path of a key selected by an optional index
+// Without an address type the coin's default purpose is used, and an empty path selects
+// the coin's default key; with one, the purpose paired with the address type is used
+func (ws *WalletService) keyPath(coinType uint32, addressType string, index *KeyIndex) (string, error) {
+	if addressType == "" {
+		if index == nil {
+			return "", nil
+		}
+		return wallet.BIP44Path(coinType, index.Account, index.Change, index.AddressIndex)
+	}
+
+	purpose, err := wallet.AddressTypePurpose(coinType, addressType)
+	if err != nil {
+		return "", err
+	}
+	if index == nil {
+		index = &KeyIndex{}
+	}
+
+	return wallet.PurposePath(coinType, purpose, index.Account, index.Change, index.AddressIndex)
+}
+
+// deriveKey derives a key from the seed and encodes its address in the given address type
+func (ws *WalletService) deriveKey(mnemonic string, passphrase string, coinType uint32, derivationPath string, addressType string) (*wallet.WalletKeys, error) {
+	keys, err := ws.engine.DeriveKey(mnemonic, passphrase, coinType, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if addressType != "" {
+		keys.Address, err = ws.engine.AddressForType(keys.PublicKey, coinType, addressType)
+		if err != nil {
+			for i := range keys.PrivateKey {
+				keys.PrivateKey[i] = 0
+			}
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// seedPassphrase returns the BIP39 passphrase to derive a wallet's keys with
+// Wallets that do not store their passphrase need it supplied; it is checked by
+// re-deriving the creation key and comparing it with the stored public key
+func (ws *WalletService) seedPassphrase(name string, walletObj *storage.Wallet, supplied string) (string, error) {
+	if !walletObj.PassphraseRequired {
+		return walletObj.Passphrase, nil
+	}
+
+	if supplied == "" {
+		ws.logger.Warn("passphrase not supplied for wallet that requires one", "name", sanitizeName(name))
+		return "", ErrPassphraseRequired
+	}
+
+	path, err := ws.keyPath(walletObj.CoinType, walletObj.AddressType, nil)
+	if err != nil {
+		ws.logger.Error("failed to build default key path", "name", sanitizeName(name), "error", err)
+		return "", fmt.Errorf("failed to check passphrase: %w", err)
+	}
+
+	keys, err := ws.engine.DeriveKey(walletObj.Mnemonic, supplied, walletObj.CoinType, path)
+	if err != nil {
+		ws.logger.Error("failed to derive key to check passphrase", "name", sanitizeName(name), "error", sanitizeError(err))
+		return "", fmt.Errorf("failed to check passphrase: %w", err)
+	}
+	for i := range keys.PrivateKey {
+		keys.PrivateKey[i] = 0
+	}
+
+	if wallet.GetPublicKeyHex(keys.PublicKey) != walletObj.PublicKey {
+		ws.logger.Warn("supplied passphrase does not match wallet", "name", sanitizeName(name))
+		return "", ErrInvalidPassphrase
+	}
+
+	return supplied, nil
+}
+
+// GetAddress derives an address for a specific coin type and optional derivation path
+// addressType selects a Bitcoin address type; empty uses the wallet's type for its creation coin
+// passphrase is only used for wallets that do not store their BIP39 passphrase
+// The address is recorded in the wallet's address registry with the optional label and metadata
+func (ws *WalletService) GetAddress(ctx context.Context, name string, coinType uint32, derivationPath string, addressType string, passphrase string, label string, metadata map[string]string) (*storage.AddressRecord, error) {
+	if name == "" {
+		ws.logger.Warn("attempted to get address with empty wallet name")
+		return nil, ErrInvalidWalletName
+	}
+
+	ws.logger.Debug("deriving address", "name", sanitizeName(name), "coin_type", coinType, "has_custom_path", derivationPath != "")
+
+	// Retrieve wallet with decrypted mnemonic
+	walletObj, err := ws.storage.GetWallet(ctx, name)
+	if err != nil {
+		if errors.Is(err, storage.ErrWalletNotFound) {
+			ws.logger.Warn("wallet not found for address derivation", "name", sanitizeName(name))
+			return nil, ErrWalletNotFound
+		}
+		ws.logger.Error("failed to retrieve wallet for address derivation", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+
+	// Ensure mnemonic is cleared from memory after use
+	defer func() {
+		// Clear mnemonic and passphrase from memory
+		walletObj.Mnemonic = ""
+		walletObj.Passphrase = ""
+		// Clear private key from memory
+		for i := range walletObj.PrivateKey {
+			walletObj.PrivateKey[i] = 0
+		}
+		// Force garbage collection to clear memory
+		runtime.GC()
+		ws.logger.Debug("sensitive data cleared from memory", "name", sanitizeName(name))
+	}()
+
+	if err := ws.checkWalletEnabled(walletObj); err != nil {
+		return nil, err
+	}
+
+	if !walletObj.HasCoinType(coinType) {
+		ws.logger.Warn("coin type not enabled for wallet", "name", sanitizeName(name), "coin_type", coinType)
+		return nil, fmt.Errorf("%w: %d", ErrCoinNotEnabled, coinType)
+	}
+
+	if err := ws.checkCoinsEnabled(ctx, coinType); err != nil {
+		ws.logger.Warn("coin type disabled on mount", "name", sanitizeName(name), "coin_type", coinType)
+		return nil, err
+	}
+
+	passphrase, err = ws.seedPassphrase(name, walletObj, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if addressType == "" {
+		addressType = keyAddressType(walletObj, coinType)
+	}
+
+	if derivationPath == "" {
+		derivationPath, err = ws.keyPath(coinType, addressType, nil)
+		if err != nil {
+			if errors.Is(err, wallet.ErrInvalidAddressType) {
+				ws.logger.Warn("invalid address type", "name", sanitizeName(name), "coin_type", coinType, "address_type", addressType)
+				return nil, fmt.Errorf("%w: %v", ErrInvalidAddressType, err)
+			}
+			ws.logger.Error("failed to build derivation path", "name", sanitizeName(name), "coin_type", coinType, "error", err)
+			return nil, fmt.Errorf("failed to build derivation path: %w", err)
+		}
+	}
+
+	// Derive address
+	keys, err := ws.deriveKey(walletObj.Mnemonic, passphrase, coinType, derivationPath, addressType)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidAddressType) {
+			ws.logger.Warn("invalid address type", "name", sanitizeName(name), "coin_type", coinType, "address_type", addressType)
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddressType, err)
+		}
+		if errors.Is(err, wallet.ErrInvalidCoinType) {
+			ws.logger.Warn("invalid coin type for address derivation", "name", sanitizeName(name), "coin_type", coinType)
+			return nil, ErrInvalidCoinType
+		}
+		if errors.Is(err, wallet.ErrAddressDerivation) {
+			ws.logger.Error("address derivation failed", "name", sanitizeName(name), "error", sanitizeError(err))
+			return nil, fmt.Errorf("address derivation failed: %w", err)
+		}
+		ws.logger.Error("failed to derive address", "name", sanitizeName(name), "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to derive address: %w", err)
+	}
+
+	for i := range keys.PrivateKey {
+		keys.PrivateKey[i] = 0
+	}
+
+	if derivationPath == "" {
+		derivationPath, err = wallet.DefaultDerivationPath(coinType)
+		if err != nil {
+			ws.logger.Error("failed to resolve default derivation path", "coin_type", coinType, "error", err)
+			return nil, fmt.Errorf("failed to resolve default derivation path: %w", err)
+		}
+	}
+
+	record := &storage.AddressRecord{
+		Wallet:         name,
+		Address:        keys.Address,
+		CoinType:       coinType,
+		DerivationPath: derivationPath,
+		Label:          label,
+		Metadata:       metadata,
+	}
+	if err := ws.storage.RecordAddresses(ctx, []*storage.AddressRecord{record}); err != nil {
+		ws.logger.Error("failed to record address", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to record address: %w", err)
+	}
+
+	ws.logger.Debug("address derived successfully", "name", sanitizeName(name), "coin_type", coinType, "registry_index", record.Index)
+
+	return record, nil
+}
+
+// LookupAddress returns the registry records of the wallets that handed out an address
+func (ws *WalletService) LookupAddress(ctx context.Context, address string) ([]*storage.AddressRecord, error) {
+	if address == "" {
+		return nil, ErrAddressNotFound
+	}
+
+	records, err := ws.storage.LookupAddress(ctx, address)
+	if err != nil {
+		if errors.Is(err, storage.ErrAddressNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		ws.logger.Error("failed to look up address", "error", err)
+		return nil, fmt.Errorf("failed to look up address: %w", err)
+	}
+
+	return records, nil
+}
+
+// ListAddresses returns the registry indexes of the addresses a wallet has handed out
+func (ws *WalletService) ListAddresses(ctx context.Context, name string) ([]string, error) {
+	if _, err := ws.storage.GetWalletMetadata(ctx, name); err != nil {
+		if errors.Is(err, storage.ErrWalletNotFound) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+
+	indexes, err := ws.storage.ListAddressRecords(ctx, name)
+	if err != nil {
+		ws.logger.Error("failed to list address records", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// MaxAddressBatch is the largest number of addresses derived in one request
+const MaxAddressBatch = 1000
+
+// GapLimit is the BIP44 address gap limit, used as the default batch size
+const GapLimit = 20
+
+// ErrInvalidBatch is returned when an address batch is empty, too large or out of range
+var ErrInvalidBatch = errors.New("invalid address batch")
+
+// AddressBatch selects a run of addresses on one account/change chain of a wallet
+type AddressBatch struct {
+	// CoinType is the chain to use; DefaultCoinType selects the wallet's creation coin
+	CoinType uint32
+	// AddressType selects a Bitcoin address type; empty uses the wallet's type
+	AddressType string
+	Account     uint32
+	Change      uint32
+	// StartIndex is the first address index considered
+	StartIndex uint32
+	// Count is the number of addresses returned, at most MaxAddressBatch
+	Count int
+	// SkipUsed selects gap-limit mode: indices in UsedIndices are skipped, so the
+	// batch holds the first Count unused indices from StartIndex
+	SkipUsed    bool
+	UsedIndices []uint32
+	// Passphrase is the BIP39 passphrase of wallets that do not store theirs
+	Passphrase string
+}
+
+// BatchAddress is an address derived as part of a batch
+type BatchAddress struct {
+	Index          uint32
+	CoinType       uint32
+	Address        string
+	PublicKey      string
+	DerivationPath string
+	// RegistryIndex is the address's position in the wallet's address registry
+	RegistryIndex uint64
+}
+
+// indices returns the address indices selected by the batch
+func (b AddressBatch) indices() ([]uint32, error) {
+	if b.Count < 1 || b.Count > MaxAddressBatch {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidBatch, MaxAddressBatch)
+	}
+
+	used := make(map[uint32]bool, len(b.UsedIndices))
+	if b.SkipUsed {
+		for _, index := range b.UsedIndices {
+			used[index] = true
+		}
+	}
+
+	indices := make([]uint32, 0, b.Count)
+	for index := uint64(b.StartIndex); len(indices) < b.Count; index++ {
+		if index > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: address indices exceed %d", ErrInvalidBatch, math.MaxInt32)
+		}
+		if used[uint32(index)] {
+			continue
+		}
+		indices = append(indices, uint32(index))
+	}
+
+	return indices, nil
+}
+
+// DeriveAddresses derives a batch of addresses of a wallet in a single decrypt cycle
+// Every address is recorded in the wallet's address registry
+func (ws *WalletService) DeriveAddresses(ctx context.Context, name string, batch AddressBatch) ([]BatchAddress, error) {
+	if name == "" {
+		ws.logger.Warn("attempted to derive addresses with empty wallet name")
+		return nil, ErrInvalidWalletName
+	}
+
+	indices, err := batch.indices()
+	if err != nil {
+		return nil, err
+	}
+
+	ws.logger.Debug("deriving address batch", "name", sanitizeName(name), "coin_type", batch.CoinType, "start_index", batch.StartIndex, "count", batch.Count, "skip_used", batch.SkipUsed)
+
+	// Retrieve wallet with decrypted mnemonic
+	walletObj, err := ws.storage.GetWallet(ctx, name)
+	if err != nil {
+		if errors.Is(err, storage.ErrWalletNotFound) {
+			ws.logger.Warn("wallet not found for address derivation", "name", sanitizeName(name))
+			return nil, ErrWalletNotFound
+		}
+		ws.logger.Error("failed to retrieve wallet for address derivation", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+
+	// Ensure mnemonic is cleared from memory once the whole batch is derived
+	defer func() {
+		walletObj.Mnemonic = ""
+		walletObj.Passphrase = ""
+		for i := range walletObj.PrivateKey {
+			walletObj.PrivateKey[i] = 0
+		}
+		runtime.GC()
+		ws.logger.Debug("sensitive data cleared from memory", "name", sanitizeName(name))
+	}()
+
+	if err := ws.checkWalletEnabled(walletObj); err != nil {
+		return nil, err
+	}
+
+	coinType := batch.CoinType
+	if coinType == DefaultCoinType {
+		coinType = walletObj.CoinType
+	}
+
+	if !walletObj.HasCoinType(coinType) {
+		ws.logger.Warn("coin type not enabled for wallet", "name", sanitizeName(name), "coin_type", coinType)
+		return nil, fmt.Errorf("%w: %d", ErrCoinNotEnabled, coinType)
+	}
+
+	if err := ws.checkCoinsEnabled(ctx, coinType); err != nil {
+		ws.logger.Warn("coin type disabled on mount", "name", sanitizeName(name), "coin_type", coinType)
+		return nil, err
+	}
+
+	passphrase, err := ws.seedPassphrase(name, walletObj, batch.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	addressType := batch.AddressType
+	if addressType == "" {
+		addressType = keyAddressType(walletObj, coinType)
+	}
+
+	paths := make([]string, 0, len(indices))
+	for _, index := range indices {
+		path, err := ws.keyPath(coinType, addressType, &KeyIndex{Account: batch.Account, Change: batch.Change, AddressIndex: index})
+		if err != nil {
+			if errors.Is(err, wallet.ErrInvalidAddressType) {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidAddressType, err)
+			}
+			if errors.Is(err, wallet.ErrInvalidDerivationPath) {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
+			}
+			ws.logger.Error("failed to build derivation path", "name", sanitizeName(name), "coin_type", coinType, "error", err)
+			return nil, fmt.Errorf("failed to build derivation path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+
+	derived, err := ws.engine.DeriveAddresses(walletObj.Mnemonic, passphrase, coinType, addressType, paths)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidAddressType) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddressType, err)
+		}
+		if errors.Is(err, wallet.ErrInvalidCoinType) {
+			ws.logger.Warn("invalid coin type for address derivation", "name", sanitizeName(name), "coin_type", coinType)
+			return nil, ErrInvalidCoinType
+		}
+		ws.logger.Error("failed to derive address batch", "name", sanitizeName(name), "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to derive addresses: %w", err)
+	}
+
+	// Record the batch in the wallet's address registry
+	records := make([]*storage.AddressRecord, 0, len(derived))
+	for _, d := range derived {
+		records = append(records, &storage.AddressRecord{
+			Wallet:         name,
+			Address:        d.Address,
+			CoinType:       coinType,
+			DerivationPath: d.Path,
+		})
+	}
+	if err := ws.storage.RecordAddresses(ctx, records); err != nil {
+		ws.logger.Error("failed to record address batch", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to record addresses: %w", err)
+	}
+
+	addresses := make([]BatchAddress, 0, len(derived))
+	for i, d := range derived {
+		addresses = append(addresses, BatchAddress{
+			Index:          indices[i],
+			CoinType:       coinType,
+			Address:        d.Address,
+			PublicKey:      wallet.GetPublicKeyHex(d.PublicKey),
+			DerivationPath: d.Path,
+			RegistryIndex:  records[i].Index,
+		})
+	}
+
+	ws.logger.Debug("address batch derived", "name", sanitizeName(name), "coin_type", coinType, "count", len(addresses))
+
+	return addresses, nil
+}
+
+// ExtendedKey is an account-level extended public key of a wallet
+type ExtendedKey struct {
+	Xpub           string
+	CoinType       uint32
+	Purpose        uint32
+	Account        uint32
+	DerivationPath string
+}
+
+// GetExtendedPublicKey exports the account-level extended public key of a wallet
+// coinType may be DefaultCoinType and purpose 0 to use the wallet's creation coin and
+// the coin's default purpose; passphrase is only used for wallets that do not store theirs
+func (ws *WalletService) GetExtendedPublicKey(ctx context.Context, name string, coinType uint32, purpose uint32, account uint32, passphrase string) (*ExtendedKey, error) {
+	if name == "" {
+		ws.logger.Warn("attempted to export extended public key with empty wallet name")
+		return nil, ErrInvalidWalletName
+	}
+
+	// Retrieve wallet with decrypted mnemonic
+	walletObj, err := ws.storage.GetWallet(ctx, name)
+	if err != nil {
+		if errors.Is(err, storage.ErrWalletNotFound) {
+			ws.logger.Warn("wallet not found for extended public key export", "name", sanitizeName(name))
+			return nil, ErrWalletNotFound
+		}
+		ws.logger.Error("failed to retrieve wallet for extended public key export", "name", sanitizeName(name), "error", err)
+		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+
+	// Ensure mnemonic is cleared from memory after use
+	defer func() {
+		walletObj.Mnemonic = ""
+		walletObj.Passphrase = ""
+		for i := range walletObj.PrivateKey {
+			walletObj.PrivateKey[i] = 0
+		}
+		runtime.GC()
+		ws.logger.Debug("sensitive data cleared from memory", "name", sanitizeName(name))
+	}()
+
+	if err := ws.checkWalletEnabled(walletObj); err != nil {
+		return nil, err
+	}
+
+	if coinType == DefaultCoinType {
+		coinType = walletObj.CoinType
+	}
+
+	if !walletObj.HasCoinType(coinType) {
+		ws.logger.Warn("coin type not enabled for wallet", "name", sanitizeName(name), "coin_type", coinType)
+		return nil, fmt.Errorf("%w: %d", ErrCoinNotEnabled, coinType)
+	}
+
+	if err := ws.checkCoinsEnabled(ctx, coinType); err != nil {
+		ws.logger.Warn("coin type disabled on mount", "name", sanitizeName(name), "coin_type", coinType)
+		return nil, err
+	}
+
+	if addressType := keyAddressType(walletObj, coinType); purpose == 0 && addressType != "" {
+		purpose, err = wallet.AddressTypePurpose(coinType, addressType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddressType, err)
+		}
+	}
+
+	if purpose == 0 {
+		purpose, err = wallet.DefaultPurpose(coinType)
+		if err != nil {
+			if errors.Is(err, wallet.ErrInvalidCoinType) {
+				return nil, ErrInvalidCoinType
+			}
+			ws.logger.Error("failed to resolve default purpose", "coin_type", coinType, "error", err)
+			return nil, fmt.Errorf("failed to resolve default purpose: %w", err)
+		}
+	}
+
+	passphrase, err = ws.seedPassphrase(name, walletObj, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	xpub, err := ws.engine.ExtendedPublicKey(walletObj.Mnemonic, passphrase, coinType, purpose, account)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidCoinType) {
+			ws.logger.Warn("invalid coin type for extended public key", "name", sanitizeName(name), "coin_type", coinType)
+			return nil, ErrInvalidCoinType
+		}
+		if errors.Is(err, wallet.ErrInvalidDerivationPath) {
+			ws.logger.Warn("invalid extended public key path", "name", sanitizeName(name), "purpose", purpose, "account", account)
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
+		}
+		if errors.Is(err, wallet.ErrInvalidExtendedKey) {
+			ws.logger.Warn("extended public key not available", "name", sanitizeName(name), "coin_type", coinType, "error", sanitizeError(err))
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+		}
+		ws.logger.Error("failed to export extended public key", "name", sanitizeName(name), "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to export extended public key: %w", err)
+	}
+
+	ws.logger.Debug("extended public key exported", "name", sanitizeName(name), "coin_type", coinType, "purpose", purpose, "account", account)
+
+	return &ExtendedKey{
+		Xpub:           xpub,
+		CoinType:       coinType,
+		Purpose:        purpose,
+		Account:        account,
+		DerivationPath: fmt.Sprintf("m/%d'/%d'/%d'", purpose, coinType, account),
+	}, nil
+}
+
+// DeriveFromExtendedKey derives the public key and address at change/addressIndex below
+// an account-level extended public key; no wallet or secret material is involved
+// An empty Bitcoin address type is inferred from the key's prefix
+func (ws *WalletService) DeriveFromExtendedKey(ctx context.Context, xpub string, coinType uint32, addressType string, change, addressIndex uint32) (*wallet.WalletKeys, error) {
+	if err := ws.checkCoinsEnabled(ctx, coinType); err != nil {
+		ws.logger.Warn("coin type disabled on mount", "coin_type", coinType)
+		return nil, err
+	}
+
+	keys, err := ws.engine.DeriveFromExtendedKey(xpub, coinType, addressType, change, addressIndex)
+	if err != nil {
+		if errors.Is(err, wallet.ErrInvalidAddressType) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddressType, err)
+		}
+		if errors.Is(err, wallet.ErrInvalidCoinType) {
+			ws.logger.Warn("invalid coin type for extended key derivation", "coin_type", coinType)
+			return nil, ErrInvalidCoinType
+		}
+		if errors.Is(err, wallet.ErrInvalidDerivationPath) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
+		}
+		if errors.Is(err, wallet.ErrInvalidExtendedKey) {
+			ws.logger.Warn("invalid extended public key", "coin_type", coinType, "error", sanitizeError(err))
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+		}
+		ws.logger.Error("failed to derive from extended public key", "coin_type", coinType, "error", sanitizeError(err))
+		return nil, fmt.Errorf("failed to derive from extended public key: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Limits of a wallet's mutable metadata
+const (
+	maxDescriptionLength = 1024
+	maxOwnerLength       = 256
+	maxEntries           = 64
+	maxEntryKeyLength    = 128
+	maxEntryValueLength  = 512
+	maxLabels            = 64
+	maxLabelLength       = 128
+)
+
+// validateWalletMetadata checks a wallet's description, owner, tags, metadata and labels against their limits
+func validateWalletMetadata(description, owner string, tags, metadata map[string]string, labels []string) error {
+	if len(description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description exceeds %d characters", ErrInvalidMetadata, maxDescriptionLength)
+	}
+	if len(owner) > maxOwnerLength {
+		return fmt.Errorf("%w: owner exceeds %d characters", ErrInvalidMetadata, maxOwnerLength)
+	}
+
+	for field, entries := range map[string]map[string]string{"tags": tags, "metadata": metadata} {
+		if len(entries) > maxEntries {
+			return fmt.Errorf("%w: %s has more than %d entries", ErrInvalidMetadata, field, maxEntries)
+		}
+		for key, value := range entries {
+			if key == "" || len(key) > maxEntryKeyLength {
+				return fmt.Errorf("%w: %s keys must be 1 to %d characters", ErrInvalidMetadata, field, maxEntryKeyLength)
+			}
+			if len(value) > maxEntryValueLength {
+				return fmt.Errorf("%w: %s value of %q exceeds %d characters", ErrInvalidMetadata, field, key, maxEntryValueLength)
+			}
+		}
+	}
+
+	if len(labels) > maxLabels {
+		return fmt.Errorf("%w: more than %d labels", ErrInvalidMetadata, maxLabels)
+	}
+	for _, label := range labels {
+		if len(label) > maxLabelLength {
+			return fmt.Errorf("%w: label %q exceeds %d characters", ErrInvalidMetadata, label, maxLabelLength)
+		}
+	}
+
+	return nil
+}
+
+// mergeEntries merges changes into a copy of entries; a change with an empty value removes the key
+// The result is nil when no entries remain
+func mergeEntries(entries, changes map[string]string) map[string]string {
+	merged := make(map[string]string, len(entries)+len(changes))
+	for key, value := range entries {
+		merged[key] = value
+	}
+	for key, value := range changes {
+		merged[key] = value
+	}
+	return nonEmptyEntries(merged)
+}
+
+// normalizeLabels sorts labels and drops empty and duplicate ones; the result is nil when none remain
+func normalizeLabels(labels []string) []string {
+	var normalized []string
+	for _, label := range labels {
+		if label != "" {
+			normalized = append(normalized, label)
+		}
+	}
+	sort.Strings(normalized)
+
+	unique := normalized[:0]
+	for i, label := range normalized {
+		if i == 0 || label != normalized[i-1] {
+			unique = append(unique, label)
+		}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+	return unique
+}
+
+// nonEmptyEntries drops entries with an empty value; the result is nil when none remain
+func nonEmptyEntries(entries map[string]string) map[string]string {
+	var kept map[string]string
+	for key, value := range entries {
+		if value == "" {
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]string, len(entries))
+		}
+		kept[key] = value
+	}
+	return kept
 }
 
 // sanitizeName sanitizes wallet name for logging (prevents logging sensitive data)
@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// addressIndexPrefix is the storage prefix of the reverse index from address to wallet
+// Each owner of an address has an entry at index/address/<address>/<wallet>
+const addressIndexPrefix = "index/address/"
+
+// ErrAddressNotFound is returned when an address has not been handed out by any wallet
+var ErrAddressNotFound = errors.New("address not found")
+
+// AddressRecord is an address handed out by a wallet, stored under wallets/<name>/addresses/<index>
+// Index is the position in the wallet's registry, assigned in the order addresses are handed out
+type AddressRecord struct {
+	Index          uint64            `json:"index"`
+	Wallet         string            `json:"wallet"`
+	Address        string            `json:"address"`
+	CoinType       uint32            `json:"coin_type"`
+	DerivationPath string            `json:"derivation_path"`
+	Label          string            `json:"label,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// addressIndexEntry points from an address to a wallet's record of it
+type addressIndexEntry struct {
+	Index uint64 `json:"index"`
+}
+
+// addressCounter holds the next registry index of a wallet
+type addressCounter struct {
+	Next uint64 `json:"next"`
+}
+
+// addressRecordPath returns the storage path of a wallet's address record
+func addressRecordPath(walletName string, index uint64) string {
+	return "wallets/" + walletName + "/addresses/" + strconv.FormatUint(index, 10)
+}
+
+// addressCounterPath returns the storage path of a wallet's next registry index
+func addressCounterPath(walletName string) string {
+	return "wallets/" + walletName + "/address_counter"
+}
+
+// addressIndexFolder returns the reverse index folder of an address
+// Hex addresses are case-insensitive, so their EIP-55 checksum casing is dropped
+func addressIndexFolder(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		address = strings.ToLower(address)
+	}
+	return addressIndexPrefix + address + "/"
+}
+
+// RecordAddresses registers addresses handed out by a wallet
+// Addresses already registered for the wallet keep their index; a non-empty label or
+// metadata replaces the stored one. Each record is updated in place with its stored state
+func (ss *StorageService) RecordAddresses(ctx context.Context, records []*AddressRecord) error {
+	ss.registryLock.Lock()
+	defer ss.registryLock.Unlock()
+
+	counters := make(map[string]*addressCounter)
+
+	err := ss.recordAddresses(ctx, records, counters)
+
+	// Persist the counters even after a failure, so indexes already used are not reissued
+	for walletName, counter := range counters {
+		entry, putErr := logical.StorageEntryJSON(addressCounterPath(walletName), counter)
+		if putErr == nil {
+			putErr = ss.storage.Put(ctx, entry)
+		}
+		if putErr != nil && err == nil {
+			err = fmt.Errorf("failed to store address counter: %w", putErr)
+		}
+	}
+
+	return err
+}
+
+// recordAddresses stores address records, taking new indexes from the wallets' counters
+func (ss *StorageService) recordAddresses(ctx context.Context, records []*AddressRecord, counters map[string]*addressCounter) error {
+	for _, record := range records {
+		existing, err := ss.walletAddressRecord(ctx, record.Wallet, record.Address)
+		if err != nil && !errors.Is(err, ErrAddressNotFound) {
+			return err
+		}
+
+		if existing != nil {
+			if record.Label == "" && len(record.Metadata) == 0 {
+				*record = *existing
+				continue
+			}
+			if record.Label != "" {
+				existing.Label = record.Label
+			}
+			if len(record.Metadata) > 0 {
+				existing.Metadata = record.Metadata
+			}
+			if err := ss.putAddressRecord(ctx, existing); err != nil {
+				return err
+			}
+			*record = *existing
+			continue
+		}
+
+		counter, ok := counters[record.Wallet]
+		if !ok {
+			counter, err = ss.getAddressCounter(ctx, record.Wallet)
+			if err != nil {
+				return err
+			}
+			counters[record.Wallet] = counter
+		}
+
+		record.Index = counter.Next
+		record.CreatedAt = time.Now().UTC()
+		counter.Next++
+
+		if err := ss.putAddressRecord(ctx, record); err != nil {
+			return err
+		}
+
+		entry, err := logical.StorageEntryJSON(addressIndexFolder(record.Address)+record.Wallet, &addressIndexEntry{Index: record.Index})
+		if err != nil {
+			return fmt.Errorf("failed to create address index entry: %w", err)
+		}
+		if err := ss.storage.Put(ctx, entry); err != nil {
+			return fmt.Errorf("failed to store address index entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// LookupAddress returns the records of the wallets that handed out an address, sorted by wallet name
+// Wallets sharing a seed may each own the same address
+func (ss *StorageService) LookupAddress(ctx context.Context, address string) ([]*AddressRecord, error) {
+	if address == "" {
+		return nil, errors.New("address cannot be empty")
+	}
+
+	owners, err := ss.storage.List(ctx, addressIndexFolder(address))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address index: %w", err)
+	}
+	sort.Strings(owners)
+
+	records := make([]*AddressRecord, 0, len(owners))
+	for _, owner := range owners {
+		record, err := ss.walletAddressRecord(ctx, owner, address)
+		if err != nil {
+			if errors.Is(err, ErrAddressNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return nil, ErrAddressNotFound
+	}
+
+	return records, nil
+}
+
+// walletAddressRecord resolves a wallet's record of an address through the reverse index
+func (ss *StorageService) walletAddressRecord(ctx context.Context, walletName string, address string) (*AddressRecord, error) {
+	entry, err := ss.storage.Get(ctx, addressIndexFolder(address)+walletName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address index: %w", err)
+	}
+	if entry == nil {
+		return nil, ErrAddressNotFound
+	}
+
+	var index addressIndexEntry
+	if err := json.Unmarshal(entry.Value, &index); err != nil {
+		return nil, fmt.Errorf("failed to decode address index entry: %w", err)
+	}
+
+	return ss.GetAddressRecord(ctx, walletName, index.Index)
+}
+
+// GetAddressRecord returns the address record at a wallet's registry index
+func (ss *StorageService) GetAddressRecord(ctx context.Context, walletName string, index uint64) (*AddressRecord, error) {
+	entry, err := ss.storage.Get(ctx, addressRecordPath(walletName, index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address record: %w", err)
+	}
+	if entry == nil {
+		return nil, ErrAddressNotFound
+	}
+
+	var record AddressRecord
+	if err := json.Unmarshal(entry.Value, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode address record: %w", err)
+	}
+
+	return &record, nil
+}
+
+// ListAddressRecords returns the registry indexes of a wallet's addresses
+func (ss *StorageService) ListAddressRecords(ctx context.Context, walletName string) ([]string, error) {
+	keys, err := ss.storage.List(ctx, "wallets/"+walletName+"/addresses/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address records: %w", err)
+	}
+	return keys, nil
+}
+
+// deleteAddressRecords removes a wallet's address records and its reverse index entries
+func (ss *StorageService) deleteAddressRecords(ctx context.Context, walletName string) error {
+	ss.registryLock.Lock()
+	defer ss.registryLock.Unlock()
+
+	keys, err := ss.ListAddressRecords(ctx, walletName)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		index, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		record, err := ss.GetAddressRecord(ctx, walletName, index)
+		if err != nil && !errors.Is(err, ErrAddressNotFound) {
+			return err
+		}
+
+		if record != nil {
+			if err := ss.storage.Delete(ctx, addressIndexFolder(record.Address)+walletName); err != nil {
+				return fmt.Errorf("failed to delete address index entry: %w", err)
+			}
+		}
+
+		if err := ss.storage.Delete(ctx, addressRecordPath(walletName, index)); err != nil {
+			return fmt.Errorf("failed to delete address record: %w", err)
+		}
+	}
+
+	if err := ss.storage.Delete(ctx, addressCounterPath(walletName)); err != nil {
+		return fmt.Errorf("failed to delete address counter: %w", err)
+	}
+
+	return nil
+}
+
+// putAddressRecord stores an address record
+func (ss *StorageService) putAddressRecord(ctx context.Context, record *AddressRecord) error {
+	entry, err := logical.StorageEntryJSON(addressRecordPath(record.Wallet, record.Index), record)
+	if err != nil {
+		return fmt.Errorf("failed to create address record entry: %w", err)
+	}
+	if err := ss.storage.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to store address record: %w", err)
+	}
+	return nil
+}
+
+// getAddressCounter returns the next registry index of a wallet
+func (ss *StorageService) getAddressCounter(ctx context.Context, walletName string) (*addressCounter, error) {
+	entry, err := ss.storage.Get(ctx, addressCounterPath(walletName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address counter: %w", err)
+	}
+
+	counter := &addressCounter{}
+	if entry == nil {
+		return counter, nil
+	}
+	if err := json.Unmarshal(entry.Value, counter); err != nil {
+		return nil, fmt.Errorf("failed to decode address counter: %w", err)
+	}
+
+	return counter, nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestAddressRegistry(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	ss := NewStorageService(store, hclog.NewNullLogger())
+
+	for _, name := range []string{"deposits", "twin"} {
+		if err := ss.StoreWallet(ctx, testWallet(name)); err != nil {
+			t.Fatalf("StoreWallet() error = %v", err)
+		}
+	}
+
+	records := []*AddressRecord{
+		{Wallet: "deposits", Address: "0xAbC0000000000000000000000000000000000001", CoinType: 60, DerivationPath: "m/44'/60'/0'/0/0"},
+		{Wallet: "deposits", Address: "0xabc0000000000000000000000000000000000002", CoinType: 60, DerivationPath: "m/44'/60'/0'/0/1", Label: "customer-7"},
+	}
+	if err := ss.RecordAddresses(ctx, records); err != nil {
+		t.Fatalf("RecordAddresses() error = %v", err)
+	}
+	if records[0].Index != 0 || records[1].Index != 1 {
+		t.Fatalf("indexes = %d, %d, want 0, 1", records[0].Index, records[1].Index)
+	}
+
+	// Handing out an address again keeps its index and only replaces the supplied fields
+	again := &AddressRecord{Wallet: "deposits", Address: records[1].Address, CoinType: 60, Metadata: map[string]string{"ref": "42"}}
+	if err := ss.RecordAddresses(ctx, []*AddressRecord{again}); err != nil {
+		t.Fatalf("RecordAddresses() error = %v", err)
+	}
+	if again.Index != 1 || again.Label != "customer-7" || again.Metadata["ref"] != "42" {
+		t.Fatalf("re-recorded address = %+v", again)
+	}
+
+	// Hex addresses are found regardless of checksum casing
+	found, err := ss.LookupAddress(ctx, "0xabc0000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("LookupAddress() error = %v", err)
+	}
+	if len(found) != 1 || found[0].Wallet != "deposits" || found[0].DerivationPath != "m/44'/60'/0'/0/0" {
+		t.Fatalf("LookupAddress() = %+v", found)
+	}
+
+	// A wallet sharing the seed owns the address too
+	twin := &AddressRecord{Wallet: "twin", Address: records[0].Address, CoinType: 60, DerivationPath: "m/44'/60'/0'/0/0"}
+	if err := ss.RecordAddresses(ctx, []*AddressRecord{twin}); err != nil {
+		t.Fatalf("RecordAddresses() error = %v", err)
+	}
+	if twin.Index != 0 {
+		t.Fatalf("twin index = %d, want 0", twin.Index)
+	}
+	found, err = ss.LookupAddress(ctx, records[0].Address)
+	if err != nil || len(found) != 2 {
+		t.Fatalf("LookupAddress() = %+v, err = %v, want two owners", found, err)
+	}
+
+	// The registry folders are not listed as wallets
+	names, err := ss.ListWallets(ctx, 0, 0)
+	if err != nil {
+		t.Fatalf("ListWallets() error = %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("ListWallets() = %v, want 2 wallets", names)
+	}
+
+	// Deleting a wallet drops its records and only its reverse index entries
+	if err := ss.DeleteWallet(ctx, "deposits"); err != nil {
+		t.Fatalf("DeleteWallet() error = %v", err)
+	}
+	if _, err := ss.LookupAddress(ctx, records[1].Address); !errors.Is(err, ErrAddressNotFound) {
+		t.Fatalf("LookupAddress() after delete error = %v, want %v", err, ErrAddressNotFound)
+	}
+	found, err = ss.LookupAddress(ctx, records[0].Address)
+	if err != nil || len(found) != 1 || found[0].Wallet != "twin" {
+		t.Fatalf("LookupAddress() after delete = %+v, err = %v", found, err)
+	}
+
+	keys, err := store.List(ctx, "wallets/deposits/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("registry of deleted wallet left keys %v", keys)
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// MountConfigPath is the storage path holding the mount-level settings
+const MountConfigPath = "config/mount"
+
+// MountConfig holds the mount-level defaults applied to new wallets
+// Zero values mean the built-in default is used
+type MountConfig struct {
+	DefaultWordCount int `json:"default_word_count,omitempty"`
+	// EnabledCoinTypes allow-lists the coin types usable on the mount; empty enables every coin
+	EnabledCoinTypes []uint32 `json:"enabled_coin_types,omitempty"`
+}
+
+// CoinEnabled reports whether a coin type may be used on the mount
+func (c *MountConfig) CoinEnabled(coinType uint32) bool {
+	if len(c.EnabledCoinTypes) == 0 {
+		return true
+	}
+	for _, enabled := range c.EnabledCoinTypes {
+		if enabled == coinType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMountConfig returns the mount-level settings
+// An empty configuration is returned if none has been written
+func (ss *StorageService) GetMountConfig(ctx context.Context) (*MountConfig, error) {
+	entry, err := ss.storage.Get(ctx, MountConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mount config: %w", err)
+	}
+	if entry == nil {
+		return &MountConfig{}, nil
+	}
+
+	var config MountConfig
+	if err := json.Unmarshal(entry.Value, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode mount config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// PutMountConfig stores the mount-level settings
+func (ss *StorageService) PutMountConfig(ctx context.Context, config *MountConfig) error {
+	entry, err := logical.StorageEntryJSON(MountConfigPath, config)
+	if err != nil {
+		return fmt.Errorf("failed to create mount config entry: %w", err)
+	}
+
+	if err := ss.storage.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to store mount config: %w", err)
+	}
+
+	ss.logger.Info("mount config updated", "default_word_count", config.DefaultWordCount, "enabled_coin_types", config.EnabledCoinTypes)
+
+	return nil
+}
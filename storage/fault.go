@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// FaultStore wraps a WalletStore and fails chosen operations, so error handling can be tested
+// Operations are named after the WalletStore methods, e.g. "GetWallet"
+type FaultStore struct {
+	store  WalletStore
+	mu     sync.Mutex
+	faults map[string]error
+}
+
+// NewFaultStore wraps a store without any injected faults
+func NewFaultStore(store WalletStore) *FaultStore {
+	return &FaultStore{
+		store:  store,
+		faults: make(map[string]error),
+	}
+}
+
+// Fail makes every later call of an operation return err instead of reaching the wrapped store
+func (f *FaultStore) Fail(operation string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults[operation] = err
+}
+
+// Reset removes all injected faults
+func (f *FaultStore) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.faults = make(map[string]error)
+}
+
+// fault returns the error injected for an operation, if any
+func (f *FaultStore) fault(operation string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.faults[operation]
+}
+
+// StoreWallet stores a new wallet unless a fault is injected
+func (f *FaultStore) StoreWallet(ctx context.Context, wallet *Wallet) error {
+	if err := f.fault("StoreWallet"); err != nil {
+		return err
+	}
+	return f.store.StoreWallet(ctx, wallet)
+}
+
+// GetWallet returns a wallet unless a fault is injected
+func (f *FaultStore) GetWallet(ctx context.Context, name string) (*Wallet, error) {
+	if err := f.fault("GetWallet"); err != nil {
+		return nil, err
+	}
+	return f.store.GetWallet(ctx, name)
+}
+
+// GetWalletMetadata returns a wallet's metadata unless a fault is injected
+func (f *FaultStore) GetWalletMetadata(ctx context.Context, name string) (*Wallet, error) {
+	if err := f.fault("GetWalletMetadata"); err != nil {
+		return nil, err
+	}
+	return f.store.GetWalletMetadata(ctx, name)
+}
+
+// UpdateWallet updates a wallet's metadata unless a fault is injected
+func (f *FaultStore) UpdateWallet(ctx context.Context, wallet *Wallet) error {
+	if err := f.fault("UpdateWallet"); err != nil {
+		return err
+	}
+	return f.store.UpdateWallet(ctx, wallet)
+}
+
+// DeleteWallet removes a wallet unless a fault is injected
+func (f *FaultStore) DeleteWallet(ctx context.Context, name string) error {
+	if err := f.fault("DeleteWallet"); err != nil {
+		return err
+	}
+	return f.store.DeleteWallet(ctx, name)
+}
+
+// ListWallets lists wallet names unless a fault is injected
+func (f *FaultStore) ListWallets(ctx context.Context, offset, limit int) ([]string, error) {
+	if err := f.fault("ListWallets"); err != nil {
+		return nil, err
+	}
+	return f.store.ListWallets(ctx, offset, limit)
+}
+
+// FindWallets finds wallet names unless a fault is injected
+func (f *FaultStore) FindWallets(ctx context.Context, filter WalletFilter, offset, limit int) ([]string, error) {
+	if err := f.fault("FindWallets"); err != nil {
+		return nil, err
+	}
+	return f.store.FindWallets(ctx, filter, offset, limit)
+}
+
+// ListWalletsWithMetadata lists wallet metadata unless a fault is injected
+func (f *FaultStore) ListWalletsWithMetadata(ctx context.Context, filter WalletFilter, offset, limit int) ([]*Wallet, error) {
+	if err := f.fault("ListWalletsWithMetadata"); err != nil {
+		return nil, err
+	}
+	return f.store.ListWalletsWithMetadata(ctx, filter, offset, limit)
+}
+
+// GetMountConfig returns the mount-level settings unless a fault is injected
+func (f *FaultStore) GetMountConfig(ctx context.Context) (*MountConfig, error) {
+	if err := f.fault("GetMountConfig"); err != nil {
+		return nil, err
+	}
+	return f.store.GetMountConfig(ctx)
+}
+
+// GetChain returns a chain profile unless a fault is injected
+func (f *FaultStore) GetChain(ctx context.Context, name string) (*ChainProfile, error) {
+	if err := f.fault("GetChain"); err != nil {
+		return nil, err
+	}
+	return f.store.GetChain(ctx, name)
+}
+
+// RecordAddresses registers addresses unless a fault is injected
+func (f *FaultStore) RecordAddresses(ctx context.Context, records []*AddressRecord) error {
+	if err := f.fault("RecordAddresses"); err != nil {
+		return err
+	}
+	return f.store.RecordAddresses(ctx, records)
+}
+
+// LookupAddress resolves an address unless a fault is injected
+func (f *FaultStore) LookupAddress(ctx context.Context, address string) ([]*AddressRecord, error) {
+	if err := f.fault("LookupAddress"); err != nil {
+		return nil, err
+	}
+	return f.store.LookupAddress(ctx, address)
+}
+
+// ListAddressRecords lists a wallet's registry indexes unless a fault is injected
+func (f *FaultStore) ListAddressRecords(ctx context.Context, walletName string) ([]string, error) {
+	if err := f.fault("ListAddressRecords"); err != nil {
+		return nil, err
+	}
+	return f.store.ListAddressRecords(ctx, walletName)
+}
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// KeyringPath is the reserved storage path holding the data-encryption keys
+// It is registered for seal wrapping by the backend
+const KeyringPath = "config/keyring"
+
+// encryptionKeySize is the size of each data-encryption key (32 bytes for AES-256)
+const encryptionKeySize = 32
+
+// keyVersionPrefix marks the key version at the start of every ciphertext
+const keyVersionPrefix = "v"
+
+var (
+	// ErrKeyringUnavailable is returned when the data-encryption keys cannot be loaded or created
+	ErrKeyringUnavailable = errors.New("keyring unavailable")
+	// ErrUnknownKeyVersion is returned when a ciphertext references a key version not in the keyring
+	ErrUnknownKeyVersion = errors.New("unknown key version")
+)
+
+// keyringKey is a single version of the data-encryption key
+type keyringKey struct {
+	Key       []byte    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// keyring holds every version of the data-encryption key
+// New data is always encrypted with LatestVersion; older versions stay
+// available for decryption until the wallets using them are rewritten
+// and MinDecryptionVersion is raised above them
+type keyring struct {
+	Keys                 map[int]*keyringKey `json:"keys"`
+	LatestVersion        int                 `json:"latest_version"`
+	MinDecryptionVersion int                 `json:"min_decryption_version"`
+}
+
+// minDecryptionVersion returns the lowest key version allowed for decryption
+func (kr *keyring) minDecryptionVersion() int {
+	if kr.MinDecryptionVersion < 1 {
+		return 1
+	}
+	return kr.MinDecryptionVersion
+}
+
+// Initialize loads the keyring from storage, generating and persisting the
+// first key version if the mount has never been initialized
+func (ss *StorageService) Initialize(ctx context.Context) error {
+	ss.keyLock.Lock()
+	defer ss.keyLock.Unlock()
+
+	return ss.loadOrCreateKeyring(ctx)
+}
+
+// InvalidateKeyring drops the cached keyring so it is re-read from storage
+// This is called when another node rotates the keys
+func (ss *StorageService) InvalidateKeyring() {
+	ss.keyLock.Lock()
+	defer ss.keyLock.Unlock()
+
+	ss.keyring = nil
+}
+
+// RotateKey adds a new data-encryption key version and makes it active
+// Existing ciphertexts remain readable with their original key version
+func (ss *StorageService) RotateKey(ctx context.Context) (int, error) {
+	ss.keyLock.Lock()
+	defer ss.keyLock.Unlock()
+
+	// Always rotate on top of the persisted keyring, not a possibly stale cache
+	if err := ss.loadOrCreateKeyring(ctx); err != nil {
+		return 0, err
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := &keyring{
+		Keys:                 make(map[int]*keyringKey, len(ss.keyring.Keys)+1),
+		LatestVersion:        ss.keyring.LatestVersion + 1,
+		MinDecryptionVersion: ss.keyring.MinDecryptionVersion,
+	}
+	for version, k := range ss.keyring.Keys {
+		rotated.Keys[version] = k
+	}
+	rotated.Keys[rotated.LatestVersion] = &keyringKey{
+		Key:       key,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := ss.putKeyring(ctx, rotated); err != nil {
+		return 0, err
+	}
+
+	ss.keyring = rotated
+	ss.logger.Info("encryption key rotated", "version", rotated.LatestVersion)
+
+	return rotated.LatestVersion, nil
+}
+
+// ensureEncryptionKey makes sure the keyring is loaded before use
+func (ss *StorageService) ensureEncryptionKey(ctx context.Context) error {
+	ss.keyLock.RLock()
+	loaded := ss.keyring != nil
+	ss.keyLock.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	ss.keyLock.Lock()
+	defer ss.keyLock.Unlock()
+
+	// Another caller may have loaded the keyring while we waited for the lock
+	if ss.keyring != nil {
+		return nil
+	}
+
+	return ss.loadOrCreateKeyring(ctx)
+}
+
+// latestKey returns the active key version used for new ciphertexts
+func (ss *StorageService) latestKey() (int, []byte, error) {
+	ss.keyLock.RLock()
+	defer ss.keyLock.RUnlock()
+
+	if ss.keyring == nil {
+		return 0, nil, fmt.Errorf("%w: keyring not loaded", ErrKeyringUnavailable)
+	}
+
+	k, ok := ss.keyring.Keys[ss.keyring.LatestVersion]
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, ss.keyring.LatestVersion)
+	}
+
+	return ss.keyring.LatestVersion, k.Key, nil
+}
+
+// keyForVersion returns the key for a specific version
+// If the version is unknown the keyring is re-read once, since another node
+// may have rotated the keys since it was cached
+func (ss *StorageService) keyForVersion(ctx context.Context, version int) ([]byte, error) {
+	ss.keyLock.RLock()
+	k, ok := ss.lookupKey(version)
+	ss.keyLock.RUnlock()
+	if ok {
+		return k, nil
+	}
+
+	ss.keyLock.Lock()
+	defer ss.keyLock.Unlock()
+
+	if err := ss.loadOrCreateKeyring(ctx); err != nil {
+		return nil, err
+	}
+
+	if version < ss.keyring.minDecryptionVersion() {
+		return nil, fmt.Errorf("%w: %d", ErrKeyVersionRetired, version)
+	}
+
+	k, ok = ss.lookupKey(version)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+
+	return k, nil
+}
+
+// lookupKey returns the cached key for a version
+// The caller must hold keyLock
+func (ss *StorageService) lookupKey(version int) ([]byte, bool) {
+	if ss.keyring == nil {
+		return nil, false
+	}
+	if version < ss.keyring.minDecryptionVersion() {
+		return nil, false
+	}
+
+	k, ok := ss.keyring.Keys[version]
+	if !ok {
+		return nil, false
+	}
+
+	return k.Key, true
+}
+
+// loadOrCreateKeyring reads the keyring entry or creates it on first use
+// The caller must hold keyLock for writing
+func (ss *StorageService) loadOrCreateKeyring(ctx context.Context) error {
+	entry, err := ss.storage.Get(ctx, KeyringPath)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read keyring: %v", ErrKeyringUnavailable, err)
+	}
+
+	if entry != nil {
+		kr, err := decodeKeyring(entry.Value)
+		if err != nil {
+			return err
+		}
+
+		ss.keyring = kr
+		ss.logger.Debug("keyring loaded", "latest_version", kr.LatestVersion)
+		return nil
+	}
+
+	// First use of this mount: generate the first key version
+	key, err := generateKey()
+	if err != nil {
+		return err
+	}
+
+	kr := &keyring{
+		Keys: map[int]*keyringKey{
+			1: {Key: key, CreatedAt: time.Now().UTC()},
+		},
+		LatestVersion: 1,
+	}
+
+	if err := ss.putKeyring(ctx, kr); err != nil {
+		return err
+	}
+
+	ss.keyring = kr
+	ss.logger.Info("generated and persisted new encryption key", "version", kr.LatestVersion)
+
+	return nil
+}
+
+// putKeyring persists the keyring
+func (ss *StorageService) putKeyring(ctx context.Context, kr *keyring) error {
+	entry, err := logical.StorageEntryJSON(KeyringPath, kr)
+	if err != nil {
+		return fmt.Errorf("%w: failed to create keyring entry: %v", ErrKeyringUnavailable, err)
+	}
+
+	if err := ss.storage.Put(ctx, entry); err != nil {
+		return fmt.Errorf("%w: failed to persist keyring: %v", ErrKeyringUnavailable, err)
+	}
+
+	return nil
+}
+
+// decodeKeyring parses and validates a stored keyring
+func decodeKeyring(value []byte) (*keyring, error) {
+	var kr keyring
+	if err := json.Unmarshal(value, &kr); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode keyring: %v", ErrKeyringUnavailable, err)
+	}
+
+	for version, k := range kr.Keys {
+		if len(k.Key) != encryptionKeySize {
+			return nil, fmt.Errorf("%w: key version %d has invalid length %d", ErrKeyringUnavailable, version, len(k.Key))
+		}
+	}
+	if _, ok := kr.Keys[kr.LatestVersion]; !ok {
+		return nil, fmt.Errorf("%w: latest key version %d missing", ErrKeyringUnavailable, kr.LatestVersion)
+	}
+
+	return &kr, nil
+}
+
+// generateKey returns a new random data-encryption key
+func generateKey() ([]byte, error) {
+	key := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("%w: failed to generate encryption key: %v", ErrKeyringUnavailable, err)
+	}
+	return key, nil
+}
+
+// formatCiphertext prefixes an encoded ciphertext with its key version
+func formatCiphertext(version int, encoded string) string {
+	return keyVersionPrefix + strconv.Itoa(version) + ":" + encoded
+}
+
+// parseCiphertext splits a ciphertext into its key version and encoded payload
+func parseCiphertext(ciphertext string) (int, string, error) {
+	prefix, encoded, found := strings.Cut(ciphertext, ":")
+	if !found || !strings.HasPrefix(prefix, keyVersionPrefix) {
+		return 0, "", errors.New("malformed ciphertext key version")
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(prefix, keyVersionPrefix))
+	if err != nil || version < 1 {
+		return 0, "", errors.New("malformed ciphertext key version")
+	}
+
+	return version, encoded, nil
+}
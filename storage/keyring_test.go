@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func testWallet(name string) *Wallet {
+	return &Wallet{
+		Name:       name,
+		CoinType:   60,
+		Mnemonic:   "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		PrivateKey: bytes.Repeat([]byte{0x42}, 32),
+		PublicKey:  "02aabbcc",
+		Address:    "0x0000000000000000000000000000000000000000",
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
+func TestKeyringSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+
+	first := NewStorageService(store, hclog.NewNullLogger())
+	if err := first.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	wallet := testWallet("restart")
+	if err := first.StoreWallet(ctx, wallet); err != nil {
+		t.Fatalf("StoreWallet() error = %v", err)
+	}
+
+	// Simulate a plugin restart with a fresh service over the same storage
+	second := NewStorageService(store, hclog.NewNullLogger())
+	if err := second.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() after restart error = %v", err)
+	}
+
+	got, err := second.GetWallet(ctx, wallet.Name)
+	if err != nil {
+		t.Fatalf("GetWallet() after restart error = %v", err)
+	}
+	if got.Mnemonic != wallet.Mnemonic {
+		t.Errorf("mnemonic mismatch after restart")
+	}
+	if !bytes.Equal(got.PrivateKey, wallet.PrivateKey) {
+		t.Errorf("private key mismatch after restart")
+	}
+}
+
+func TestKeyringLazyLoad(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+
+	// Storing without an explicit Initialize must create the keyring
+	ss := NewStorageService(store, hclog.NewNullLogger())
+	if err := ss.StoreWallet(ctx, testWallet("lazy")); err != nil {
+		t.Fatalf("StoreWallet() error = %v", err)
+	}
+
+	entry, err := store.Get(ctx, KeyringPath)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", KeyringPath, err)
+	}
+	if entry == nil {
+		t.Fatalf("keyring was not persisted at %q", KeyringPath)
+	}
+
+	// Re-initializing must keep the existing key
+	_, key, err := ss.latestKey()
+	if err != nil {
+		t.Fatalf("latestKey() error = %v", err)
+	}
+	if err := ss.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	_, reloaded, err := ss.latestKey()
+	if err != nil {
+		t.Fatalf("latestKey() error = %v", err)
+	}
+	if !bytes.Equal(key, reloaded) {
+		t.Errorf("Initialize() replaced an existing encryption key")
+	}
+}
+
+func TestKeyringRejectsCorruptKey(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+
+	entry, err := logical.StorageEntryJSON(KeyringPath, &keyring{
+		Keys:          map[int]*keyringKey{1: {Key: []byte("short")}},
+		LatestVersion: 1,
+	})
+	if err != nil {
+		t.Fatalf("StorageEntryJSON() error = %v", err)
+	}
+	if err := store.Put(ctx, entry); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	ss := NewStorageService(store, hclog.NewNullLogger())
+	if err := ss.Initialize(ctx); err == nil {
+		t.Fatalf("Initialize() with corrupt keyring succeeded, want error")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+
+	ss := NewStorageService(store, hclog.NewNullLogger())
+	if err := ss.StoreWallet(ctx, testWallet("before")); err != nil {
+		t.Fatalf("StoreWallet() error = %v", err)
+	}
+
+	// A second node caches the keyring before the rotation happens
+	standby := NewStorageService(store, hclog.NewNullLogger())
+	if err := standby.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	version, err := ss.RotateKey(ctx)
+	if err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	if version != 2 {
+		t.Fatalf("RotateKey() version = %d, want 2", version)
+	}
+
+	if err := ss.StoreWallet(ctx, testWallet("after")); err != nil {
+		t.Fatalf("StoreWallet() after rotation error = %v", err)
+	}
+
+	entry, err := store.Get(ctx, "wallets/after")
+	if err != nil || entry == nil {
+		t.Fatalf("Get(wallets/after) entry = %v, error = %v", entry, err)
+	}
+	var encrypted encryptedWallet
+	if err := json.Unmarshal(entry.Value, &encrypted); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !strings.HasPrefix(encrypted.MnemonicEncrypted, "v2:") {
+		t.Errorf("new ciphertext not encrypted with version 2: %q", encrypted.MnemonicEncrypted[:3])
+	}
+
+	for _, svc := range []*StorageService{ss, standby} {
+		for _, name := range []string{"before", "after"} {
+			if _, err := svc.GetWallet(ctx, name); err != nil {
+				t.Errorf("GetWallet(%q) error = %v", name, err)
+			}
+		}
+	}
+}
+
+func TestDecryptRejectsUnversionedCiphertext(t *testing.T) {
+	ctx := context.Background()
+	ss := NewStorageService(&logical.InmemStorage{}, hclog.NewNullLogger())
+	if err := ss.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	ciphertext, err := ss.encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("encrypt() error = %v", err)
+	}
+
+	if _, err := ss.decrypt(ctx, strings.TrimPrefix(ciphertext, "v1:")); err == nil {
+		t.Fatalf("decrypt() of unversioned ciphertext succeeded, want error")
+	}
+}
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an unencrypted in-memory WalletStore for tests and tooling
+// Values are copied in and out, so callers may clear key material they were handed
+type MemoryStore struct {
+	mu      sync.RWMutex
+	wallets map[string]*Wallet
+	config  MountConfig
+	chains  map[string]*ChainProfile
+	// addresses holds each wallet's address records in registry index order
+	addresses map[string][]*AddressRecord
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		wallets:   make(map[string]*Wallet),
+		chains:    make(map[string]*ChainProfile),
+		addresses: make(map[string][]*AddressRecord),
+	}
+}
+
+// StoreWallet stores a new wallet
+func (m *MemoryStore) StoreWallet(ctx context.Context, wallet *Wallet) error {
+	if wallet == nil {
+		return errors.New("wallet cannot be nil")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.wallets[wallet.Name]; ok {
+		return ErrWalletExists
+	}
+	m.wallets[wallet.Name] = copyWallet(wallet)
+
+	return nil
+}
+
+// GetWallet returns a copy of a wallet with its key material
+func (m *MemoryStore) GetWallet(ctx context.Context, name string) (*Wallet, error) {
+	if name == "" {
+		return nil, errors.New("wallet name cannot be empty")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wallet, ok := m.wallets[name]
+	if !ok {
+		return nil, ErrWalletNotFound
+	}
+
+	return copyWallet(wallet), nil
+}
+
+// GetWalletMetadata returns a copy of a wallet without its key material
+func (m *MemoryStore) GetWalletMetadata(ctx context.Context, name string) (*Wallet, error) {
+	wallet, err := m.GetWallet(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.Mnemonic = ""
+	wallet.Passphrase = ""
+	wallet.PrivateKey = nil
+
+	return wallet, nil
+}
+
+// UpdateWallet writes a wallet's mutable metadata if its Version matches the stored version
+// It follows StorageService: key material is kept, and Version and UpdatedAt are advanced
+func (m *MemoryStore) UpdateWallet(ctx context.Context, wallet *Wallet) error {
+	if wallet == nil {
+		return errors.New("wallet cannot be nil")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.wallets[wallet.Name]
+	if !ok {
+		return ErrWalletNotFound
+	}
+	if stored.Version != wallet.Version {
+		return fmt.Errorf("%w: current version is %d, update is based on %d", ErrVersionConflict, stored.Version, wallet.Version)
+	}
+
+	wallet.Version++
+	wallet.UpdatedAt = time.Now().UTC()
+
+	stored.Description = wallet.Description
+	stored.Owner = wallet.Owner
+	stored.Tags = copyMetadata(wallet.Tags)
+	stored.Metadata = copyMetadata(wallet.Metadata)
+	stored.Labels = append([]string(nil), wallet.Labels...)
+	stored.Disabled = wallet.Disabled
+	stored.Version = wallet.Version
+	stored.UpdatedAt = wallet.UpdatedAt
+
+	return nil
+}
+
+// DeleteWallet removes a wallet and its address registry
+func (m *MemoryStore) DeleteWallet(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("wallet name cannot be empty")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.wallets[name]; !ok {
+		return ErrWalletNotFound
+	}
+	delete(m.wallets, name)
+	delete(m.addresses, name)
+
+	return nil
+}
+
+// ListWallets returns a page of wallet names in lexical order
+func (m *MemoryStore) ListWallets(ctx context.Context, offset, limit int) ([]string, error) {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.wallets))
+	for name := range m.wallets {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(names)
+
+	return paginate(names, offset, limit), nil
+}
+
+// FindWallets returns a page of the names of the wallets matching a filter, in lexical order
+func (m *MemoryStore) FindWallets(ctx context.Context, filter WalletFilter, offset, limit int) ([]string, error) {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.wallets))
+	for name, wallet := range m.wallets {
+		if filter.Matches(wallet) {
+			names = append(names, name)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(names)
+
+	return paginate(names, offset, limit), nil
+}
+
+// ListWalletsWithMetadata returns the metadata of a page of the wallets matching a filter
+func (m *MemoryStore) ListWalletsWithMetadata(ctx context.Context, filter WalletFilter, offset, limit int) ([]*Wallet, error) {
+	names, err := m.FindWallets(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]*Wallet, 0, len(names))
+	for _, name := range names {
+		// Skip wallets deleted since they were found
+		if wallet, err := m.GetWalletMetadata(ctx, name); err == nil {
+			wallets = append(wallets, wallet)
+		}
+	}
+
+	return wallets, nil
+}
+
+// GetMountConfig returns a copy of the mount-level settings
+func (m *MemoryStore) GetMountConfig(ctx context.Context) (*MountConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	config := m.config
+	config.EnabledCoinTypes = append([]uint32(nil), m.config.EnabledCoinTypes...)
+
+	return &config, nil
+}
+
+// PutMountConfig stores the mount-level settings
+func (m *MemoryStore) PutMountConfig(ctx context.Context, config *MountConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config = *config
+	m.config.EnabledCoinTypes = append([]uint32(nil), config.EnabledCoinTypes...)
+
+	return nil
+}
+
+// GetChain returns a copy of a chain profile
+func (m *MemoryStore) GetChain(ctx context.Context, name string) (*ChainProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	chain, ok := m.chains[name]
+	if !ok {
+		return nil, ErrChainNotFound
+	}
+
+	profile := *chain
+	return &profile, nil
+}
+
+// PutChain creates or replaces a chain profile
+// CreatedAt is kept from the stored profile when one exists
+func (m *MemoryStore) PutChain(ctx context.Context, chain *ChainProfile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	chain.CreatedAt = now
+	if existing, ok := m.chains[chain.Name]; ok {
+		chain.CreatedAt = existing.CreatedAt
+	}
+	chain.UpdatedAt = now
+
+	profile := *chain
+	m.chains[chain.Name] = &profile
+
+	return nil
+}
+
+// RecordAddresses registers addresses handed out by a wallet
+// It follows StorageService: addresses already registered for the wallet keep their index,
+// and a non-empty label or metadata replaces the stored one
+func (m *MemoryStore) RecordAddresses(ctx context.Context, records []*AddressRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, record := range records {
+		existing := m.walletAddressRecord(record.Wallet, record.Address)
+		if existing != nil {
+			if record.Label != "" {
+				existing.Label = record.Label
+			}
+			if len(record.Metadata) > 0 {
+				existing.Metadata = copyMetadata(record.Metadata)
+			}
+			*record = *copyAddressRecord(existing)
+			continue
+		}
+
+		record.Index = uint64(len(m.addresses[record.Wallet]))
+		record.CreatedAt = time.Now().UTC()
+		m.addresses[record.Wallet] = append(m.addresses[record.Wallet], copyAddressRecord(record))
+	}
+
+	return nil
+}
+
+// LookupAddress returns the records of the wallets that handed out an address, sorted by wallet name
+func (m *MemoryStore) LookupAddress(ctx context.Context, address string) ([]*AddressRecord, error) {
+	if address == "" {
+		return nil, errors.New("address cannot be empty")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var records []*AddressRecord
+	for walletName := range m.addresses {
+		if record := m.walletAddressRecord(walletName, address); record != nil {
+			records = append(records, copyAddressRecord(record))
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, ErrAddressNotFound
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Wallet < records[j].Wallet })
+
+	return records, nil
+}
+
+// ListAddressRecords returns the registry indexes of a wallet's addresses
+func (m *MemoryStore) ListAddressRecords(ctx context.Context, walletName string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	indexes := make([]string, 0, len(m.addresses[walletName]))
+	for _, record := range m.addresses[walletName] {
+		indexes = append(indexes, strconv.FormatUint(record.Index, 10))
+	}
+
+	return indexes, nil
+}
+
+// walletAddressRecord returns a wallet's stored record of an address, or nil
+// Addresses are matched the way the StorageService reverse index matches them
+func (m *MemoryStore) walletAddressRecord(walletName string, address string) *AddressRecord {
+	for _, record := range m.addresses[walletName] {
+		if addressIndexFolder(record.Address) == addressIndexFolder(address) {
+			return record
+		}
+	}
+	return nil
+}
+
+// copyWallet returns a deep copy of a wallet
+func copyWallet(w *Wallet) *Wallet {
+	wallet := *w
+	wallet.PrivateKey = append([]byte(nil), w.PrivateKey...)
+	wallet.CoinTypes = append([]uint32(nil), w.CoinTypes...)
+	wallet.Tags = copyMetadata(w.Tags)
+	wallet.Metadata = copyMetadata(w.Metadata)
+	wallet.Labels = append([]string(nil), w.Labels...)
+	return &wallet
+}
+
+// copyAddressRecord returns a deep copy of an address record
+func copyAddressRecord(r *AddressRecord) *AddressRecord {
+	record := *r
+	record.Metadata = copyMetadata(r.Metadata)
+	return &record
+}
+
+// copyMetadata returns a copy of a string map such as address metadata; nil stays nil
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,296 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// RewrapStatePath is the storage path holding the rewrap progress checkpoint
+const RewrapStatePath = "config/rewrap"
+
+// rewrapBatchSize is the number of wallets processed per worker tick
+const rewrapBatchSize = 100
+
+// Rewrap job statuses
+const (
+	RewrapStatusIdle      = "idle"
+	RewrapStatusRunning   = "running"
+	RewrapStatusCompleted = "completed"
+)
+
+var (
+	// ErrKeyVersionRetired is returned when a ciphertext uses a key version below min_decryption_version
+	ErrKeyVersionRetired = errors.New("key version retired")
+	// ErrRewrapIncomplete is returned when retiring key versions that wallets may still use
+	ErrRewrapIncomplete = errors.New("rewrap not completed for requested key version")
+	// ErrInvalidKeyVersion is returned when a requested key version is not in the keyring
+	ErrInvalidKeyVersion = errors.New("invalid key version")
+)
+
+// RewrapState is the persisted progress of a rewrap job
+// Cursor is the last wallet name processed, so an interrupted job resumes after it
+type RewrapState struct {
+	Status        string    `json:"status"`
+	TargetVersion int       `json:"target_version"`
+	Cursor        string    `json:"cursor"`
+	Processed     int       `json:"processed"`
+	Rewrapped     int       `json:"rewrapped"`
+	Failed        int       `json:"failed"`
+	LastError     string    `json:"last_error,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	CompletedAt   time.Time `json:"completed_at"`
+}
+
+// KeyConfig describes the keyring versions without exposing key material
+type KeyConfig struct {
+	LatestVersion        int
+	MinDecryptionVersion int
+}
+
+// StartRewrap begins rewrapping every wallet onto the latest key version
+// If a job is already running its current state is returned unchanged
+func (ss *StorageService) StartRewrap(ctx context.Context) (*RewrapState, error) {
+	if err := ss.ensureEncryptionKey(ctx); err != nil {
+		return nil, err
+	}
+
+	state, err := ss.GetRewrapState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state.Status == RewrapStatusRunning {
+		ss.logger.Debug("rewrap already running", "target_version", state.TargetVersion)
+		return state, nil
+	}
+
+	version, _, err := ss.latestKey()
+	if err != nil {
+		return nil, err
+	}
+
+	state = &RewrapState{
+		Status:        RewrapStatusRunning,
+		TargetVersion: version,
+		StartedAt:     time.Now().UTC(),
+	}
+	if err := ss.putRewrapState(ctx, state); err != nil {
+		return nil, err
+	}
+
+	ss.logger.Info("rewrap started", "target_version", version)
+
+	return state, nil
+}
+
+// GetRewrapState returns the persisted rewrap progress
+func (ss *StorageService) GetRewrapState(ctx context.Context) (*RewrapState, error) {
+	entry, err := ss.storage.Get(ctx, RewrapStatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rewrap state: %w", err)
+	}
+	if entry == nil {
+		return &RewrapState{Status: RewrapStatusIdle}, nil
+	}
+
+	var state RewrapState
+	if err := json.Unmarshal(entry.Value, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode rewrap state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// RunRewrapBatch advances a running rewrap job by one batch of wallets
+// Progress is checkpointed after the batch so the job survives restarts
+func (ss *StorageService) RunRewrapBatch(ctx context.Context) error {
+	state, err := ss.GetRewrapState(ctx)
+	if err != nil {
+		return err
+	}
+	if state.Status != RewrapStatusRunning {
+		return nil
+	}
+
+	if err := ss.ensureEncryptionKey(ctx); err != nil {
+		return err
+	}
+
+	names, err := ss.ListWallets(ctx, 0, 0)
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	// Resume after the last checkpointed wallet
+	start := sort.SearchStrings(names, state.Cursor)
+	if start < len(names) && names[start] == state.Cursor {
+		start++
+	}
+
+	end := start + rewrapBatchSize
+	if end > len(names) {
+		end = len(names)
+	}
+
+	for _, name := range names[start:end] {
+		name = strings.TrimSuffix(name, "/")
+
+		rewrapped, err := ss.rewrapWallet(ctx, name, state.TargetVersion)
+		if err != nil {
+			ss.logger.Error("failed to rewrap wallet", "name", sanitizeName(name), "error", err)
+			state.Failed++
+			state.LastError = fmt.Sprintf("%s: %v", sanitizeName(name), err)
+		} else if rewrapped {
+			state.Rewrapped++
+		}
+
+		state.Processed++
+		state.Cursor = name
+	}
+
+	if end == len(names) {
+		state.Status = RewrapStatusCompleted
+		state.CompletedAt = time.Now().UTC()
+		ss.logger.Info("rewrap completed", "target_version", state.TargetVersion,
+			"processed", state.Processed, "rewrapped", state.Rewrapped, "failed", state.Failed)
+	}
+
+	return ss.putRewrapState(ctx, state)
+}
+
+// GetKeyConfig returns the latest and minimum decryption key versions
+func (ss *StorageService) GetKeyConfig(ctx context.Context) (*KeyConfig, error) {
+	if err := ss.ensureEncryptionKey(ctx); err != nil {
+		return nil, err
+	}
+
+	ss.keyLock.RLock()
+	defer ss.keyLock.RUnlock()
+
+	return &KeyConfig{
+		LatestVersion:        ss.keyring.LatestVersion,
+		MinDecryptionVersion: ss.keyring.minDecryptionVersion(),
+	}, nil
+}
+
+// SetMinDecryptionVersion refuses decryption with key versions below version
+// It is only allowed once a rewrap onto at least that version has completed
+// without failures, so no stored wallet can still depend on a retired key
+func (ss *StorageService) SetMinDecryptionVersion(ctx context.Context, version int) error {
+	state, err := ss.GetRewrapState(ctx)
+	if err != nil {
+		return err
+	}
+
+	ss.keyLock.Lock()
+	defer ss.keyLock.Unlock()
+
+	if err := ss.loadOrCreateKeyring(ctx); err != nil {
+		return err
+	}
+
+	if version < 1 || version > ss.keyring.LatestVersion {
+		return fmt.Errorf("%w: %d (latest is %d)", ErrInvalidKeyVersion, version, ss.keyring.LatestVersion)
+	}
+
+	if version > ss.keyring.minDecryptionVersion() {
+		if state.Status != RewrapStatusCompleted || state.Failed > 0 || state.TargetVersion < version {
+			return fmt.Errorf("%w: %d", ErrRewrapIncomplete, version)
+		}
+	}
+
+	updated := *ss.keyring
+	updated.MinDecryptionVersion = version
+	if err := ss.putKeyring(ctx, &updated); err != nil {
+		return err
+	}
+
+	ss.keyring = &updated
+	ss.logger.Info("minimum decryption version updated", "version", version)
+
+	return nil
+}
+
+// rewrapWallet re-encrypts a wallet's sensitive fields with the latest key
+// Wallets already encrypted with at least targetVersion are left untouched
+func (ss *StorageService) rewrapWallet(ctx context.Context, name string, targetVersion int) (bool, error) {
+	// Hold off metadata updates so they are not overwritten by the re-encrypted copy
+	ss.walletLock.Lock()
+	defer ss.walletLock.Unlock()
+
+	entry, err := ss.storage.Get(ctx, "wallets/"+name)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+	if entry == nil {
+		// Deleted since the batch was listed
+		return false, nil
+	}
+
+	var encrypted encryptedWallet
+	if err := json.Unmarshal(entry.Value, &encrypted); err != nil {
+		return false, fmt.Errorf("failed to decode wallet: %w", err)
+	}
+
+	if ciphertextVersion(encrypted.MnemonicEncrypted) >= targetVersion &&
+		ciphertextVersion(encrypted.PrivateKeyEncrypted) >= targetVersion &&
+		(encrypted.PassphraseEncrypted == "" || ciphertextVersion(encrypted.PassphraseEncrypted) >= targetVersion) {
+		return false, nil
+	}
+
+	wallet, err := ss.decryptWallet(ctx, &encrypted)
+	if err != nil {
+		return false, err
+	}
+
+	rewrapped, err := ss.encryptWallet(wallet)
+	for i := range wallet.PrivateKey {
+		wallet.PrivateKey[i] = 0
+	}
+	wallet.Mnemonic = ""
+	wallet.Passphrase = ""
+	if err != nil {
+		return false, err
+	}
+
+	newEntry, err := logical.StorageEntryJSON("wallets/"+name, rewrapped)
+	if err != nil {
+		return false, fmt.Errorf("failed to create storage entry: %w", err)
+	}
+
+	if err := ss.storage.Put(ctx, newEntry); err != nil {
+		return false, fmt.Errorf("failed to store wallet: %w", err)
+	}
+
+	return true, nil
+}
+
+// putRewrapState checkpoints the rewrap progress
+func (ss *StorageService) putRewrapState(ctx context.Context, state *RewrapState) error {
+	entry, err := logical.StorageEntryJSON(RewrapStatePath, state)
+	if err != nil {
+		return fmt.Errorf("failed to create rewrap state entry: %w", err)
+	}
+
+	if err := ss.storage.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to store rewrap state: %w", err)
+	}
+
+	return nil
+}
+
+// ciphertextVersion returns the key version of a ciphertext, or 0 if it is malformed
+func ciphertextVersion(ciphertext string) int {
+	version, _, err := parseCiphertext(ciphertext)
+	if err != nil {
+		return 0
+	}
+	return version
+}
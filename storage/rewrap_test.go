@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestRewrapMovesWalletsToLatestKey(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	ss := NewStorageService(store, hclog.NewNullLogger())
+
+	// More wallets than one batch so the worker has to resume from its cursor
+	total := rewrapBatchSize + 5
+	for i := 0; i < total; i++ {
+		if err := ss.StoreWallet(ctx, testWallet(fmt.Sprintf("w%03d", i))); err != nil {
+			t.Fatalf("StoreWallet() error = %v", err)
+		}
+	}
+
+	if _, err := ss.RotateKey(ctx); err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+
+	// A version that was never created is rejected, and retiring version 1 before the rewrap must be refused
+	if err := ss.SetMinDecryptionVersion(ctx, 3); !errors.Is(err, ErrInvalidKeyVersion) {
+		t.Fatalf("SetMinDecryptionVersion() beyond the latest version error = %v, want %v", err, ErrInvalidKeyVersion)
+	}
+	if err := ss.SetMinDecryptionVersion(ctx, 2); !errors.Is(err, ErrRewrapIncomplete) {
+		t.Fatalf("SetMinDecryptionVersion() before rewrap error = %v, want %v", err, ErrRewrapIncomplete)
+	}
+
+	state, err := ss.StartRewrap(ctx)
+	if err != nil {
+		t.Fatalf("StartRewrap() error = %v", err)
+	}
+	if state.Status != RewrapStatusRunning || state.TargetVersion != 2 {
+		t.Fatalf("StartRewrap() state = %+v", state)
+	}
+
+	if err := ss.RunRewrapBatch(ctx); err != nil {
+		t.Fatalf("RunRewrapBatch() error = %v", err)
+	}
+	state, err = ss.GetRewrapState(ctx)
+	if err != nil {
+		t.Fatalf("GetRewrapState() error = %v", err)
+	}
+	if state.Status != RewrapStatusRunning || state.Processed != rewrapBatchSize {
+		t.Fatalf("state after first batch = %+v", state)
+	}
+
+	// A restarted worker picks up from the persisted checkpoint
+	restarted := NewStorageService(store, hclog.NewNullLogger())
+	if err := restarted.RunRewrapBatch(ctx); err != nil {
+		t.Fatalf("RunRewrapBatch() error = %v", err)
+	}
+	state, err = restarted.GetRewrapState(ctx)
+	if err != nil {
+		t.Fatalf("GetRewrapState() error = %v", err)
+	}
+	if state.Status != RewrapStatusCompleted || state.Processed != total || state.Rewrapped != total || state.Failed != 0 {
+		t.Fatalf("state after second batch = %+v", state)
+	}
+
+	entry, err := store.Get(ctx, "wallets/w000")
+	if err != nil || entry == nil {
+		t.Fatalf("Get(wallets/w000) entry = %v, error = %v", entry, err)
+	}
+	var encrypted encryptedWallet
+	if err := json.Unmarshal(entry.Value, &encrypted); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !strings.HasPrefix(encrypted.PrivateKeyEncrypted, "v2:") {
+		t.Errorf("wallet not rewrapped onto version 2")
+	}
+
+	if err := restarted.SetMinDecryptionVersion(ctx, 2); err != nil {
+		t.Fatalf("SetMinDecryptionVersion() after rewrap error = %v", err)
+	}
+	if _, err := restarted.GetWallet(ctx, "w000"); err != nil {
+		t.Errorf("GetWallet() after retiring version 1 error = %v", err)
+	}
+
+	// Ciphertexts under the retired version are refused
+	if _, err := restarted.decrypt(ctx, strings.Replace(encrypted.PrivateKeyEncrypted, "v2:", "v1:", 1)); !errors.Is(err, ErrKeyVersionRetired) {
+		t.Errorf("decrypt() with retired version error = %v, want %v", err, ErrKeyVersionRetired)
+	}
+}
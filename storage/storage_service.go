@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
@@ -26,17 +28,72 @@ var (
 	ErrEncryptionFailed = errors.New("encryption failed")
 	// ErrDecryptionFailed is returned when decryption operations fail
 	ErrDecryptionFailed = errors.New("decryption failed")
+	// ErrVersionConflict is returned when an update is based on an outdated wallet version
+	ErrVersionConflict = errors.New("wallet version conflict")
 )
 
 // Wallet represents a cryptocurrency wallet with its metadata and key material
 type Wallet struct {
-	Name       string    `json:"name"`
-	CoinType   uint32    `json:"coin_type"`
-	Mnemonic   string    `json:"-"` // Never serialized to JSON
-	PrivateKey []byte    `json:"-"` // Never serialized to JSON
-	PublicKey  string    `json:"public_key"`
-	Address    string    `json:"address"`
-	CreatedAt  time.Time `json:"created_at"`
+	Name       string   `json:"name"`
+	CoinType   uint32   `json:"coin_type"`
+	Mnemonic   string   `json:"-"` // Never serialized to JSON
+	Passphrase string   `json:"-"` // Never serialized to JSON
+	PrivateKey []byte   `json:"-"` // Never serialized to JSON
+	PublicKey  string   `json:"public_key"`
+	Address    string   `json:"address"`
+	Curve      string   `json:"curve"`
+	CoinTypes  []uint32 `json:"coin_types"`
+	// WordCount is the number of mnemonic words; 0 for wallets stored before it was recorded
+	WordCount int `json:"word_count"`
+	// AddressType is the Bitcoin address type of the wallet's default key; empty for other coins
+	AddressType string `json:"address_type"`
+	// PassphraseRequired is set when the wallet has a BIP39 passphrase that is not stored,
+	// so every operation that derives keys must supply it
+	PassphraseRequired bool      `json:"passphrase_required"`
+	CreatedAt          time.Time `json:"created_at"`
+
+	// Description, Owner, Tags, Metadata, Labels and Disabled are the mutable metadata of the wallet
+	Description string            `json:"description"`
+	Owner       string            `json:"owner"`
+	Tags        map[string]string `json:"tags"`
+	Metadata    map[string]string `json:"metadata"`
+	// Labels are free-form names the wallet can be listed by, kept sorted and unique
+	Labels []string `json:"labels"`
+	// Disabled wallets refuse every operation that uses their keys
+	Disabled bool `json:"disabled"`
+	// Version is incremented by every metadata update and is checked by UpdateWallet;
+	// 0 for wallets stored before updates were versioned
+	Version   uint64    `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EnabledCoinTypes returns the coin types the wallet's seed may be used for
+// Wallets stored before multi-coin support are enabled for their creation coin only
+func (w *Wallet) EnabledCoinTypes() []uint32 {
+	if len(w.CoinTypes) == 0 {
+		return []uint32{w.CoinType}
+	}
+	return w.CoinTypes
+}
+
+// HasCoinType reports whether the wallet is enabled for a coin type
+func (w *Wallet) HasCoinType(coinType uint32) bool {
+	for _, enabled := range w.EnabledCoinTypes() {
+		if enabled == coinType {
+			return true
+		}
+	}
+	return false
+}
+
+// HasLabel reports whether the wallet carries a label
+func (w *Wallet) HasLabel(label string) bool {
+	for _, l := range w.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
 }
 
 // encryptedWallet is the internal representation with encrypted sensitive fields
@@ -44,25 +101,84 @@ type encryptedWallet struct {
 	Name                string    `json:"name"`
 	CoinType            uint32    `json:"coin_type"`
 	MnemonicEncrypted   string    `json:"mnemonic_encrypted"`
+	PassphraseEncrypted string    `json:"passphrase_encrypted,omitempty"`
 	PrivateKeyEncrypted string    `json:"private_key_encrypted"`
 	PublicKey           string    `json:"public_key"`
 	Address             string    `json:"address"`
+	Curve               string    `json:"curve"`
+	CoinTypes           []uint32  `json:"coin_types,omitempty"`
+	WordCount           int       `json:"word_count,omitempty"`
+	AddressType         string    `json:"address_type,omitempty"`
+	PassphraseRequired  bool      `json:"passphrase_required,omitempty"`
 	CreatedAt           time.Time `json:"created_at"`
+
+	Description string            `json:"description,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Labels      []string          `json:"labels,omitempty"`
+	Disabled    bool              `json:"disabled,omitempty"`
+	Version     uint64            `json:"version,omitempty"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// setMetadata copies the mutable metadata of a wallet, leaving the key material untouched
+func (e *encryptedWallet) setMetadata(wallet *Wallet) {
+	e.Description = wallet.Description
+	e.Owner = wallet.Owner
+	e.Tags = wallet.Tags
+	e.Metadata = wallet.Metadata
+	e.Labels = wallet.Labels
+	e.Disabled = wallet.Disabled
+	e.Version = wallet.Version
+	e.UpdatedAt = wallet.UpdatedAt
+}
+
+// metadata returns the wallet without decrypting its sensitive fields
+func (e *encryptedWallet) metadata() *Wallet {
+	return &Wallet{
+		Name:               e.Name,
+		CoinType:           e.CoinType,
+		PublicKey:          e.PublicKey,
+		Address:            e.Address,
+		Curve:              e.Curve,
+		CoinTypes:          e.CoinTypes,
+		WordCount:          e.WordCount,
+		AddressType:        e.AddressType,
+		PassphraseRequired: e.PassphraseRequired,
+		CreatedAt:          e.CreatedAt,
+		Description:        e.Description,
+		Owner:              e.Owner,
+		Tags:               e.Tags,
+		Metadata:           e.Metadata,
+		Labels:             e.Labels,
+		Disabled:           e.Disabled,
+		Version:            e.Version,
+		UpdatedAt:          e.UpdatedAt,
+	}
 }
 
 // StorageService handles encrypted storage of wallet data
 type StorageService struct {
-	storage       logical.Storage
-	encryptionKey []byte
-	logger        hclog.Logger
+	storage logical.Storage
+	keyring *keyring
+	keyLock sync.RWMutex
+	// registryLock serializes address registry writes so indexes are not handed out twice
+	registryLock sync.Mutex
+	// walletLock serializes writes of stored wallets so check-and-set updates are not lost
+	// and deleted wallets are not written back
+	walletLock sync.Mutex
+	// walletIndexesReady is set once the wallet indexes are known to cover every wallet
+	walletIndexesReady atomic.Bool
+	logger             hclog.Logger
 }
 
 // NewStorageService creates a new storage service instance
-func NewStorageService(storage logical.Storage, encryptionKey []byte, logger hclog.Logger) *StorageService {
+// The data-encryption key is loaded from the keyring on first use
+func NewStorageService(storage logical.Storage, logger hclog.Logger) *StorageService {
 	return &StorageService{
-		storage:       storage,
-		encryptionKey: encryptionKey,
-		logger:        logger,
+		storage: storage,
+		logger:  logger,
 	}
 }
 
@@ -75,6 +191,9 @@ func (ss *StorageService) StoreWallet(ctx context.Context, wallet *Wallet) error
 
 	ss.logger.Debug("storing wallet", "name", sanitizeName(wallet.Name))
 
+	ss.walletLock.Lock()
+	defer ss.walletLock.Unlock()
+
 	// Check if wallet already exists
 	existing, err := ss.storage.Get(ctx, "wallets/"+wallet.Name)
 	if err != nil {
@@ -86,23 +205,14 @@ func (ss *StorageService) StoreWallet(ctx context.Context, wallet *Wallet) error
 		return ErrWalletExists
 	}
 
-	// Encrypt sensitive fields
-	encrypted, err := ss.encryptWallet(wallet)
-	if err != nil {
-		ss.logger.Error("failed to encrypt wallet", "name", sanitizeName(wallet.Name), "error", err)
-		return fmt.Errorf("failed to encrypt wallet: %w", err)
-	}
-
-	// Store encrypted wallet
-	entry, err := logical.StorageEntryJSON("wallets/"+wallet.Name, encrypted)
-	if err != nil {
-		ss.logger.Error("failed to create storage entry", "name", sanitizeName(wallet.Name), "error", err)
-		return fmt.Errorf("failed to create storage entry: %w", err)
+	// Index the wallet before writing it, so it is never stored without its index entries
+	if err := ss.addWalletIndexes(ctx, nil, wallet); err != nil {
+		ss.logger.Error("failed to index wallet", "name", sanitizeName(wallet.Name), "error", err)
+		return err
 	}
 
-	if err := ss.storage.Put(ctx, entry); err != nil {
-		ss.logger.Error("failed to store wallet", "name", sanitizeName(wallet.Name), "error", err)
-		return fmt.Errorf("failed to store wallet: %w", err)
+	if err := ss.putWallet(ctx, wallet); err != nil {
+		return err
 	}
 
 	ss.logger.Info("wallet stored successfully", "name", sanitizeName(wallet.Name))
@@ -135,8 +245,14 @@ func (ss *StorageService) GetWallet(ctx context.Context, name string) (*Wallet,
 		return nil, fmt.Errorf("failed to decode wallet: %w", err)
 	}
 
+	// Make sure the data-encryption key is loaded
+	if err := ss.ensureEncryptionKey(ctx); err != nil {
+		ss.logger.Error("failed to load encryption key", "error", err)
+		return nil, err
+	}
+
 	// Decrypt sensitive fields
-	wallet, err := ss.decryptWallet(&encrypted)
+	wallet, err := ss.decryptWallet(ctx, &encrypted)
 	if err != nil {
 		ss.logger.Error("failed to decrypt wallet", "name", sanitizeName(name), "error", err)
 		return nil, fmt.Errorf("failed to decrypt wallet: %w", err)
@@ -147,6 +263,122 @@ func (ss *StorageService) GetWallet(ctx context.Context, name string) (*Wallet,
 	return wallet, nil
 }
 
+// UpdateWallet writes a wallet's mutable metadata if its Version matches the stored version
+// The stored key material is kept exactly as encrypted; on success the wallet's Version
+// and UpdatedAt are advanced
+func (ss *StorageService) UpdateWallet(ctx context.Context, wallet *Wallet) error {
+	if wallet == nil {
+		ss.logger.Error("attempted to update nil wallet")
+		return errors.New("wallet cannot be nil")
+	}
+
+	ss.logger.Debug("updating wallet", "name", sanitizeName(wallet.Name))
+
+	ss.walletLock.Lock()
+	defer ss.walletLock.Unlock()
+
+	encrypted, err := ss.getEncryptedWallet(ctx, wallet.Name)
+	if err != nil {
+		if errors.Is(err, ErrWalletNotFound) {
+			ss.logger.Debug("wallet not found for update", "name", sanitizeName(wallet.Name))
+		} else {
+			ss.logger.Error("failed to retrieve wallet for update", "name", sanitizeName(wallet.Name), "error", err)
+		}
+		return err
+	}
+
+	if encrypted.Version != wallet.Version {
+		ss.logger.Warn("wallet version conflict", "name", sanitizeName(wallet.Name),
+			"version", encrypted.Version, "update_version", wallet.Version)
+		return fmt.Errorf("%w: current version is %d, update is based on %d", ErrVersionConflict, encrypted.Version, wallet.Version)
+	}
+
+	previous := encrypted.metadata()
+
+	updated := *wallet
+	updated.Version++
+	updated.UpdatedAt = time.Now().UTC()
+	encrypted.setMetadata(&updated)
+
+	entry, err := logical.StorageEntryJSON("wallets/"+wallet.Name, encrypted)
+	if err != nil {
+		ss.logger.Error("failed to create storage entry", "name", sanitizeName(wallet.Name), "error", err)
+		return fmt.Errorf("failed to create storage entry: %w", err)
+	}
+
+	// New index entries are written before the wallet and stale ones removed after it
+	current := encrypted.metadata()
+	if err := ss.addWalletIndexes(ctx, previous, current); err != nil {
+		ss.logger.Error("failed to index wallet", "name", sanitizeName(wallet.Name), "error", err)
+		return err
+	}
+
+	if err := ss.storage.Put(ctx, entry); err != nil {
+		ss.logger.Error("failed to store wallet", "name", sanitizeName(wallet.Name), "error", err)
+		return fmt.Errorf("failed to store wallet: %w", err)
+	}
+
+	if err := ss.removeWalletIndexes(ctx, previous, current); err != nil {
+		ss.logger.Error("failed to delete wallet indexes", "name", sanitizeName(wallet.Name), "error", err)
+		return err
+	}
+
+	wallet.Version = updated.Version
+	wallet.UpdatedAt = updated.UpdatedAt
+
+	ss.logger.Info("wallet updated successfully", "name", sanitizeName(wallet.Name), "version", wallet.Version)
+
+	return nil
+}
+
+// getEncryptedWallet reads a stored wallet without decrypting it, or returns ErrWalletNotFound
+func (ss *StorageService) getEncryptedWallet(ctx context.Context, name string) (*encryptedWallet, error) {
+	entry, err := ss.storage.Get(ctx, "wallets/"+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
+	}
+	if entry == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	var encrypted encryptedWallet
+	if err := json.Unmarshal(entry.Value, &encrypted); err != nil {
+		return nil, fmt.Errorf("failed to decode wallet: %w", err)
+	}
+
+	return &encrypted, nil
+}
+
+// putWallet encrypts a wallet under the latest key version and writes it to storage
+func (ss *StorageService) putWallet(ctx context.Context, wallet *Wallet) error {
+	// Make sure the data-encryption key is loaded
+	if err := ss.ensureEncryptionKey(ctx); err != nil {
+		ss.logger.Error("failed to load encryption key", "error", err)
+		return err
+	}
+
+	// Encrypt sensitive fields
+	encrypted, err := ss.encryptWallet(wallet)
+	if err != nil {
+		ss.logger.Error("failed to encrypt wallet", "name", sanitizeName(wallet.Name), "error", err)
+		return fmt.Errorf("failed to encrypt wallet: %w", err)
+	}
+
+	// Store encrypted wallet
+	entry, err := logical.StorageEntryJSON("wallets/"+wallet.Name, encrypted)
+	if err != nil {
+		ss.logger.Error("failed to create storage entry", "name", sanitizeName(wallet.Name), "error", err)
+		return fmt.Errorf("failed to create storage entry: %w", err)
+	}
+
+	if err := ss.storage.Put(ctx, entry); err != nil {
+		ss.logger.Error("failed to store wallet", "name", sanitizeName(wallet.Name), "error", err)
+		return fmt.Errorf("failed to store wallet: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteWallet removes a wallet from storage
 func (ss *StorageService) DeleteWallet(ctx context.Context, name string) error {
 	if name == "" {
@@ -156,15 +388,24 @@ func (ss *StorageService) DeleteWallet(ctx context.Context, name string) error {
 
 	ss.logger.Debug("deleting wallet", "name", sanitizeName(name))
 
+	ss.walletLock.Lock()
+	defer ss.walletLock.Unlock()
+
 	// Verify wallet exists before deletion
-	entry, err := ss.storage.Get(ctx, "wallets/"+name)
+	encrypted, err := ss.getEncryptedWallet(ctx, name)
 	if err != nil {
-		ss.logger.Error("failed to check wallet existence", "name", sanitizeName(name), "error", err)
-		return fmt.Errorf("failed to check wallet existence: %w", err)
+		if errors.Is(err, ErrWalletNotFound) {
+			ss.logger.Debug("wallet not found for deletion", "name", sanitizeName(name))
+		} else {
+			ss.logger.Error("failed to check wallet existence", "name", sanitizeName(name), "error", err)
+		}
+		return err
 	}
-	if entry == nil {
-		ss.logger.Debug("wallet not found for deletion", "name", sanitizeName(name))
-		return ErrWalletNotFound
+
+	// Delete the wallet's address registry before the wallet itself
+	if err := ss.deleteAddressRecords(ctx, name); err != nil {
+		ss.logger.Error("failed to delete address records", "name", sanitizeName(name), "error", err)
+		return err
 	}
 
 	// Delete the wallet
@@ -173,6 +414,12 @@ func (ss *StorageService) DeleteWallet(ctx context.Context, name string) error {
 		return fmt.Errorf("failed to delete wallet: %w", err)
 	}
 
+	// Drop the wallet from the secondary indexes once it is gone
+	if err := ss.removeWalletIndexes(ctx, encrypted.metadata(), nil); err != nil {
+		ss.logger.Error("failed to delete wallet indexes", "name", sanitizeName(name), "error", err)
+		return err
+	}
+
 	ss.logger.Info("wallet deleted successfully", "name", sanitizeName(name))
 
 	return nil
@@ -182,17 +429,31 @@ func (ss *StorageService) DeleteWallet(ctx context.Context, name string) error {
 func (ss *StorageService) ListWallets(ctx context.Context, offset, limit int) ([]string, error) {
 	ss.logger.Debug("listing wallets", "offset", offset, "limit", limit)
 
-	keys, err := ss.storage.List(ctx, "wallets/")
+	entries, err := ss.storage.List(ctx, "wallets/")
 	if err != nil {
 		ss.logger.Error("failed to list wallets", "error", err)
 		return nil, fmt.Errorf("failed to list wallets: %w", err)
 	}
 
-	// Apply pagination
+	// Skip the folders holding each wallet's address registry
+	keys := make([]string, 0, len(entries))
+	for _, key := range entries {
+		if !strings.HasSuffix(key, "/") {
+			keys = append(keys, key)
+		}
+	}
+
+	result := paginate(keys, offset, limit)
+	ss.logger.Debug("wallets listed successfully", "count", len(result), "total", len(keys))
+
+	return result, nil
+}
+
+// paginate returns the page of keys starting at offset; a non-positive limit returns the rest
+func paginate(keys []string, offset, limit int) []string {
 	total := len(keys)
 	if offset >= total {
-		ss.logger.Debug("offset exceeds total wallets", "offset", offset, "total", total)
-		return []string{}, nil
+		return []string{}
 	}
 
 	end := offset + limit
@@ -200,10 +461,7 @@ func (ss *StorageService) ListWallets(ctx context.Context, offset, limit int) ([
 		end = total
 	}
 
-	result := keys[offset:end]
-	ss.logger.Debug("wallets listed successfully", "count", len(result), "total", total)
-
-	return result, nil
+	return keys[offset:end]
 }
 
 // encryptWallet encrypts sensitive fields of a wallet
@@ -214,51 +472,82 @@ func (ss *StorageService) encryptWallet(wallet *Wallet) (*encryptedWallet, error
 		return nil, fmt.Errorf("%w: failed to encrypt mnemonic", ErrEncryptionFailed)
 	}
 
+	// Encrypt passphrase, if the wallet has one that should be stored
+	var passphraseEncrypted string
+	if wallet.Passphrase != "" {
+		passphraseEncrypted, err = ss.encrypt([]byte(wallet.Passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to encrypt passphrase", ErrEncryptionFailed)
+		}
+	}
+
 	// Encrypt private key
 	privateKeyEncrypted, err := ss.encrypt(wallet.PrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("%w: failed to encrypt private key", ErrEncryptionFailed)
 	}
 
-	return &encryptedWallet{
+	encrypted := &encryptedWallet{
 		Name:                wallet.Name,
 		CoinType:            wallet.CoinType,
 		MnemonicEncrypted:   mnemonicEncrypted,
+		PassphraseEncrypted: passphraseEncrypted,
 		PrivateKeyEncrypted: privateKeyEncrypted,
 		PublicKey:           wallet.PublicKey,
 		Address:             wallet.Address,
+		Curve:               wallet.Curve,
+		CoinTypes:           wallet.CoinTypes,
+		WordCount:           wallet.WordCount,
+		AddressType:         wallet.AddressType,
+		PassphraseRequired:  wallet.PassphraseRequired,
 		CreatedAt:           wallet.CreatedAt,
-	}, nil
+	}
+	encrypted.setMetadata(wallet)
+
+	return encrypted, nil
 }
 
 // decryptWallet decrypts sensitive fields of an encrypted wallet
-func (ss *StorageService) decryptWallet(encrypted *encryptedWallet) (*Wallet, error) {
+func (ss *StorageService) decryptWallet(ctx context.Context, encrypted *encryptedWallet) (*Wallet, error) {
 	// Decrypt mnemonic
-	mnemonicBytes, err := ss.decrypt(encrypted.MnemonicEncrypted)
+	mnemonicBytes, err := ss.decrypt(ctx, encrypted.MnemonicEncrypted)
 	if err != nil {
-		return nil, fmt.Errorf("%w: failed to decrypt mnemonic", ErrDecryptionFailed)
+		return nil, fmt.Errorf("%w: failed to decrypt mnemonic: %w", ErrDecryptionFailed, err)
+	}
+
+	// Decrypt passphrase, if one is stored
+	var passphrase string
+	if encrypted.PassphraseEncrypted != "" {
+		passphraseBytes, err := ss.decrypt(ctx, encrypted.PassphraseEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decrypt passphrase: %w", ErrDecryptionFailed, err)
+		}
+		passphrase = string(passphraseBytes)
 	}
 
 	// Decrypt private key
-	privateKey, err := ss.decrypt(encrypted.PrivateKeyEncrypted)
+	privateKey, err := ss.decrypt(ctx, encrypted.PrivateKeyEncrypted)
 	if err != nil {
-		return nil, fmt.Errorf("%w: failed to decrypt private key", ErrDecryptionFailed)
+		return nil, fmt.Errorf("%w: failed to decrypt private key: %w", ErrDecryptionFailed, err)
 	}
 
-	return &Wallet{
-		Name:       encrypted.Name,
-		CoinType:   encrypted.CoinType,
-		Mnemonic:   string(mnemonicBytes),
-		PrivateKey: privateKey,
-		PublicKey:  encrypted.PublicKey,
-		Address:    encrypted.Address,
-		CreatedAt:  encrypted.CreatedAt,
-	}, nil
+	wallet := encrypted.metadata()
+	wallet.Mnemonic = string(mnemonicBytes)
+	wallet.Passphrase = passphrase
+	wallet.PrivateKey = privateKey
+
+	return wallet, nil
 }
 
-// encrypt encrypts data using AES-GCM
+// encrypt encrypts data using AES-GCM under the latest key version
+// The ciphertext is prefixed with the key version, e.g. "v2:<base64>"
 func (ss *StorageService) encrypt(plaintext []byte) (string, error) {
-	block, err := aes.NewCipher(ss.encryptionKey)
+	version, key, err := ss.latestKey()
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
@@ -274,17 +563,27 @@ func (ss *StorageService) encrypt(plaintext []byte) (string, error) {
 	}
 
 	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return formatCiphertext(version, base64.StdEncoding.EncodeToString(ciphertext)), nil
 }
 
-// decrypt decrypts data using AES-GCM
-func (ss *StorageService) decrypt(ciphertext string) ([]byte, error) {
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+// decrypt decrypts data using AES-GCM with the key version recorded in the ciphertext
+func (ss *StorageService) decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	version, encoded, err := parseCiphertext(ciphertext)
 	if err != nil {
 		return nil, err
 	}
 
-	block, err := aes.NewCipher(ss.encryptionKey)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ss.keyForVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -314,32 +613,18 @@ func (ss *StorageService) GetWalletMetadata(ctx context.Context, name string) (*
 		return nil, errors.New("wallet name cannot be empty")
 	}
 
-	entry, err := ss.storage.Get(ctx, "wallets/"+name)
+	encrypted, err := ss.getEncryptedWallet(ctx, name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve wallet: %w", err)
-	}
-	if entry == nil {
-		return nil, ErrWalletNotFound
-	}
-
-	var encrypted encryptedWallet
-	if err := json.Unmarshal(entry.Value, &encrypted); err != nil {
-		return nil, fmt.Errorf("failed to decode wallet: %w", err)
+		return nil, err
 	}
 
 	// Return wallet without decrypting sensitive fields
-	return &Wallet{
-		Name:      encrypted.Name,
-		CoinType:  encrypted.CoinType,
-		PublicKey: encrypted.PublicKey,
-		Address:   encrypted.Address,
-		CreatedAt: encrypted.CreatedAt,
-	}, nil
+	return encrypted.metadata(), nil
 }
 
-// ListWalletsWithMetadata returns wallet metadata for all wallets with pagination
-func (ss *StorageService) ListWalletsWithMetadata(ctx context.Context, offset, limit int) ([]*Wallet, error) {
-	names, err := ss.ListWallets(ctx, offset, limit)
+// ListWalletsWithMetadata returns the metadata of a page of the wallets matching a filter
+func (ss *StorageService) ListWalletsWithMetadata(ctx context.Context, filter WalletFilter, offset, limit int) ([]*Wallet, error) {
+	names, err := ss.FindWallets(ctx, filter, offset, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+)
+
+// WalletStore persists wallets and the mount state the wallet service reads
+// StorageService is the Vault-backed implementation; MemoryStore keeps everything
+// in memory and FaultStore injects failures into another store
+type WalletStore interface {
+	// StoreWallet stores a new wallet; it returns ErrWalletExists for a duplicate name
+	StoreWallet(ctx context.Context, wallet *Wallet) error
+	// GetWallet returns a wallet with its key material, or ErrWalletNotFound
+	GetWallet(ctx context.Context, name string) (*Wallet, error)
+	// GetWalletMetadata returns a wallet without its key material, or ErrWalletNotFound
+	GetWalletMetadata(ctx context.Context, name string) (*Wallet, error)
+	// UpdateWallet writes a wallet's mutable metadata, keeping its key material, and advances
+	// its Version; it returns ErrWalletNotFound, or ErrVersionConflict if Version is outdated
+	UpdateWallet(ctx context.Context, wallet *Wallet) error
+	// DeleteWallet removes a wallet and its address registry, or returns ErrWalletNotFound
+	DeleteWallet(ctx context.Context, name string) error
+	// ListWallets returns a page of wallet names; a non-positive limit returns the rest
+	ListWallets(ctx context.Context, offset, limit int) ([]string, error)
+	// FindWallets returns a page of the names of the wallets matching a filter, in lexical order
+	FindWallets(ctx context.Context, filter WalletFilter, offset, limit int) ([]string, error)
+	// ListWalletsWithMetadata returns the metadata of a page of the wallets matching a filter
+	ListWalletsWithMetadata(ctx context.Context, filter WalletFilter, offset, limit int) ([]*Wallet, error)
+
+	// GetMountConfig returns the mount-level settings
+	GetMountConfig(ctx context.Context) (*MountConfig, error)
+	// GetChain returns a chain profile, or ErrChainNotFound
+	GetChain(ctx context.Context, name string) (*ChainProfile, error)
+
+	// RecordAddresses registers addresses handed out by a wallet
+	RecordAddresses(ctx context.Context, records []*AddressRecord) error
+	// LookupAddress returns the records of the wallets that handed out an address, or ErrAddressNotFound
+	LookupAddress(ctx context.Context, address string) ([]*AddressRecord, error)
+	// ListAddressRecords returns the registry indexes of a wallet's addresses
+	ListAddressRecords(ctx context.Context, walletName string) ([]string, error)
+}
+
+var (
+	_ WalletStore = (*StorageService)(nil)
+	_ WalletStore = (*MemoryStore)(nil)
+	_ WalletStore = (*FaultStore)(nil)
+)
@@ -0,0 +1,488 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// testStores returns a fresh instance of each WalletStore implementation
+func testStores() map[string]func() WalletStore {
+	return map[string]func() WalletStore{
+		"vault": func() WalletStore {
+			return NewStorageService(&logical.InmemStorage{}, hclog.NewNullLogger())
+		},
+		"memory": func() WalletStore {
+			return NewMemoryStore()
+		},
+	}
+}
+
+func TestWalletStoreContract(t *testing.T) {
+	ctx := context.Background()
+
+	for name, newStore := range testStores() {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+
+			for _, walletName := range []string{"charlie", "alpha", "bravo"} {
+				if err := store.StoreWallet(ctx, testWallet(walletName)); err != nil {
+					t.Fatalf("StoreWallet(%s) error = %v", walletName, err)
+				}
+			}
+			if err := store.StoreWallet(ctx, testWallet("alpha")); !errors.Is(err, ErrWalletExists) {
+				t.Errorf("StoreWallet() duplicate error = %v, want %v", err, ErrWalletExists)
+			}
+
+			// Key material handed out is a copy the caller may clear
+			got, err := store.GetWallet(ctx, "alpha")
+			if err != nil {
+				t.Fatalf("GetWallet() error = %v", err)
+			}
+			for i := range got.PrivateKey {
+				got.PrivateKey[i] = 0
+			}
+			got, err = store.GetWallet(ctx, "alpha")
+			if err != nil {
+				t.Fatalf("GetWallet() error = %v", err)
+			}
+			if !bytes.Equal(got.PrivateKey, testWallet("alpha").PrivateKey) || got.Mnemonic != testWallet("alpha").Mnemonic {
+				t.Errorf("GetWallet() key material was not preserved")
+			}
+
+			metadata, err := store.GetWalletMetadata(ctx, "alpha")
+			if err != nil {
+				t.Fatalf("GetWalletMetadata() error = %v", err)
+			}
+			if metadata.Mnemonic != "" || metadata.PrivateKey != nil || metadata.Address != got.Address {
+				t.Errorf("GetWalletMetadata() = %+v, want public fields only", metadata)
+			}
+
+			// Updates write metadata only, and only from the current version
+			got.Description = "cold storage"
+			got.Tags = map[string]string{"env": "prod"}
+			got.CoinTypes = []uint32{60, 501}
+			got.Mnemonic = "changed"
+			if err := store.UpdateWallet(ctx, got); err != nil {
+				t.Fatalf("UpdateWallet() error = %v", err)
+			}
+			if got.Version != 1 || got.UpdatedAt.IsZero() {
+				t.Errorf("UpdateWallet() version = %d, updated_at = %v, want 1 and a timestamp", got.Version, got.UpdatedAt)
+			}
+			updated, err := store.GetWallet(ctx, "alpha")
+			if err != nil {
+				t.Fatalf("GetWallet() error = %v", err)
+			}
+			if updated.Description != "cold storage" || !reflect.DeepEqual(updated.Tags, got.Tags) || updated.Version != 1 {
+				t.Errorf("wallet after UpdateWallet() = %+v, want the new metadata at version 1", updated)
+			}
+			if updated.Mnemonic != testWallet("alpha").Mnemonic || len(updated.CoinTypes) != 0 {
+				t.Errorf("UpdateWallet() changed key material or coin types")
+			}
+
+			stale := testWallet("alpha")
+			stale.Owner = "someone else"
+			if err := store.UpdateWallet(ctx, stale); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("UpdateWallet() stale error = %v, want %v", err, ErrVersionConflict)
+			}
+			if err := store.UpdateWallet(ctx, testWallet("missing")); !errors.Is(err, ErrWalletNotFound) {
+				t.Errorf("UpdateWallet() missing error = %v, want %v", err, ErrWalletNotFound)
+			}
+
+			// Registry folders are not listed as wallets
+			if err := store.RecordAddresses(ctx, []*AddressRecord{{Wallet: "bravo", Address: "0xabc", CoinType: 60}}); err != nil {
+				t.Fatalf("RecordAddresses() error = %v", err)
+			}
+
+			pages := []struct {
+				offset, limit int
+				want          []string
+			}{
+				{offset: 0, limit: 0, want: []string{"alpha", "bravo", "charlie"}},
+				{offset: 1, limit: 1, want: []string{"bravo"}},
+				{offset: 2, limit: 5, want: []string{"charlie"}},
+				{offset: 3, limit: 1, want: []string{}},
+			}
+			for _, page := range pages {
+				names, err := store.ListWallets(ctx, page.offset, page.limit)
+				if err != nil {
+					t.Fatalf("ListWallets() error = %v", err)
+				}
+				if !reflect.DeepEqual(names, page.want) {
+					t.Errorf("ListWallets(%d, %d) = %v, want %v", page.offset, page.limit, names, page.want)
+				}
+			}
+
+			if err := store.DeleteWallet(ctx, "bravo"); err != nil {
+				t.Fatalf("DeleteWallet() error = %v", err)
+			}
+			if _, err := store.GetWallet(ctx, "bravo"); !errors.Is(err, ErrWalletNotFound) {
+				t.Errorf("GetWallet() after delete error = %v, want %v", err, ErrWalletNotFound)
+			}
+			if _, err := store.LookupAddress(ctx, "0xABC"); !errors.Is(err, ErrAddressNotFound) {
+				t.Errorf("LookupAddress() after delete error = %v, want %v", err, ErrAddressNotFound)
+			}
+			if err := store.DeleteWallet(ctx, "bravo"); !errors.Is(err, ErrWalletNotFound) {
+				t.Errorf("DeleteWallet() twice error = %v, want %v", err, ErrWalletNotFound)
+			}
+
+			if _, err := store.GetChain(ctx, "polygon"); !errors.Is(err, ErrChainNotFound) {
+				t.Errorf("GetChain() error = %v, want %v", err, ErrChainNotFound)
+			}
+			if config, err := store.GetMountConfig(ctx); err != nil || !config.CoinEnabled(501) {
+				t.Errorf("GetMountConfig() = %+v, %v, want an empty config", config, err)
+			}
+		})
+	}
+}
+
+func TestFindWallets(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	solana := uint32(501)
+
+	for name, newStore := range testStores() {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+
+			wallets := []*Wallet{
+				{Name: "hot-eth", CoinType: 60, Owner: "payments", Tags: map[string]string{"env": "prod", "tier": "hot"}, Labels: []string{"payouts"}},
+				{Name: "hot-sol", CoinType: 501, Owner: "payments", Tags: map[string]string{"env": "prod", "tier": "hot"}},
+				{Name: "cold-multi", CoinType: 60, CoinTypes: []uint32{60, 501}, Owner: "treasury", Tags: map[string]string{"env": "prod", "tier": "cold"}, Labels: []string{"payouts", "reserve"}},
+				{Name: "test-eth", CoinType: 60, Tags: map[string]string{"env": "a/b"}},
+			}
+			for i, wallet := range wallets {
+				wallet.Mnemonic = testWallet(wallet.Name).Mnemonic
+				wallet.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+				if err := store.StoreWallet(ctx, wallet); err != nil {
+					t.Fatalf("StoreWallet(%s) error = %v", wallet.Name, err)
+				}
+			}
+
+			tests := []struct {
+				name   string
+				filter WalletFilter
+				want   []string
+			}{
+				{name: "all", want: []string{"cold-multi", "hot-eth", "hot-sol", "test-eth"}},
+				{name: "coin", filter: WalletFilter{CoinType: &solana}, want: []string{"cold-multi", "hot-sol"}},
+				{name: "tag", filter: WalletFilter{Tags: map[string]string{"tier": "hot"}}, want: []string{"hot-eth", "hot-sol"}},
+				{name: "tags and coin", filter: WalletFilter{CoinType: &solana, Tags: map[string]string{"env": "prod", "tier": "hot"}}, want: []string{"hot-sol"}},
+				{name: "tag value with slash", filter: WalletFilter{Tags: map[string]string{"env": "a/b"}}, want: []string{"test-eth"}},
+				{name: "labels", filter: WalletFilter{Labels: []string{"payouts", "reserve"}}, want: []string{"cold-multi"}},
+				{name: "owner", filter: WalletFilter{Owner: "payments"}, want: []string{"hot-eth", "hot-sol"}},
+				{name: "owner and coin", filter: WalletFilter{CoinType: &solana, Owner: "treasury"}, want: []string{"cold-multi"}},
+				{name: "prefix", filter: WalletFilter{Prefix: "hot-"}, want: []string{"hot-eth", "hot-sol"}},
+				{name: "created after", filter: WalletFilter{CreatedAfter: base.Add(time.Hour)}, want: []string{"cold-multi", "test-eth"}},
+				{name: "created between", filter: WalletFilter{CreatedAfter: base, CreatedBefore: base.Add(3 * time.Hour)}, want: []string{"cold-multi", "hot-sol"}},
+				{name: "no match", filter: WalletFilter{Tags: map[string]string{"env": "staging"}}, want: []string{}},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					names, err := store.FindWallets(ctx, tt.filter, 0, 0)
+					if err != nil {
+						t.Fatalf("FindWallets() error = %v", err)
+					}
+					if !reflect.DeepEqual(names, tt.want) {
+						t.Errorf("FindWallets() = %v, want %v", names, tt.want)
+					}
+				})
+			}
+
+			// Updates and deletes keep the indexes in step
+			metadata, err := store.GetWalletMetadata(ctx, "hot-eth")
+			if err != nil {
+				t.Fatalf("GetWalletMetadata() error = %v", err)
+			}
+			metadata.Tags = map[string]string{"env": "prod", "tier": "cold"}
+			metadata.Owner = "treasury"
+			if err := store.UpdateWallet(ctx, metadata); err != nil {
+				t.Fatalf("UpdateWallet() error = %v", err)
+			}
+			if err := store.DeleteWallet(ctx, "hot-sol"); err != nil {
+				t.Fatalf("DeleteWallet() error = %v", err)
+			}
+
+			hot, err := store.FindWallets(ctx, WalletFilter{Tags: map[string]string{"tier": "hot"}}, 0, 0)
+			if err != nil || len(hot) != 0 {
+				t.Errorf("FindWallets(tier=hot) = %v, %v, want none", hot, err)
+			}
+			payments, err := store.FindWallets(ctx, WalletFilter{Owner: "payments"}, 0, 0)
+			if err != nil || len(payments) != 0 {
+				t.Errorf("FindWallets(owner=payments) = %v, %v, want none", payments, err)
+			}
+			cold, err := store.ListWalletsWithMetadata(ctx, WalletFilter{Tags: map[string]string{"tier": "cold"}}, 1, 1)
+			if err != nil || len(cold) != 1 || cold[0].Name != "hot-eth" || cold[0].Mnemonic != "" {
+				t.Errorf("ListWalletsWithMetadata(tier=cold, 1, 1) = %+v, %v, want hot-eth without key material", cold, err)
+			}
+		})
+	}
+}
+
+func TestWalletIndexesBuiltForExistingWallets(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	ss := NewStorageService(store, hclog.NewNullLogger())
+
+	wallet := testWallet("legacy")
+	wallet.Tags = map[string]string{"env": "prod"}
+	wallet.Owner = "payments"
+	if err := ss.StoreWallet(ctx, wallet); err != nil {
+		t.Fatalf("StoreWallet() error = %v", err)
+	}
+
+	// Drop the indexes, as if the wallet was stored before they were maintained
+	keys, err := logical.CollectKeysWithPrefix(ctx, store, "index/")
+	if err != nil {
+		t.Fatalf("CollectKeysWithPrefix() error = %v", err)
+	}
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete(%s) error = %v", key, err)
+		}
+	}
+
+	// Until the indexes are built, wallets are found by scanning them
+	restarted := NewStorageService(store, hclog.NewNullLogger())
+	names, err := restarted.FindWallets(ctx, WalletFilter{Tags: map[string]string{"env": "prod"}}, 0, 0)
+	if err != nil {
+		t.Fatalf("FindWallets() before build error = %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"legacy"}) {
+		t.Errorf("FindWallets() before build = %v, want [legacy]", names)
+	}
+	if entry, _ := store.Get(ctx, walletIndexVersionPath); entry != nil {
+		t.Fatalf("FindWallets() wrote the index version")
+	}
+
+	if err := restarted.BuildWalletIndexes(ctx); err != nil {
+		t.Fatalf("BuildWalletIndexes() error = %v", err)
+	}
+	if entry, _ := store.Get(ctx, tagIndexFolder("env", "prod")+"legacy"); entry == nil {
+		t.Errorf("tag index not built for the existing wallet")
+	}
+	if entry, _ := store.Get(ctx, walletIndexVersionPath); entry == nil {
+		t.Errorf("index version not recorded after the indexes were built")
+	}
+
+	names, err = restarted.FindWallets(ctx, WalletFilter{Tags: map[string]string{"env": "prod"}}, 0, 0)
+	if err != nil {
+		t.Fatalf("FindWallets() error = %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"legacy"}) {
+		t.Errorf("FindWallets() = %v, want [legacy]", names)
+	}
+
+	// Indexes of version 1 have no owner index and are built again
+	if err := store.Delete(ctx, ownerIndexFolder("payments")+"legacy"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	entry, err := logical.StorageEntryJSON(walletIndexVersionPath, walletIndexState{Version: 1})
+	if err != nil {
+		t.Fatalf("StorageEntryJSON() error = %v", err)
+	}
+	if err := store.Put(ctx, entry); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	upgraded := NewStorageService(store, hclog.NewNullLogger())
+	if err := upgraded.BuildWalletIndexes(ctx); err != nil {
+		t.Fatalf("BuildWalletIndexes() error = %v", err)
+	}
+	if entry, _ := store.Get(ctx, ownerIndexFolder("payments")+"legacy"); entry == nil {
+		t.Errorf("owner index not built when upgrading the indexes")
+	}
+}
+
+func TestFindWalletsSkipsStaleIndexEntries(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	ss := NewStorageService(store, hclog.NewNullLogger())
+	if err := ss.BuildWalletIndexes(ctx); err != nil {
+		t.Fatalf("BuildWalletIndexes() error = %v", err)
+	}
+
+	wallet := testWallet("kept")
+	wallet.CoinType = 60
+	wallet.Tags = map[string]string{"env": "prod"}
+	if err := ss.StoreWallet(ctx, wallet); err != nil {
+		t.Fatalf("StoreWallet() error = %v", err)
+	}
+
+	// Entries left by interrupted writes: a wallet that was never stored, and a tag "kept" no longer carries
+	for _, key := range []string{
+		coinIndexFolder(60) + "ghost",
+		tagIndexFolder("env", "prod") + "ghost",
+		tagIndexFolder("env", "staging") + "kept",
+	} {
+		if err := store.Put(ctx, &logical.StorageEntry{Key: key, Value: []byte("{}")}); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	coinType := uint32(60)
+	tests := []struct {
+		name   string
+		filter WalletFilter
+		want   []string
+	}{
+		{name: "coin", filter: WalletFilter{CoinType: &coinType}, want: []string{"kept"}},
+		{name: "tag", filter: WalletFilter{Tags: map[string]string{"env": "prod"}}, want: []string{"kept"}},
+		{name: "stale tag", filter: WalletFilter{Tags: map[string]string{"env": "staging"}}, want: []string{}},
+		{name: "coin and created", filter: WalletFilter{CoinType: &coinType, CreatedBefore: time.Now().Add(time.Hour)}, want: []string{"kept"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := ss.FindWallets(ctx, tt.filter, 0, 0)
+			if err != nil {
+				t.Fatalf("FindWallets() error = %v", err)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("FindWallets() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// Deleting a wallet removes its index entries after the wallet itself
+	if err := ss.DeleteWallet(ctx, "kept"); err != nil {
+		t.Fatalf("DeleteWallet() error = %v", err)
+	}
+	if entry, _ := store.Get(ctx, coinIndexFolder(60)+"kept"); entry != nil {
+		t.Errorf("coin index entry kept after DeleteWallet()")
+	}
+}
+
+func TestUpdateWalletKeepsCiphertext(t *testing.T) {
+	ctx := context.Background()
+	store := &logical.InmemStorage{}
+	ss := NewStorageService(store, hclog.NewNullLogger())
+
+	if err := ss.StoreWallet(ctx, testWallet("cold")); err != nil {
+		t.Fatalf("StoreWallet() error = %v", err)
+	}
+	before := readEncryptedWallet(t, store, "cold")
+
+	metadata, err := ss.GetWalletMetadata(ctx, "cold")
+	if err != nil {
+		t.Fatalf("GetWalletMetadata() error = %v", err)
+	}
+	metadata.Owner = "treasury"
+	metadata.Disabled = true
+	if err := ss.UpdateWallet(ctx, metadata); err != nil {
+		t.Fatalf("UpdateWallet() error = %v", err)
+	}
+
+	after := readEncryptedWallet(t, store, "cold")
+	if after.MnemonicEncrypted != before.MnemonicEncrypted || after.PrivateKeyEncrypted != before.PrivateKeyEncrypted {
+		t.Errorf("UpdateWallet() re-encrypted the key material")
+	}
+	if after.Owner != "treasury" || !after.Disabled || after.Version != 1 {
+		t.Errorf("stored wallet after UpdateWallet() = %+v", after)
+	}
+
+	// Rewrapping re-encrypts the keys but keeps the metadata
+	if _, err := ss.RotateKey(ctx); err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	if _, err := ss.rewrapWallet(ctx, "cold", 2); err != nil {
+		t.Fatalf("rewrapWallet() error = %v", err)
+	}
+	if rewrapped, _ := ss.GetWalletMetadata(ctx, "cold"); rewrapped.Owner != "treasury" || rewrapped.Version != 1 {
+		t.Errorf("metadata after rewrap = %+v", rewrapped)
+	}
+}
+
+// readEncryptedWallet decodes a wallet as stored, without decrypting it
+func readEncryptedWallet(t *testing.T, store logical.Storage, name string) *encryptedWallet {
+	t.Helper()
+
+	entry, err := store.Get(context.Background(), "wallets/"+name)
+	if err != nil || entry == nil {
+		t.Fatalf("Get(wallets/%s) entry = %v, error = %v", name, entry, err)
+	}
+	var encrypted encryptedWallet
+	if err := json.Unmarshal(entry.Value, &encrypted); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return &encrypted
+}
+
+func TestMemoryStoreAddressRegistry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	for _, name := range []string{"deposits", "twin"} {
+		if err := store.StoreWallet(ctx, testWallet(name)); err != nil {
+			t.Fatalf("StoreWallet() error = %v", err)
+		}
+	}
+
+	records := []*AddressRecord{
+		{Wallet: "twin", Address: "0xAbC0000000000000000000000000000000000001", CoinType: 60},
+		{Wallet: "deposits", Address: "0xabc0000000000000000000000000000000000001", CoinType: 60, Label: "customer-7"},
+		{Wallet: "deposits", Address: "0xabc0000000000000000000000000000000000002", CoinType: 60},
+	}
+	if err := store.RecordAddresses(ctx, records); err != nil {
+		t.Fatalf("RecordAddresses() error = %v", err)
+	}
+	if records[1].Index != 0 || records[2].Index != 1 {
+		t.Fatalf("indexes = %d, %d, want 0, 1", records[1].Index, records[2].Index)
+	}
+
+	// Re-recording keeps the index and label unless new ones are supplied
+	again := &AddressRecord{Wallet: "deposits", Address: records[1].Address, Metadata: map[string]string{"ref": "42"}}
+	if err := store.RecordAddresses(ctx, []*AddressRecord{again}); err != nil {
+		t.Fatalf("RecordAddresses() error = %v", err)
+	}
+	if again.Index != 0 || again.Label != "customer-7" || again.Metadata["ref"] != "42" {
+		t.Fatalf("re-recorded address = %+v", again)
+	}
+
+	owners, err := store.LookupAddress(ctx, "0xABC0000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatalf("LookupAddress() error = %v", err)
+	}
+	if len(owners) != 2 || owners[0].Wallet != "deposits" || owners[1].Wallet != "twin" {
+		t.Errorf("LookupAddress() = %+v, want deposits and twin", owners)
+	}
+
+	indexes, err := store.ListAddressRecords(ctx, "deposits")
+	if err != nil {
+		t.Fatalf("ListAddressRecords() error = %v", err)
+	}
+	if !reflect.DeepEqual(indexes, []string{"0", "1"}) {
+		t.Errorf("ListAddressRecords() = %v, want [0 1]", indexes)
+	}
+}
+
+func TestFaultStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewFaultStore(NewMemoryStore())
+	errUnavailable := errors.New("storage unavailable")
+
+	if err := store.StoreWallet(ctx, testWallet("faulty")); err != nil {
+		t.Fatalf("StoreWallet() error = %v", err)
+	}
+
+	store.Fail("GetWallet", errUnavailable)
+	if _, err := store.GetWallet(ctx, "faulty"); !errors.Is(err, errUnavailable) {
+		t.Errorf("GetWallet() error = %v, want %v", err, errUnavailable)
+	}
+
+	// Other operations still reach the wrapped store
+	if _, err := store.GetWalletMetadata(ctx, "faulty"); err != nil {
+		t.Errorf("GetWalletMetadata() error = %v", err)
+	}
+
+	store.Reset()
+	if _, err := store.GetWallet(ctx, "faulty"); err != nil {
+		t.Errorf("GetWallet() after Reset() error = %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Secondary indexes of wallets, each holding an empty entry per wallet: index/coin/<coin type>/<wallet>,
+// index/tag/<key>/<value>/<wallet>, index/label/<label>/<wallet> and index/owner/<owner>/<wallet>
+// Tag keys, tag values, labels and owners are base64url-encoded, so any text is a single path segment
+const (
+	coinIndexPrefix  = "index/coin/"
+	tagIndexPrefix   = "index/tag/"
+	labelIndexPrefix = "index/label/"
+	ownerIndexPrefix = "index/owner/"
+
+	// walletIndexVersionPath records that the indexes cover every stored wallet
+	// Version 2 added the owner index
+	walletIndexVersionPath = "index/version"
+	walletIndexVersion     = 2
+)
+
+// walletIndexState is stored at walletIndexVersionPath once the indexes are built
+type walletIndexState struct {
+	Version int `json:"version"`
+}
+
+// WalletFilter selects wallets to list; the zero value matches every wallet
+type WalletFilter struct {
+	// CoinType, when set, matches wallets enabled for the coin type
+	CoinType *uint32
+	// Tags matches wallets that carry every tag with the given value
+	Tags map[string]string
+	// Labels matches wallets that carry every label
+	Labels []string
+	// Owner, when set, matches wallets with this owner
+	Owner string
+	// Prefix matches wallet names starting with it
+	Prefix string
+	// CreatedAfter and CreatedBefore, when set, bound the creation time (exclusive)
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// Matches reports whether a wallet passes the filter
+func (f WalletFilter) Matches(wallet *Wallet) bool {
+	if f.CoinType != nil && !wallet.HasCoinType(*f.CoinType) {
+		return false
+	}
+	for key, value := range f.Tags {
+		if wallet.Tags[key] != value {
+			return false
+		}
+	}
+	for _, label := range f.Labels {
+		if !wallet.HasLabel(label) {
+			return false
+		}
+	}
+	if f.Owner != "" && wallet.Owner != f.Owner {
+		return false
+	}
+	return strings.HasPrefix(wallet.Name, f.Prefix) && f.matchesCreated(wallet.CreatedAt)
+}
+
+// matchesCreated reports whether a creation time is within the filter's bounds
+func (f WalletFilter) matchesCreated(createdAt time.Time) bool {
+	if !f.CreatedAfter.IsZero() && !createdAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !createdAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// indexFolders returns the index folders listing the wallets that may match the filter
+func (f WalletFilter) indexFolders() []string {
+	var folders []string
+	if f.CoinType != nil {
+		folders = append(folders, coinIndexFolder(*f.CoinType))
+	}
+	for key, value := range f.Tags {
+		folders = append(folders, tagIndexFolder(key, value))
+	}
+	for _, label := range f.Labels {
+		folders = append(folders, labelIndexFolder(label))
+	}
+	if f.Owner != "" {
+		folders = append(folders, ownerIndexFolder(f.Owner))
+	}
+	return folders
+}
+
+// coinIndexFolder returns the index folder of the wallets enabled for a coin type
+func coinIndexFolder(coinType uint32) string {
+	return coinIndexPrefix + strconv.FormatUint(uint64(coinType), 10) + "/"
+}
+
+// tagIndexFolder returns the index folder of the wallets carrying a tag
+func tagIndexFolder(key, value string) string {
+	return tagIndexPrefix + indexSegment(key) + "/" + indexSegment(value) + "/"
+}
+
+// labelIndexFolder returns the index folder of the wallets carrying a label
+func labelIndexFolder(label string) string {
+	return labelIndexPrefix + indexSegment(label) + "/"
+}
+
+// ownerIndexFolder returns the index folder of the wallets with an owner
+func ownerIndexFolder(owner string) string {
+	return ownerIndexPrefix + indexSegment(owner) + "/"
+}
+
+// indexSegment encodes text as a storage path segment
+func indexSegment(text string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(text))
+}
+
+// walletIndexKeys returns the index entries of a wallet; a nil wallet has none
+func walletIndexKeys(wallet *Wallet) map[string]bool {
+	keys := make(map[string]bool)
+	if wallet == nil {
+		return keys
+	}
+
+	for _, coinType := range wallet.EnabledCoinTypes() {
+		keys[coinIndexFolder(coinType)+wallet.Name] = true
+	}
+	for key, value := range wallet.Tags {
+		keys[tagIndexFolder(key, value)+wallet.Name] = true
+	}
+	for _, label := range wallet.Labels {
+		keys[labelIndexFolder(label)+wallet.Name] = true
+	}
+	if wallet.Owner != "" {
+		keys[ownerIndexFolder(wallet.Owner)+wallet.Name] = true
+	}
+
+	return keys
+}
+
+// addWalletIndexes writes the index entries a wallet gains moving from its previous to its current state
+// previous is nil for a new wallet. It runs before the wallet is written, so an indexed search never
+// misses a stored wallet; entries left by a failed write are skipped by FindWallets. Callers hold walletLock
+func (ss *StorageService) addWalletIndexes(ctx context.Context, previous, current *Wallet) error {
+	existing := walletIndexKeys(previous)
+	for key := range walletIndexKeys(current) {
+		if existing[key] {
+			continue
+		}
+		if err := ss.storage.Put(ctx, &logical.StorageEntry{Key: key, Value: []byte("{}")}); err != nil {
+			return fmt.Errorf("failed to store wallet index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeWalletIndexes deletes the index entries a wallet loses moving from its previous to its current state
+// current is nil for a deleted wallet. It runs after the wallet is written or deleted; deleting a missing
+// entry is not an error, so it can be repeated. Callers hold walletLock
+func (ss *StorageService) removeWalletIndexes(ctx context.Context, previous, current *Wallet) error {
+	kept := walletIndexKeys(current)
+	for key := range walletIndexKeys(previous) {
+		if kept[key] {
+			continue
+		}
+		if err := ss.storage.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete wallet index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// BuildWalletIndexes builds the indexes of wallets stored before they were maintained
+// It writes to storage, so it is run when the mount is initialized on a node that may write;
+// until the indexes are built, FindWallets scans every wallet
+func (ss *StorageService) BuildWalletIndexes(ctx context.Context) error {
+	ss.walletLock.Lock()
+	defer ss.walletLock.Unlock()
+
+	built, err := ss.walletIndexesBuilt(ctx)
+	if err != nil || built {
+		return err
+	}
+
+	names, err := ss.ListWallets(ctx, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	ss.logger.Info("building wallet indexes", "wallets", len(names))
+
+	for _, name := range names {
+		encrypted, err := ss.getEncryptedWallet(ctx, name)
+		if errors.Is(err, ErrWalletNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := ss.addWalletIndexes(ctx, nil, encrypted.metadata()); err != nil {
+			return err
+		}
+	}
+
+	entry, err := logical.StorageEntryJSON(walletIndexVersionPath, walletIndexState{Version: walletIndexVersion})
+	if err != nil {
+		return fmt.Errorf("failed to create storage entry: %w", err)
+	}
+	if err := ss.storage.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to store wallet index version: %w", err)
+	}
+
+	ss.walletIndexesReady.Store(true)
+
+	return nil
+}
+
+// walletIndexesBuilt reports whether the indexes cover every stored wallet
+// Indexes of an earlier version lack newer indexes and are built again
+// Once seen, the index version is remembered so later checks do not read storage
+func (ss *StorageService) walletIndexesBuilt(ctx context.Context) (bool, error) {
+	if ss.walletIndexesReady.Load() {
+		return true, nil
+	}
+
+	entry, err := ss.storage.Get(ctx, walletIndexVersionPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read wallet index version: %w", err)
+	}
+	if entry == nil {
+		return false, nil
+	}
+
+	var state walletIndexState
+	if err := entry.DecodeJSON(&state); err != nil {
+		return false, fmt.Errorf("failed to decode wallet index version: %w", err)
+	}
+	if state.Version < walletIndexVersion {
+		return false, nil
+	}
+
+	ss.walletIndexesReady.Store(true)
+
+	return true, nil
+}
+
+// FindWallets returns a page of the names of the wallets matching a filter, in lexical order
+// Coin type, tag, label and owner filters narrow the candidates through the secondary indexes; each
+// candidate is then checked against its stored metadata, so index entries left behind by a
+// failed write never match and wallets deleted meanwhile are skipped
+func (ss *StorageService) FindWallets(ctx context.Context, filter WalletFilter, offset, limit int) ([]string, error) {
+	built, err := ss.walletIndexesBuilt(ctx)
+	if err != nil {
+		ss.logger.Error("failed to check wallet indexes", "error", err)
+		return nil, err
+	}
+
+	var candidates []string
+	folders := filter.indexFolders()
+	if len(folders) == 0 || !built {
+		names, err := ss.ListWallets(ctx, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		candidates = names
+		folders = nil
+	}
+	for i, folder := range folders {
+		names, err := ss.storage.List(ctx, folder)
+		if err != nil {
+			ss.logger.Error("failed to list wallet index", "error", err)
+			return nil, fmt.Errorf("failed to list wallet index: %w", err)
+		}
+		if i == 0 {
+			candidates = names
+		} else {
+			candidates = intersectNames(candidates, names)
+		}
+	}
+
+	sort.Strings(candidates)
+
+	// Metadata is only read when the names alone cannot decide the filter
+	checkMetadata := len(filter.indexFolders()) > 0 || !filter.CreatedAfter.IsZero() || !filter.CreatedBefore.IsZero()
+
+	names := make([]string, 0, len(candidates))
+	for _, name := range candidates {
+		if !strings.HasPrefix(name, filter.Prefix) {
+			continue
+		}
+
+		if checkMetadata {
+			encrypted, err := ss.getEncryptedWallet(ctx, name)
+			if errors.Is(err, ErrWalletNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !filter.Matches(encrypted.metadata()) {
+				continue
+			}
+		}
+
+		names = append(names, name)
+	}
+
+	ss.logger.Debug("wallets found", "count", len(names))
+
+	return paginate(names, offset, limit), nil
+}
+
+// intersectNames returns the names present in both lists
+func intersectNames(a, b []string) []string {
+	present := make(map[string]bool, len(b))
+	for _, name := range b {
+		present[name] = true
+	}
+
+	var both []string
+	for _, name := range a {
+		if present[name] {
+			both = append(both, name)
+		}
+	}
+	return both
+}
@@ -0,0 +1,55 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddressForTypeKnownAnswers(t *testing.T) {
+	engine := testEngine(t)
+
+	// BIP44, BIP49, BIP84 and BIP86 test vectors for the test mnemonic at .../0'/0/0
+	tests := []struct {
+		addressType string
+		want        string
+	}{
+		{addressType: AddressTypeP2PKH, want: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{addressType: AddressTypeP2SHP2WPKH, want: "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"},
+		{addressType: AddressTypeP2WPKH, want: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{addressType: AddressTypeP2TR, want: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addressType, func(t *testing.T) {
+			purpose, err := AddressTypePurpose(CoinTypeBitcoin, tt.addressType)
+			if err != nil {
+				t.Fatalf("AddressTypePurpose() error = %v", err)
+			}
+			path, err := PurposePath(CoinTypeBitcoin, purpose, 0, 0, 0)
+			if err != nil {
+				t.Fatalf("PurposePath() error = %v", err)
+			}
+			keys, err := engine.DeriveKey(testMnemonic, "", CoinTypeBitcoin, path)
+			if err != nil {
+				t.Fatalf("DeriveKey() error = %v", err)
+			}
+
+			got, err := engine.AddressForType(keys.PublicKey, CoinTypeBitcoin, tt.addressType)
+			if err != nil {
+				t.Fatalf("AddressForType() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AddressForType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddressTypePurpose(t *testing.T) {
+	if _, err := AddressTypePurpose(CoinTypeEthereum, AddressTypeP2PKH); !errors.Is(err, ErrInvalidAddressType) {
+		t.Errorf("AddressTypePurpose(ethereum) error = %v, want %v", err, ErrInvalidAddressType)
+	}
+	if _, err := AddressTypePurpose(CoinTypeBitcoin, "p2wsh"); !errors.Is(err, ErrInvalidAddressType) {
+		t.Errorf("AddressTypePurpose(p2wsh) error = %v, want %v", err, ErrInvalidAddressType)
+	}
+}
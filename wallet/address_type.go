@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Bitcoin address types
+const (
+	AddressTypeP2PKH      = "p2pkh"       // Legacy, BIP44
+	AddressTypeP2SHP2WPKH = "p2sh-p2wpkh" // Nested SegWit, BIP49
+	AddressTypeP2WPKH     = "p2wpkh"      // Native SegWit, BIP84
+	AddressTypeP2TR       = "p2tr"        // Taproot, BIP86
+
+	// Detected only; P2SH may wrap P2WPKH or any other script
+	AddressTypeP2SH  = "p2sh"
+	AddressTypeP2WSH = "p2wsh"
+)
+
+// ErrInvalidAddressType is returned when an address type is unknown or not defined for a coin
+var ErrInvalidAddressType = errors.New("invalid address type")
+
+// addressTypePurposes maps each Bitcoin address type to its derivation purpose
+var addressTypePurposes = map[string]uint32{
+	AddressTypeP2PKH:      PurposeBIP44,
+	AddressTypeP2SHP2WPKH: PurposeBIP49,
+	AddressTypeP2WPKH:     PurposeBIP84,
+	AddressTypeP2TR:       PurposeBIP86,
+}
+
+// DefaultAddressType returns the address type used when none is requested
+// Only Bitcoin has address types; other coins return an empty string
+func DefaultAddressType(coinType uint32) string {
+	if coinType == CoinTypeBitcoin {
+		return AddressTypeP2WPKH
+	}
+	return ""
+}
+
+// AddressTypePurpose returns the derivation purpose paired with an address type
+func AddressTypePurpose(coinType uint32, addressType string) (uint32, error) {
+	purpose, ok := addressTypePurposes[addressType]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q (must be p2pkh, p2sh-p2wpkh, p2wpkh or p2tr)", ErrInvalidAddressType, addressType)
+	}
+	if coinType != CoinTypeBitcoin {
+		return 0, fmt.Errorf("%w: address types are only defined for Bitcoin", ErrInvalidAddressType)
+	}
+	return purpose, nil
+}
+
+// extendedKeyAddressType resolves the Bitcoin address type derived below an extended key
+// ypub and zpub keys imply P2SH-P2WPKH and P2WPKH. An xpub is exported for both P2PKH (purpose 44)
+// and Taproot (purpose 86), and the key does not record which, so its address type must be given
+func extendedKeyAddressType(xpub, addressType string) (string, error) {
+	var implied string
+	switch {
+	case strings.HasPrefix(xpub, "ypub"):
+		implied = AddressTypeP2SHP2WPKH
+	case strings.HasPrefix(xpub, "zpub"):
+		implied = AddressTypeP2WPKH
+	}
+
+	switch {
+	case addressType == "" && implied == "":
+		return "", fmt.Errorf("%w: an xpub is used for both p2pkh (purpose 44) and p2tr (purpose 86); set the address type", ErrInvalidAddressType)
+	case addressType == "":
+		return implied, nil
+	case implied != "" && addressType != implied:
+		return "", fmt.Errorf("%w: a %s key derives %s addresses, not %s", ErrInvalidAddressType, xpub[:4], implied, addressType)
+	}
+	return addressType, nil
+}
+
+// DetectAddressType returns the Bitcoin address type of a valid, normalized address
+// Other coins have no address types and return an empty string
+func DetectAddressType(coinType uint32, address string) string {
+	if coinType != CoinTypeBitcoin {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(address, "1"):
+		return AddressTypeP2PKH
+	case strings.HasPrefix(address, "3"):
+		return AddressTypeP2SH
+	case strings.HasPrefix(address, "bc1q") && len(address) == 42:
+		return AddressTypeP2WPKH
+	case strings.HasPrefix(address, "bc1q") && len(address) == 62:
+		return AddressTypeP2WSH
+	case strings.HasPrefix(address, "bc1p"):
+		return AddressTypeP2TR
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,201 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ErrInvalidSigningInput is returned when a SigningInput protobuf is malformed
+var ErrInvalidSigningInput = errors.New("invalid signing input")
+
+// SignedTransaction is the result of signing a chain-specific SigningInput
+type SignedTransaction struct {
+	// CoinType is the chain the transaction was signed for
+	CoinType uint32
+	// Output is the encoded chain-specific SigningOutput protobuf
+	Output []byte
+	// RawTx is the broadcast-ready transaction (hex for Bitcoin and Ethereum, base58 for Solana)
+	RawTx string
+	// TxHash is the transaction hash or ID used by block explorers
+	TxHash string
+}
+
+// anySignerSchema records the protobuf field numbers the plugin needs for a chain
+// See the Bitcoin.proto, Ethereum.proto and Solana.proto files in Trust Wallet Core
+type anySignerSchema struct {
+	inputPrivateKey    protowire.Number
+	outputEncoded      protowire.Number
+	outputTxID         protowire.Number // 0 when the hash is computed from the raw tx
+	outputError        protowire.Number
+	outputErrorMessage protowire.Number
+}
+
+// anySignerSchemas lists the chains supported for SigningInput signing
+var anySignerSchemas = map[uint32]anySignerSchema{
+	CoinTypeBitcoin: {
+		inputPrivateKey:    6,
+		outputEncoded:      2,
+		outputTxID:         3,
+		outputError:        4,
+		outputErrorMessage: 5,
+	},
+	CoinTypeEthereum: {
+		inputPrivateKey:    9,
+		outputEncoded:      1,
+		outputError:        6,
+		outputErrorMessage: 7,
+	},
+	CoinTypeSolana: {
+		inputPrivateKey:    1,
+		outputEncoded:      1,
+		outputError:        3,
+		outputErrorMessage: 4,
+	},
+}
+
+// injectPrivateKey removes any private key field from a serialized SigningInput
+// and appends the wallet's private key in its place
+func injectPrivateKey(input []byte, field protowire.Number, privateKey []byte) ([]byte, error) {
+	out := make([]byte, 0, len(input)+len(privateKey)+4)
+
+	for b := input; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSigningInput, protowire.ParseError(n))
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSigningInput, protowire.ParseError(m))
+		}
+
+		if num != field {
+			out = append(out, b[:n+m]...)
+		}
+		b = b[n+m:]
+	}
+
+	out = protowire.AppendTag(out, field, protowire.BytesType)
+	out = protowire.AppendBytes(out, privateKey)
+
+	return out, nil
+}
+
+// parseSigningOutput reads the signed transaction from a chain's SigningOutput protobuf
+// An error reported in the output is returned wrapping ErrSigningFailed
+func parseSigningOutput(coinType uint32, output []byte) (*SignedTransaction, error) {
+	schema, ok := anySignerSchemas[coinType]
+	if !ok {
+		return nil, fmt.Errorf("%w: signing input not supported for coin type %d", ErrInvalidCoinType, coinType)
+	}
+
+	fields, err := parseFields(output)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signing output: %v", ErrSigningFailed, err)
+	}
+
+	if code := fields.varint(schema.outputError); code != 0 {
+		return nil, fmt.Errorf("%w: %s (code %d)", ErrSigningFailed, fields.bytes(schema.outputErrorMessage), code)
+	}
+
+	encoded := fields.bytes(schema.outputEncoded)
+	if len(encoded) == 0 {
+		return nil, fmt.Errorf("%w: empty signed transaction", ErrSigningFailed)
+	}
+
+	signed := &SignedTransaction{CoinType: coinType, Output: output}
+
+	switch coinType {
+	case CoinTypeBitcoin:
+		signed.RawTx = hex.EncodeToString(encoded)
+		signed.TxHash = string(fields.bytes(schema.outputTxID))
+	case CoinTypeEthereum:
+		signed.RawTx = "0x" + hex.EncodeToString(encoded)
+		signed.TxHash = "0x" + hex.EncodeToString(keccak256(encoded))
+	case CoinTypeSolana:
+		signed.RawTx = string(encoded)
+		signed.TxHash, err = solanaTxHash(string(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSigningFailed, err)
+		}
+	}
+
+	return signed, nil
+}
+
+// protoFields holds the last occurrence of each top-level field of a protobuf message
+type protoFields map[protowire.Number][]byte
+
+// parseFields decodes the top-level fields of a serialized protobuf message
+func parseFields(message []byte) (protoFields, error) {
+	fields := protoFields{}
+
+	for b := message; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			return nil, protowire.ParseError(m)
+		}
+
+		switch typ {
+		case protowire.BytesType:
+			v, _ := protowire.ConsumeBytes(b)
+			fields[num] = v
+		case protowire.VarintType:
+			v, _ := protowire.ConsumeVarint(b)
+			fields[num] = protowire.AppendVarint(nil, v)
+		}
+		b = b[m:]
+	}
+
+	return fields, nil
+}
+
+// bytes returns a length-delimited field, or nil if it is absent
+func (f protoFields) bytes(num protowire.Number) []byte {
+	if num == 0 {
+		return nil
+	}
+	return f[num]
+}
+
+// varint returns a varint field, or 0 if it is absent
+func (f protoFields) varint(num protowire.Number) uint64 {
+	raw, ok := f[num]
+	if !ok || num == 0 {
+		return 0
+	}
+	v, n := protowire.ConsumeVarint(raw)
+	if n < 0 {
+		return 0
+	}
+	return v
+}
+
+// solanaTxHash returns the transaction ID of a signed Solana transaction,
+// which is the base58-encoded first signature
+func solanaTxHash(encoded string) (string, error) {
+	raw, err := base58Decode(encoded)
+	if err != nil {
+		// Newer Trust Wallet Core versions can emit base64 transactions
+		raw, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", errors.New("failed to decode signed solana transaction")
+		}
+	}
+
+	// Layout: compact-u16 signature count followed by 64-byte signatures
+	if len(raw) < 65 || raw[0] == 0 || raw[0]&0x80 != 0 {
+		return "", errors.New("signed solana transaction has no signature")
+	}
+
+	return base58Encode(raw[1:65]), nil
+}
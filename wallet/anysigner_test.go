@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// eip155SignedTx is the signed transaction of the EIP-155 example: nonce 9, 20 gwei,
+// 21000 gas, 1 ether to 0x3535...35 on chain 1, signed with the key 0x4646...46
+const eip155SignedTx = "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+
+// eip155TxHash is keccak256 of eip155SignedTx
+const eip155TxHash = "0x33469b22e9f636356c4160a87eb19df52b7412e8eac32a4a55ffe88ea8350788"
+
+func TestInjectPrivateKey(t *testing.T) {
+	privateKey := bytes.Repeat([]byte{0x46}, 32)
+
+	// chain_id=1, a caller-supplied private_key and to_address, in Ethereum.SigningInput numbering
+	var input []byte
+	input = appendBytesField(input, 1, []byte{0x01})
+	input = appendBytesField(input, 9, bytes.Repeat([]byte{0xff}, 32))
+	input = appendBytesField(input, 8, []byte("0x3535353535353535353535353535353535353535"))
+
+	var want []byte
+	want = appendBytesField(want, 1, []byte{0x01})
+	want = appendBytesField(want, 8, []byte("0x3535353535353535353535353535353535353535"))
+	want = appendBytesField(want, 9, privateKey)
+
+	got, err := injectPrivateKey(input, 9, privateKey)
+	if err != nil {
+		t.Fatalf("injectPrivateKey() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("injectPrivateKey() = %x, want %x", got, want)
+	}
+
+	// A truncated length-delimited field is rejected
+	if _, err := injectPrivateKey([]byte{0x0a, 0x05, 0x01}, 9, privateKey); !errors.Is(err, ErrInvalidSigningInput) {
+		t.Errorf("injectPrivateKey(truncated) error = %v, want %v", err, ErrInvalidSigningInput)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	// Field 1 "old" then "new", varint field 2 = 300, fixed32 field 3 (skipped)
+	message, _ := hex.DecodeString("0a036f6c640a036e657710ac021d01000000")
+
+	fields, err := parseFields(message)
+	if err != nil {
+		t.Fatalf("parseFields() error = %v", err)
+	}
+	if got := string(fields.bytes(1)); got != "new" {
+		t.Errorf("bytes(1) = %q, want the last occurrence %q", got, "new")
+	}
+	if got := fields.varint(2); got != 300 {
+		t.Errorf("varint(2) = %d, want 300", got)
+	}
+	if _, ok := fields[3]; ok {
+		t.Errorf("fixed32 field 3 was recorded")
+	}
+	if fields.bytes(0) != nil || fields.varint(4) != 0 {
+		t.Errorf("absent fields are not empty")
+	}
+
+	if _, err := parseFields([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Errorf("parseFields(truncated) error = nil, want an error")
+	}
+}
+
+func TestParseSigningOutput(t *testing.T) {
+	encoded, _ := hex.DecodeString(eip155SignedTx)
+
+	// Ethereum.SigningOutput: encoded=1, v=2, r=3, s=4; r and s must not be taken for the transaction
+	var ethereum []byte
+	ethereum = appendBytesField(ethereum, 1, encoded)
+	ethereum = appendBytesField(ethereum, 2, []byte{0x25})
+	ethereum = appendBytesField(ethereum, 3, encoded[len(encoded)-66:len(encoded)-34])
+	ethereum = appendBytesField(ethereum, 4, encoded[len(encoded)-32:])
+
+	// Bitcoin.SigningOutput: encoded=2, transaction_id=3
+	var bitcoin []byte
+	bitcoin = appendBytesField(bitcoin, 2, []byte{0x02, 0x00, 0x00, 0x00})
+	bitcoin = appendBytesField(bitcoin, 3, []byte("b2c3d4"))
+
+	// Solana.SigningOutput: encoded=1, a base58 transaction whose first signature is the ID
+	signature := bytes.Repeat([]byte{0x07}, 64)
+	solanaTx := base58Encode(append(append([]byte{0x01}, signature...), 0x01, 0x00, 0x01))
+	solana := appendBytesField(nil, 1, []byte(solanaTx))
+
+	// Ethereum error=6 (varint), error_message=7
+	var failed []byte
+	failed = protowire.AppendTag(failed, 6, protowire.VarintType)
+	failed = protowire.AppendVarint(failed, 15)
+	failed = appendBytesField(failed, 7, []byte("insufficient funds"))
+
+	tests := []struct {
+		name       string
+		coinType   uint32
+		output     []byte
+		wantRawTx  string
+		wantTxHash string
+		wantErr    string
+	}{
+		{name: "ethereum eip155", coinType: CoinTypeEthereum, output: ethereum, wantRawTx: "0x" + eip155SignedTx, wantTxHash: eip155TxHash},
+		{name: "bitcoin", coinType: CoinTypeBitcoin, output: bitcoin, wantRawTx: "02000000", wantTxHash: "b2c3d4"},
+		{name: "solana", coinType: CoinTypeSolana, output: solana, wantRawTx: solanaTx, wantTxHash: base58Encode(signature)},
+		{name: "signer error", coinType: CoinTypeEthereum, output: failed, wantErr: "insufficient funds (code 15)"},
+		{name: "empty transaction", coinType: CoinTypeEthereum, output: appendBytesField(nil, 4, []byte{0x01}), wantErr: "empty signed transaction"},
+		{name: "unsupported coin", coinType: 118, output: ethereum, wantErr: "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := parseSigningOutput(tt.coinType, tt.output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseSigningOutput() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSigningOutput() error = %v", err)
+			}
+			if signed.RawTx != tt.wantRawTx || signed.TxHash != tt.wantTxHash {
+				t.Errorf("parseSigningOutput() = %s / %s, want %s / %s", signed.RawTx, signed.TxHash, tt.wantRawTx, tt.wantTxHash)
+			}
+			if signed.CoinType != tt.coinType {
+				t.Errorf("parseSigningOutput() coin type = %d, want %d", signed.CoinType, tt.coinType)
+			}
+			if !bytes.Equal(signed.Output, tt.output) {
+				t.Errorf("parseSigningOutput() did not keep the output")
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package wallet
+
+// DerivedAddress is the public key and address at a derivation path
+type DerivedAddress struct {
+	Path      string
+	PublicKey []byte
+	Address   string
+}
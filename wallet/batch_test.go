@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"testing"
+)
+
+func TestDeriveAddressesMatchesDeriveKey(t *testing.T) {
+	engine := testEngine(t)
+
+	for _, coinType := range []uint32{CoinTypeBitcoin, CoinTypeEthereum, CoinTypeSolana} {
+		var paths []string
+		for index := uint32(0); index < 3; index++ {
+			path, err := BIP44Path(coinType, 0, 0, index)
+			if err != nil {
+				t.Fatalf("BIP44Path() error = %v", err)
+			}
+			paths = append(paths, path)
+		}
+
+		addresses, err := engine.DeriveAddresses(testMnemonic, "", coinType, "", paths)
+		if err != nil {
+			t.Fatalf("DeriveAddresses(%d) error = %v", coinType, err)
+		}
+		if len(addresses) != len(paths) {
+			t.Fatalf("DeriveAddresses(%d) returned %d addresses, want %d", coinType, len(addresses), len(paths))
+		}
+
+		for i, path := range paths {
+			keys, err := engine.DeriveKey(testMnemonic, "", coinType, path)
+			if err != nil {
+				t.Fatalf("DeriveKey() error = %v", err)
+			}
+			if addresses[i].Path != path || addresses[i].Address != keys.Address {
+				t.Errorf("DeriveAddresses(%d)[%d] = %s at %s, want %s at %s", coinType, i, addresses[i].Address, addresses[i].Path, keys.Address, path)
+			}
+			if GetPublicKeyHex(addresses[i].PublicKey) != GetPublicKeyHex(keys.PublicKey) {
+				t.Errorf("DeriveAddresses(%d)[%d] public key mismatch", coinType, i)
+			}
+		}
+	}
+}
+
+func TestDeriveAddressesAddressType(t *testing.T) {
+	engine := testEngine(t)
+
+	addresses, err := engine.DeriveAddresses(testMnemonic, "", CoinTypeBitcoin, AddressTypeP2PKH, []string{"m/44'/0'/0'/0/0"})
+	if err != nil {
+		t.Fatalf("DeriveAddresses() error = %v", err)
+	}
+	if want := "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"; addresses[0].Address != want {
+		t.Errorf("DeriveAddresses() = %s, want %s", addresses[0].Address, want)
+	}
+
+	if _, err := engine.DeriveAddresses(testMnemonic, "", CoinTypeEthereum, AddressTypeP2WPKH, []string{"m/44'/60'/0'/0/0"}); err == nil {
+		t.Error("DeriveAddresses() with a Bitcoin address type for Ethereum should fail")
+	}
+}
@@ -0,0 +1,79 @@
+//go:build !trustwalletcore
+
+package wallet
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// coinList is the coin registry of builds without Trust Wallet Core: the chains
+// the pure-Go engine supports, with Trust Wallet Core's metadata for them
+var coinList = []CoinInfo{
+	{CoinType: CoinTypeBitcoin, ID: "bitcoin", Name: "Bitcoin", Symbol: "BTC", Decimals: 8, Curve: CurveSecp256k1, DerivationPath: "m/84'/0'/0'/0/0"},
+	{CoinType: CoinTypeEthereum, ID: "ethereum", Name: "Ethereum", Symbol: "ETH", Decimals: 18, Curve: CurveSecp256k1, DerivationPath: "m/44'/60'/0'/0/0"},
+	{CoinType: CoinTypeSolana, ID: "solana", Name: "Solana", Symbol: "SOL", Decimals: 9, Curve: CurveEd25519, DerivationPath: "m/44'/501'/0'"},
+}
+
+// Coins returns the chains supported by the pure-Go engine, ordered by coin type
+func Coins() []CoinInfo {
+	coins := make([]CoinInfo, len(coinList))
+	copy(coins, coinList)
+	return coins
+}
+
+// LookupCoin returns the registry entry of a coin type
+func LookupCoin(coinType uint32) (CoinInfo, bool) {
+	for _, info := range coinList {
+		if info.CoinType == coinType {
+			return info, true
+		}
+	}
+	return CoinInfo{}, false
+}
+
+// IsValidAddress reports whether an address is valid on the coin's chain
+func (c CoinInfo) IsValidAddress(address string) bool {
+	_, ok := c.NormalizeAddress(address)
+	return ok
+}
+
+// NormalizeAddress returns the canonical form of an address on the coin's chain,
+// e.g. EIP-55 checksummed for EVM chains; ok is false if the address is invalid
+func (c CoinInfo) NormalizeAddress(address string) (normalized string, ok bool) {
+	switch c.CoinType {
+	case CoinTypeBitcoin:
+		if payload, err := base58CheckDecode(address); err == nil {
+			if len(payload) == 21 && (payload[0] == bitcoinP2PKHPrefix || payload[0] == bitcoinP2SHPrefix) {
+				return address, true
+			}
+			return "", false
+		}
+		version, program, err := decodeSegwitAddress(bitcoinHRP, address)
+		if err != nil {
+			return "", false
+		}
+		normalized, err := encodeSegwitAddress(bitcoinHRP, version, program)
+		return normalized, err == nil
+
+	case CoinTypeEthereum:
+		if !strings.HasPrefix(address, "0x") || len(address) != 42 {
+			return "", false
+		}
+		raw, err := hex.DecodeString(address[2:])
+		if err != nil {
+			return "", false
+		}
+		return eip55(raw), true
+
+	case CoinTypeSolana:
+		raw, err := base58Decode(address)
+		if err != nil || len(raw) != 32 {
+			return "", false
+		}
+		return address, true
+
+	default:
+		return "", false
+	}
+}
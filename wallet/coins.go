@@ -0,0 +1,213 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedCoin is returned when a coin cannot be resolved against the coin registry
+var ErrUnsupportedCoin = errors.New("unsupported coin")
+
+// CoinInfo describes a chain supported by Trust Wallet Core
+type CoinInfo struct {
+	CoinType       uint32
+	ID             string
+	Name           string
+	Symbol         string
+	Decimals       int
+	Curve          string
+	DerivationPath string
+}
+
+// knownCoinTypes lists the TWCoinType values probed when the coin registry is built
+// Values Trust Wallet Core does not know are dropped, so this list may safely
+// run ahead of the bundled wallet-core version
+var knownCoinTypes = []uint32{
+	0,          // Bitcoin
+	2,          // Litecoin
+	3,          // Dogecoin
+	5,          // Dash
+	14,         // Viacoin
+	17,         // Groestlcoin
+	20,         // DigiByte
+	22,         // Monacoin
+	42,         // Decred
+	57,         // Syscoin
+	60,         // Ethereum
+	61,         // Ethereum Classic
+	74,         // ICON
+	77,         // Verge
+	118,        // Cosmos Hub
+	119,        // PIVX
+	121,        // Horizen
+	133,        // Zcash
+	136,        // Firo
+	141,        // Komodo
+	144,        // XRP
+	145,        // Bitcoin Cash
+	148,        // Stellar
+	156,        // Bitcoin Gold
+	165,        // Nano
+	175,        // Ravencoin
+	178,        // POA Network
+	194,        // EOS
+	195,        // Tron
+	235,        // FIO
+	242,        // Nimiq
+	283,        // Algorand
+	291,        // IOST
+	304,        // IoTeX
+	309,        // Nervos
+	313,        // Zilliqa
+	330,        // Terra Classic
+	354,        // Polkadot
+	394,        // Cronos POS
+	396,        // Everscale
+	397,        // NEAR
+	425,        // Aion
+	434,        // Kusama
+	457,        // Aeternity
+	459,        // Kava
+	461,        // Filecoin
+	474,        // Oasis
+	483,        // Bluzelle
+	494,        // BandChain
+	500,        // Theta
+	501,        // Solana
+	508,        // MultiversX
+	529,        // Secret
+	564,        // Agoric
+	607,        // TON
+	637,        // Aptos
+	714,        // BNB Beacon Chain
+	784,        // Sui
+	818,        // VeChain
+	820,        // Callisto
+	888,        // NEO
+	889,        // Viction
+	899,        // eCash
+	931,        // THORChain
+	966,        // Polygon
+	996,        // OKX Chain
+	1001,       // ThunderCore
+	1023,       // Harmony
+	1024,       // Ontology
+	1729,       // Tezos
+	1815,       // Cardano
+	2017,       // Kin
+	2301,       // Qtum
+	2718,       // Nebulas
+	3030,       // Hedera
+	6060,       // GoChain
+	8453,       // Base
+	8964,       // NULS
+	14001,      // WAX
+	18000,      // Meter
+	19167,      // Flux
+	52752,      // Celo
+	59144,      // Linea
+	105105,     // Stratis
+	534352,     // Scroll
+	1001088,    // Metis
+	5718350,    // Wanchain
+	5741564,    // Waves
+	10000025,   // Cronos
+	10000060,   // Injective
+	10000070,   // Optimism
+	10000100,   // Gnosis Chain
+	10000118,   // Osmosis
+	10000145,   // Smart Bitcoin Cash
+	10000250,   // Fantom
+	10000288,   // Boba
+	10000321,   // KuCoin Community Chain
+	10000324,   // zkSync Era
+	10000330,   // Terra
+	10000553,   // ECO Chain
+	10000714,   // BNB Smart Chain (legacy)
+	10001101,   // Polygon zkEVM
+	10001284,   // Moonbeam
+	10001285,   // Moonriver
+	10002020,   // Ronin
+	10002222,   // Kava EVM
+	10008217,   // Kaia
+	10009000,   // Avalanche C-Chain
+	10009001,   // Evmos
+	10042221,   // Arbitrum
+	20000714,   // BNB Smart Chain
+	20009001,   // Native Evmos
+	1323161554, // Aurora
+}
+
+// AddressValidation is the result of checking an address against a coin's chain
+type AddressValidation struct {
+	Valid bool
+	// Normalized is the canonical form of a valid address (EIP-55 for EVM chains)
+	Normalized string
+	// AddressType is the detected Bitcoin address type; empty for other coins
+	AddressType string
+	// Reason explains why an address is invalid
+	Reason string
+}
+
+// ValidateAddress checks that an address is well-formed for the coin
+// Mixed-case hex addresses must match the chain's checksum (EIP-55); all-lowercase
+// and all-uppercase ones carry no checksum and are accepted
+func (c CoinInfo) ValidateAddress(address string) AddressValidation {
+	normalized, ok := c.NormalizeAddress(address)
+	if !ok || !c.IsValidAddress(address) {
+		return AddressValidation{Reason: fmt.Sprintf("not a valid %s address", c.Name)}
+	}
+
+	if strings.HasPrefix(address, "0x") && address != normalized && strings.EqualFold(address, normalized) {
+		body := address[2:]
+		if body != strings.ToLower(body) && body != strings.ToUpper(body) {
+			return AddressValidation{Normalized: normalized, Reason: "checksum mismatch (EIP-55)"}
+		}
+	}
+
+	return AddressValidation{
+		Valid:       true,
+		Normalized:  normalized,
+		AddressType: DetectAddressType(c.CoinType, normalized),
+	}
+}
+
+// ResolveCoin resolves a coin given as a SLIP-44 coin type, a Trust Wallet Core
+// coin ID, a ticker symbol or a name, all matched case-insensitively
+// Symbols shared by several chains (e.g. ETH) resolve to the lowest coin type
+func ResolveCoin(coin string) (CoinInfo, error) {
+	coin = strings.TrimSpace(coin)
+	if coin == "" {
+		return CoinInfo{}, fmt.Errorf("%w: empty coin", ErrUnsupportedCoin)
+	}
+
+	if coinType, err := strconv.ParseUint(coin, 10, 32); err == nil {
+		if info, ok := LookupCoin(uint32(coinType)); ok {
+			return info, nil
+		}
+		return CoinInfo{}, fmt.Errorf("%w: %s", ErrUnsupportedCoin, coin)
+	}
+
+	coins := Coins()
+	for _, match := range []func(CoinInfo) string{
+		func(c CoinInfo) string { return c.ID },
+		func(c CoinInfo) string { return c.Symbol },
+		func(c CoinInfo) string { return c.Name },
+	} {
+		for _, info := range coins {
+			if strings.EqualFold(match(info), coin) {
+				return info, nil
+			}
+		}
+	}
+
+	return CoinInfo{}, fmt.Errorf("%w: %s", ErrUnsupportedCoin, coin)
+}
+
+// CoinSymbol returns the ticker symbol of a coin type, or an empty string if it is not in the registry
+func CoinSymbol(coinType uint32) string {
+	info, _ := LookupCoin(coinType)
+	return info.Symbol
+}
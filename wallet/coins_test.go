@@ -0,0 +1,132 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCoinRegistry(t *testing.T) {
+	tests := []struct {
+		coinType       uint32
+		symbol         string
+		decimals       int
+		curve          string
+		derivationPath string
+	}{
+		{coinType: CoinTypeBitcoin, symbol: "BTC", decimals: 8, curve: CurveSecp256k1, derivationPath: "m/84'/0'/0'/0/0"},
+		{coinType: CoinTypeEthereum, symbol: "ETH", decimals: 18, curve: CurveSecp256k1, derivationPath: "m/44'/60'/0'/0/0"},
+		{coinType: CoinTypeSolana, symbol: "SOL", decimals: 9, curve: CurveEd25519, derivationPath: "m/44'/501'/0'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			coin, ok := LookupCoin(tt.coinType)
+			if !ok {
+				t.Fatalf("LookupCoin(%d) not found", tt.coinType)
+			}
+			if coin.Symbol != tt.symbol || coin.Decimals != tt.decimals || coin.Curve != tt.curve || coin.DerivationPath != tt.derivationPath {
+				t.Errorf("LookupCoin(%d) = %+v", tt.coinType, coin)
+			}
+		})
+	}
+
+	if _, ok := LookupCoin(999999999); ok {
+		t.Error("LookupCoin() found an unknown coin type")
+	}
+
+	coins := Coins()
+	for i := 1; i < len(coins); i++ {
+		if coins[i-1].CoinType >= coins[i].CoinType {
+			t.Fatalf("Coins() not ordered by coin type at %d", i)
+		}
+	}
+}
+
+func TestCoinIsValidAddress(t *testing.T) {
+	coin, ok := LookupCoin(CoinTypeEthereum)
+	if !ok {
+		t.Fatal("Ethereum missing from coin registry")
+	}
+
+	if !coin.IsValidAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94") {
+		t.Error("IsValidAddress() rejected a valid address")
+	}
+	if coin.IsValidAddress("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu") {
+		t.Error("IsValidAddress() accepted a Bitcoin address")
+	}
+}
+
+func TestResolveCoin(t *testing.T) {
+	tests := []struct {
+		coin    string
+		want    uint32
+		wantErr bool
+	}{
+		{coin: "60", want: CoinTypeEthereum},
+		{coin: "ETH", want: CoinTypeEthereum},
+		{coin: "eth", want: CoinTypeEthereum},
+		{coin: "bitcoin", want: CoinTypeBitcoin},
+		{coin: " Bitcoin ", want: CoinTypeBitcoin},
+		{coin: "SOL", want: CoinTypeSolana},
+		{coin: "999999999", wantErr: true},
+		{coin: "-1", wantErr: true},
+		{coin: "dogecoin-classic", wantErr: true},
+		{coin: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.coin, func(t *testing.T) {
+			got, err := ResolveCoin(tt.coin)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedCoin) {
+					t.Fatalf("ResolveCoin(%q) error = %v, want %v", tt.coin, err, ErrUnsupportedCoin)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveCoin(%q) error = %v", tt.coin, err)
+			}
+			if got.CoinType != tt.want {
+				t.Errorf("ResolveCoin(%q) = %d, want %d", tt.coin, got.CoinType, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoinValidateAddress(t *testing.T) {
+	tests := []struct {
+		name            string
+		coinType        uint32
+		address         string
+		wantValid       bool
+		wantNormalized  string
+		wantAddressType string
+	}{
+		{name: "eip55", coinType: CoinTypeEthereum, address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", wantValid: true, wantNormalized: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{name: "lowercase hex", coinType: CoinTypeEthereum, address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", wantValid: true, wantNormalized: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{name: "bad checksum", coinType: CoinTypeEthereum, address: "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", wantNormalized: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{name: "short hex", coinType: CoinTypeEthereum, address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA"},
+		{name: "p2pkh", coinType: CoinTypeBitcoin, address: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", wantValid: true, wantNormalized: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", wantAddressType: AddressTypeP2PKH},
+		{name: "p2sh", coinType: CoinTypeBitcoin, address: "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf", wantValid: true, wantNormalized: "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf", wantAddressType: AddressTypeP2SH},
+		{name: "p2wpkh", coinType: CoinTypeBitcoin, address: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", wantValid: true, wantNormalized: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", wantAddressType: AddressTypeP2WPKH},
+		{name: "p2tr", coinType: CoinTypeBitcoin, address: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", wantValid: true, wantNormalized: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", wantAddressType: AddressTypeP2TR},
+		{name: "ethereum address on bitcoin", coinType: CoinTypeBitcoin, address: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coin, ok := LookupCoin(tt.coinType)
+			if !ok {
+				t.Fatalf("LookupCoin(%d) not found", tt.coinType)
+			}
+
+			got := coin.ValidateAddress(tt.address)
+			if got.Valid != tt.wantValid || got.Normalized != tt.wantNormalized || got.AddressType != tt.wantAddressType {
+				t.Errorf("ValidateAddress(%q) = %+v", tt.address, got)
+			}
+			if !got.Valid && got.Reason == "" {
+				t.Errorf("ValidateAddress(%q) gave no reason", tt.address)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hardenedOffset is the first hardened BIP32 child index
+const hardenedOffset = 1 << 31
+
+// ErrInvalidDerivationPath is returned when a derivation path or its components are invalid
+var ErrInvalidDerivationPath = errors.New("invalid derivation path")
+
+// formatBIP44Path formats m/purpose'/coin'/account'/change/address_index
+func formatBIP44Path(purpose, coinType, account, change, addressIndex uint32) (string, error) {
+	if account >= hardenedOffset {
+		return "", fmt.Errorf("%w: account must be below %d", ErrInvalidDerivationPath, uint32(hardenedOffset))
+	}
+	if change > 1 {
+		return "", fmt.Errorf("%w: change must be 0 or 1", ErrInvalidDerivationPath)
+	}
+	if addressIndex >= hardenedOffset {
+		return "", fmt.Errorf("%w: address index must be below %d", ErrInvalidDerivationPath, uint32(hardenedOffset))
+	}
+
+	return fmt.Sprintf("m/%d'/%d'/%d'/%d/%d", purpose, coinType, account, change, addressIndex), nil
+}
+
+// formatHardenedPath formats m/purpose'/coin'/address_index' for a chain that only supports
+// hardened derivation, where every address is its own account (the Trust Wallet Solana layout)
+// Address index 0 is the coin's default key. Such chains have no separate account or change level
+func formatHardenedPath(purpose, coinType, account, change, addressIndex uint32) (string, error) {
+	if account != 0 || change != 0 {
+		return "", fmt.Errorf("%w: coin type %d has one account per address; select it with the address index", ErrInvalidDerivationPath, coinType)
+	}
+	if addressIndex >= hardenedOffset {
+		return "", fmt.Errorf("%w: address index must be below %d", ErrInvalidDerivationPath, uint32(hardenedOffset))
+	}
+
+	return fmt.Sprintf("m/%d'/%d'/%d'", purpose, coinType, addressIndex), nil
+}
+
+// pathPurpose returns the purpose level of a derivation path such as m/84'/0'/0'/0/0
+func pathPurpose(path string) (uint32, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] != "m" {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidDerivationPath, path)
+	}
+
+	purpose, err := strconv.ParseUint(strings.TrimSuffix(parts[1], "'"), 10, 31)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidDerivationPath, path)
+	}
+
+	return uint32(purpose), nil
+}
+
+// Derivation purposes of the BIP44 family
+const (
+	PurposeBIP44 uint32 = 44 // P2PKH (legacy)
+	PurposeBIP49 uint32 = 49 // P2SH-P2WPKH (nested SegWit)
+	PurposeBIP84 uint32 = 84 // P2WPKH (native SegWit)
+	PurposeBIP86 uint32 = 86 // P2TR (Taproot)
+)
+
+// validatePurpose checks that a purpose can be used with a coin type
+// Only Bitcoin defines the SegWit and Taproot purposes
+func validatePurpose(coinType uint32, purpose uint32) error {
+	switch purpose {
+	case PurposeBIP44:
+		return nil
+	case PurposeBIP49, PurposeBIP84, PurposeBIP86:
+		if coinType != CoinTypeBitcoin {
+			return fmt.Errorf("%w: purpose %d is only defined for Bitcoin", ErrInvalidDerivationPath, purpose)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: purpose must be 44, 49, 84 or 86", ErrInvalidDerivationPath)
+	}
+}
+
+// BIP44Path builds a derivation path for a coin from account, change and address index
+// The purpose comes from the coin's default path (e.g. 84 for Bitcoin, 44 for Ethereum)
+// Chains on Ed25519 only support hardened derivation and use formatHardenedPath's layout
+func BIP44Path(coinType uint32, account, change, addressIndex uint32) (string, error) {
+	purpose, err := DefaultPurpose(coinType)
+	if err != nil {
+		return "", err
+	}
+
+	return PurposePath(coinType, purpose, account, change, addressIndex)
+}
+
+// PurposePath builds m/purpose'/coin'/account'/change/address_index for an explicit purpose,
+// such as the purpose paired with a Bitcoin address type
+func PurposePath(coinType uint32, purpose uint32, account, change, addressIndex uint32) (string, error) {
+	if _, ok := LookupCoin(coinType); !ok {
+		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if hardenedOnly(coinType) {
+		return formatHardenedPath(purpose, coinType, account, change, addressIndex)
+	}
+
+	return formatBIP44Path(purpose, coinType, account, change, addressIndex)
+}
+
+// DefaultPurpose returns the purpose of a coin's default derivation path
+func DefaultPurpose(coinType uint32) (uint32, error) {
+	defaultPath, err := DefaultDerivationPath(coinType)
+	if err != nil {
+		return 0, err
+	}
+
+	purpose, err := pathPurpose(defaultPath)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrAddressDerivation, err)
+	}
+
+	return purpose, nil
+}
+
+// DefaultDerivationPath returns the path of the key used when no derivation path is given
+func DefaultDerivationPath(coinType uint32) (string, error) {
+	info, ok := LookupCoin(coinType)
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if info.DerivationPath == "" {
+		return "", fmt.Errorf("%w: no default derivation path for coin type %d", ErrAddressDerivation, coinType)
+	}
+
+	return info.DerivationPath, nil
+}
+
+// Curve returns the name of the elliptic curve used by the coin type
+func Curve(coinType uint32) (string, error) {
+	info, ok := LookupCoin(coinType)
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+	return info.Curve, nil
+}
+
+// hardenedOnly reports whether a coin's curve only supports hardened derivation
+func hardenedOnly(coinType uint32) bool {
+	info, _ := LookupCoin(coinType)
+	switch info.Curve {
+	case CurveEd25519, CurveEd25519Blake2bNano, CurveCurve25519:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,279 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // required for Bitcoin HASH160
+	"golang.org/x/crypto/sha3"
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// bech32Charset is the BIP173 data character set
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Checksum constants of bech32 (witness version 0) and bech32m (version 1+, BIP350)
+const (
+	bech32Const  uint32 = 1
+	bech32mConst uint32 = 0x2bc830a3
+)
+
+var errInvalidBech32 = errors.New("invalid bech32 address")
+
+// keccak256 hashes data with the legacy Keccak-256 used by Ethereum
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// doubleSHA256 returns SHA-256(SHA-256(data))
+func doubleSHA256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// hash160 returns RIPEMD-160(SHA-256(data))
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
+
+// base58Encode encodes data with the Bitcoin base58 alphabet
+func base58Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return string(out)
+}
+
+// base58Decode decodes a string encoded with the Bitcoin base58 alphabet
+func base58Decode(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("invalid base58 string")
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base58Alphabet, s[i])
+		if digit < 0 {
+			return nil, errors.New("invalid base58 string")
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// base58CheckEncode encodes a payload with a 4-byte double SHA-256 checksum
+func base58CheckEncode(payload []byte) string {
+	checksum := doubleSHA256(payload)[:4]
+	return base58Encode(append(append([]byte{}, payload...), checksum...))
+}
+
+// base58CheckDecode decodes a base58check string and verifies its checksum
+func base58CheckDecode(s string) ([]byte, error) {
+	raw, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 5 {
+		return nil, errors.New("base58check string too short")
+	}
+
+	payload, checksum := raw[:len(raw)-4], raw[len(raw)-4:]
+	if !bytes.Equal(doubleSHA256(payload)[:4], checksum) {
+		return nil, errors.New("base58check checksum mismatch")
+	}
+
+	return payload, nil
+}
+
+// ethereumAddress returns the EIP-55 checksummed address of an uncompressed secp256k1 public key
+func ethereumAddress(uncompressed []byte) string {
+	return eip55(keccak256(uncompressed[1:])[12:])
+}
+
+// eip55 returns the mixed-case checksum encoding of a 20-byte address
+func eip55(address []byte) string {
+	lower := hex.EncodeToString(address)
+	hash := keccak256([]byte(lower))
+
+	out := []byte(lower)
+	for i, c := range out {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(out)
+}
+
+// encodeSegwitAddress encodes a witness program as a bech32 (v0) or bech32m (v1+) address
+func encodeSegwitAddress(hrp string, version byte, program []byte) (string, error) {
+	data, err := convertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	data = append([]byte{version}, data...)
+
+	constant := bech32Const
+	if version > 0 {
+		constant = bech32mConst
+	}
+
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ constant
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+
+	return sb.String(), nil
+}
+
+// decodeSegwitAddress decodes a bech32 or bech32m address for hrp into its witness version and program
+func decodeSegwitAddress(hrp string, address string) (byte, []byte, error) {
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return 0, nil, errInvalidBech32
+	}
+	address = strings.ToLower(address)
+
+	sep := strings.LastIndexByte(address, '1')
+	if sep < 1 || sep+8 > len(address) || len(address) > 90 || address[:sep] != hrp {
+		return 0, nil, errInvalidBech32
+	}
+
+	data := make([]byte, 0, len(address)-sep-1)
+	for i := sep + 1; i < len(address); i++ {
+		d := strings.IndexByte(bech32Charset, address[i])
+		if d < 0 {
+			return 0, nil, errInvalidBech32
+		}
+		data = append(data, byte(d))
+	}
+
+	version := data[0]
+	constant := bech32Const
+	if version > 0 {
+		constant = bech32mConst
+	}
+	if version > 16 || bech32Polymod(append(bech32HRPExpand(hrp), data...)) != constant {
+		return 0, nil, errInvalidBech32
+	}
+
+	program, err := convertBits(data[1:len(data)-6], 5, 8, false)
+	if err != nil || len(program) < 2 || len(program) > 40 {
+		return 0, nil, errInvalidBech32
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return 0, nil, errInvalidBech32
+	}
+
+	return version, program, nil
+}
+
+// bech32Polymod computes the BIP173 checksum polynomial
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+// bech32HRPExpand expands the human-readable part for checksum computation
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups data from fromBits-bit to toBits-bit groups
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<toBits - 1
+
+	var out []byte
+	for _, value := range data {
+		if uint(value)>>fromBits != 0 {
+			return nil, errInvalidBech32
+		}
+		acc = acc<<fromBits | uint(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, errInvalidBech32
+	}
+
+	return out, nil
+}
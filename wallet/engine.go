@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Engine names selectable with the engine mount option
+const (
+	// EngineTrustWalletCore uses the Trust Wallet Core C library; it is only
+	// compiled with the trustwalletcore build tag
+	EngineTrustWalletCore = "trustwalletcore"
+	// EngineGo is the pure-Go engine for Bitcoin, Ethereum and Solana
+	EngineGo = "go"
+)
+
+// ErrUnknownEngine is returned when an engine is not compiled into this build
+var ErrUnknownEngine = errors.New("unknown wallet engine")
+
+// Engine derives keys and addresses from mnemonics and signs with them
+// Implementations validate coin types against the coin registry and wrap
+// failures in the package's sentinel errors
+type Engine interface {
+	// GenerateWallet creates a mnemonic of wordCount words and derives the coin's default key
+	GenerateWallet(coinType uint32, wordCount int, passphrase string) (*WalletKeys, error)
+	// ImportWallet derives the coin's default key from an existing mnemonic
+	ImportWallet(mnemonic string, passphrase string, coinType uint32) (*WalletKeys, error)
+	// DeriveKey derives the key pair and address at a path; an empty path selects the coin's default
+	DeriveKey(mnemonic string, passphrase string, coinType uint32, derivationPath string) (*WalletKeys, error)
+	// DeriveAddress derives the address at a path; an empty path selects the coin's default
+	DeriveAddress(mnemonic string, passphrase string, coinType uint32, derivationPath string) (string, error)
+	// DeriveAddresses derives the public keys and addresses at each path from one seed
+	DeriveAddresses(mnemonic string, passphrase string, coinType uint32, addressType string, paths []string) ([]DerivedAddress, error)
+	// AddressForType returns the address of a public key in the given address type
+	AddressForType(publicKey []byte, coinType uint32, addressType string) (string, error)
+	// PublicKey returns the public key for a raw private key using the coin's curve
+	PublicKey(privateKey []byte, coinType uint32) ([]byte, error)
+	// SignTransaction signs raw data with the coin's curve
+	SignTransaction(privateKey []byte, coinType uint32, txData []byte) ([]byte, error)
+	// SignInput signs a chain-specific SigningInput protobuf
+	SignInput(privateKey []byte, coinType uint32, input []byte) (*SignedTransaction, error)
+	// SignMessage signs a message with the domain separation of the given format
+	SignMessage(privateKey []byte, coinType uint32, format string, message string, address string) (string, error)
+	// VerifyMessage checks a signature produced by SignMessage or SignTransaction
+	VerifyMessage(publicKey []byte, coinType uint32, format string, message string, signature string) (bool, error)
+	// ExtendedPublicKey returns the account-level extended public key of a wallet
+	ExtendedPublicKey(mnemonic string, passphrase string, coinType uint32, purpose uint32, account uint32) (string, error)
+	// DeriveFromExtendedKey derives the public key and address at change/addressIndex below an extended public key
+	DeriveFromExtendedKey(xpub string, coinType uint32, addressType string, change, addressIndex uint32) (*WalletKeys, error)
+}
+
+// engines holds the constructors of the engines compiled into this build
+var engines = map[string]func() Engine{
+	EngineGo: func() Engine { return NewGoEngine() },
+}
+
+// defaultEngine is used when no engine is configured
+// Builds with the trustwalletcore tag default to Trust Wallet Core
+var defaultEngine = EngineGo
+
+// NewEngine returns the named engine; an empty name selects the build's default engine
+func NewEngine(name string) (Engine, error) {
+	if name == "" {
+		name = defaultEngine
+	}
+
+	newEngine, ok := engines[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %v)", ErrUnknownEngine, name, Engines())
+	}
+
+	return newEngine(), nil
+}
+
+// DefaultEngine returns the name of the engine used when none is configured
+func DefaultEngine() string {
+	return defaultEngine
+}
+
+// RegisterEngine makes an engine selectable by name, e.g. a fake engine in tests
+// It panics if the name is empty or already registered
+func RegisterEngine(name string, newEngine func() Engine) {
+	if name == "" || newEngine == nil {
+		panic("wallet: RegisterEngine requires a name and a constructor")
+	}
+	if _, ok := engines[name]; ok {
+		panic("wallet: RegisterEngine called twice for engine " + name)
+	}
+	engines[name] = newEngine
+}
+
+// Engines returns the names of the engines compiled into this build
+func Engines() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
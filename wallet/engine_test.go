@@ -0,0 +1,362 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testMnemonic is the BIP39 test mnemonic used by BIP84 and most wallet test suites
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// testEngine returns the default engine of the build
+func testEngine(t *testing.T) Engine {
+	t.Helper()
+
+	engine, err := NewEngine("")
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	return engine
+}
+
+func TestNewEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  string
+		wantErr error
+	}{
+		{name: "default", engine: ""},
+		{name: "go", engine: EngineGo},
+		{name: "unknown", engine: "openssl", wantErr: ErrUnknownEngine},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngine(tt.engine)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewEngine(%q) error = %v, want %v", tt.engine, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && engine == nil {
+				t.Fatalf("NewEngine(%q) returned nil engine", tt.engine)
+			}
+		})
+	}
+
+	names := Engines()
+	found := false
+	for _, name := range names {
+		if name == DefaultEngine() {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Engines() = %v, missing default engine %q", names, DefaultEngine())
+	}
+}
+
+func TestPublicKeyKnownAnswers(t *testing.T) {
+	engine := testEngine(t)
+
+	tests := []struct {
+		name       string
+		coinType   uint32
+		privateKey string
+		publicKey  string
+		curve      string
+	}{
+		{
+			// secp256k1 generator point (private key 1), compressed
+			name:       "bitcoin",
+			coinType:   CoinTypeBitcoin,
+			privateKey: "0000000000000000000000000000000000000000000000000000000000000001",
+			publicKey:  "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+			curve:      CurveSecp256k1,
+		},
+		{
+			// secp256k1 generator point (private key 1), uncompressed
+			name:       "ethereum",
+			coinType:   CoinTypeEthereum,
+			privateKey: "0000000000000000000000000000000000000000000000000000000000000001",
+			publicKey:  "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+			curve:      CurveSecp256k1,
+		},
+		{
+			// RFC 8032 Ed25519 test vector 1
+			name:       "solana",
+			coinType:   CoinTypeSolana,
+			privateKey: "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			publicKey:  "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			curve:      CurveEd25519,
+		},
+		{
+			// SLIP-10 Ed25519 test vector 1, chain m
+			name:       "solana slip10",
+			coinType:   CoinTypeSolana,
+			privateKey: "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			publicKey:  "a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+			curve:      CurveEd25519,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, err := hex.DecodeString(tt.privateKey)
+			if err != nil {
+				t.Fatalf("invalid test private key: %v", err)
+			}
+
+			publicKey, err := engine.PublicKey(privateKey, tt.coinType)
+			if err != nil {
+				t.Fatalf("PublicKey() error = %v", err)
+			}
+			if got := hex.EncodeToString(publicKey); got != tt.publicKey {
+				t.Errorf("PublicKey() = %s, want %s", got, tt.publicKey)
+			}
+
+			curve, err := Curve(tt.coinType)
+			if err != nil {
+				t.Fatalf("Curve() error = %v", err)
+			}
+			if curve != tt.curve {
+				t.Errorf("Curve() = %s, want %s", curve, tt.curve)
+			}
+		})
+	}
+}
+
+func TestImportWalletKnownAnswers(t *testing.T) {
+	engine := testEngine(t)
+
+	tests := []struct {
+		name         string
+		coinType     uint32
+		address      string
+		publicKey    string
+		publicKeyLen int
+		curve        string
+	}{
+		{
+			// BIP84 test vector: m/84'/0'/0'/0/0
+			name:         "bitcoin",
+			coinType:     CoinTypeBitcoin,
+			address:      "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+			publicKeyLen: 33,
+			curve:        CurveSecp256k1,
+		},
+		{
+			// m/44'/60'/0'/0/0
+			name:         "ethereum",
+			coinType:     CoinTypeEthereum,
+			address:      "0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+			publicKeyLen: 65,
+			curve:        CurveSecp256k1,
+		},
+		{
+			// SLIP-10 m/44'/501'/0', the Trust Wallet default Solana account
+			name:         "solana",
+			coinType:     CoinTypeSolana,
+			address:      "GjJyeC1r2RgkuoCWMyPYkCWSGSGLcz266EaAkLA27AhL",
+			publicKey:    "e9b6062841bb977ad21de71ec961900633c26f21384e015b014a637a61499547",
+			publicKeyLen: 32,
+			curve:        CurveEd25519,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := engine.ImportWallet(testMnemonic, "", tt.coinType)
+			if err != nil {
+				t.Fatalf("ImportWallet() error = %v", err)
+			}
+
+			if tt.address != "" && keys.Address != tt.address {
+				t.Errorf("Address = %s, want %s", keys.Address, tt.address)
+			}
+			if tt.publicKey != "" && hex.EncodeToString(keys.PublicKey) != tt.publicKey {
+				t.Errorf("PublicKey = %x, want %s", keys.PublicKey, tt.publicKey)
+			}
+			if len(keys.PublicKey) != tt.publicKeyLen {
+				t.Errorf("len(PublicKey) = %d, want %d", len(keys.PublicKey), tt.publicKeyLen)
+			}
+			if keys.Curve != tt.curve {
+				t.Errorf("Curve = %s, want %s", keys.Curve, tt.curve)
+			}
+
+			// The public key must match the one derived from the private key
+			publicKey, err := engine.PublicKey(keys.PrivateKey, tt.coinType)
+			if err != nil {
+				t.Fatalf("PublicKey() error = %v", err)
+			}
+			if hex.EncodeToString(publicKey) != hex.EncodeToString(keys.PublicKey) {
+				t.Errorf("wallet public key does not match its private key")
+			}
+
+			// DeriveAddress with the default path must agree with the import
+			address, err := engine.DeriveAddress(testMnemonic, "", tt.coinType, "")
+			if err != nil {
+				t.Fatalf("DeriveAddress() error = %v", err)
+			}
+			if address != keys.Address {
+				t.Errorf("DeriveAddress() = %s, want %s", address, keys.Address)
+			}
+		})
+	}
+}
+
+func TestSignTransactionUsesCoinCurve(t *testing.T) {
+	engine := testEngine(t)
+	digest := []byte(strings.Repeat("\x01", 32))
+
+	tests := []struct {
+		name         string
+		coinType     uint32
+		signatureLen int
+	}{
+		// secp256k1 recoverable signature r||s||v
+		{name: "ethereum", coinType: CoinTypeEthereum, signatureLen: 65},
+		// Ed25519 signature
+		{name: "solana", coinType: CoinTypeSolana, signatureLen: 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := engine.ImportWallet(testMnemonic, "", tt.coinType)
+			if err != nil {
+				t.Fatalf("ImportWallet() error = %v", err)
+			}
+
+			signature, err := engine.SignTransaction(keys.PrivateKey, tt.coinType, digest)
+			if err != nil {
+				t.Fatalf("SignTransaction() error = %v", err)
+			}
+			if len(signature) != tt.signatureLen {
+				t.Errorf("len(signature) = %d, want %d", len(signature), tt.signatureLen)
+			}
+		})
+	}
+}
+
+func TestBIP44Path(t *testing.T) {
+	tests := []struct {
+		name     string
+		coinType uint32
+		account  uint32
+		change   uint32
+		index    uint32
+		want     string
+		wantErr  bool
+	}{
+		{name: "ethereum", coinType: CoinTypeEthereum, index: 5, want: "m/44'/60'/0'/0/5"},
+		{name: "bitcoin native segwit", coinType: CoinTypeBitcoin, account: 1, change: 1, want: "m/84'/0'/1'/1/0"},
+		{name: "solana default key", coinType: CoinTypeSolana, want: "m/44'/501'/0'"},
+		{name: "solana account per address", coinType: CoinTypeSolana, index: 2, want: "m/44'/501'/2'"},
+		{name: "solana account", coinType: CoinTypeSolana, account: 1, wantErr: true},
+		{name: "solana change", coinType: CoinTypeSolana, change: 1, index: 1, wantErr: true},
+		{name: "invalid change", coinType: CoinTypeEthereum, change: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BIP44Path(tt.coinType, tt.account, tt.change, tt.index)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BIP44Path() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BIP44Path() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveKeyAtPath(t *testing.T) {
+	engine := testEngine(t)
+
+	// The default path must yield the same key as the coin's default derivation
+	defaultKeys, err := engine.DeriveKey(testMnemonic, "", CoinTypeEthereum, "")
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	pathKeys, err := engine.DeriveKey(testMnemonic, "", CoinTypeEthereum, "m/44'/60'/0'/0/0")
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	if pathKeys.Address != defaultKeys.Address {
+		t.Errorf("DeriveKey() at default path = %s, want %s", pathKeys.Address, defaultKeys.Address)
+	}
+
+	// Another index yields another key
+	otherKeys, err := engine.DeriveKey(testMnemonic, "", CoinTypeEthereum, "m/44'/60'/0'/0/1")
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	if otherKeys.Address == defaultKeys.Address {
+		t.Errorf("DeriveKey() at index 1 returned the index 0 address")
+	}
+
+	// Solana address indexes select accounts in one layout, index 0 being the default key
+	for index, want := range []string{"GjJyeC1r2RgkuoCWMyPYkCWSGSGLcz266EaAkLA27AhL", "ANf3TEKFL6jPWjzkndo4CbnNdUNkBk4KHPggJs2nu8Xi", "Ag74i82rUZBTgMGLacCA1ZLnotvAca8CLscXcrG6Nwem"} {
+		solanaPath, err := BIP44Path(CoinTypeSolana, 0, 0, uint32(index))
+		if err != nil {
+			t.Fatalf("BIP44Path(solana, index %d) error = %v", index, err)
+		}
+		solanaKeys, err := engine.DeriveKey(testMnemonic, "", CoinTypeSolana, solanaPath)
+		if err != nil {
+			t.Fatalf("DeriveKey() error = %v", err)
+		}
+		if solanaKeys.Address != want {
+			t.Errorf("DeriveKey() at %s = %s, want %s", solanaPath, solanaKeys.Address, want)
+		}
+	}
+
+	// SLIP-10 Ed25519 below the account level: m/44'/501'/0'/0' is the Phantom and Solana CLI default
+	solanaKeys, err := engine.DeriveKey(testMnemonic, "", CoinTypeSolana, "m/44'/501'/0'/0'")
+	if err != nil {
+		t.Fatalf("DeriveKey() error = %v", err)
+	}
+	if want := "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"; solanaKeys.Address != want {
+		t.Errorf("DeriveKey() at m/44'/501'/0'/0' = %s, want %s", solanaKeys.Address, want)
+	}
+}
+
+func TestGenerateWalletWordCount(t *testing.T) {
+	engine := testEngine(t)
+
+	for _, wordCount := range []int{12, 15, 18, 21, 24} {
+		keys, err := engine.GenerateWallet(CoinTypeEthereum, wordCount, "")
+		if err != nil {
+			t.Fatalf("GenerateWallet(%d) error = %v", wordCount, err)
+		}
+		if got := len(MnemonicWords(keys.Mnemonic)); got != wordCount {
+			t.Errorf("GenerateWallet(%d) mnemonic has %d words", wordCount, got)
+		}
+	}
+
+	if _, err := engine.GenerateWallet(CoinTypeEthereum, 13, ""); !errors.Is(err, ErrInvalidWordCount) {
+		t.Errorf("GenerateWallet(13) error = %v, want %v", err, ErrInvalidWordCount)
+	}
+}
+
+func TestImportWalletReportsInvalidMnemonic(t *testing.T) {
+	engine := testEngine(t)
+
+	tests := []struct {
+		name     string
+		mnemonic string
+		want     string
+	}{
+		{name: "word count", mnemonic: "abandon abandon abandon", want: "mnemonic has 3 words"},
+		{name: "unknown word", mnemonic: strings.Replace(testMnemonic, "about", "aboot", 1), want: "word 12 is not in the english wordlist"},
+		{name: "checksum", mnemonic: strings.Replace(testMnemonic, "about", "abandon", 1), want: "checksum mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := engine.ImportWallet(tt.mnemonic, "", CoinTypeEthereum)
+			if !errors.Is(err, ErrInvalidMnemonic) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ImportWallet() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
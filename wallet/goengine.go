@@ -0,0 +1,337 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// goEngineCoins lists the coin types the pure-Go engine implements
+var goEngineCoins = map[uint32]bool{
+	CoinTypeBitcoin:  true,
+	CoinTypeEthereum: true,
+	CoinTypeSolana:   true,
+}
+
+// GoEngine is a pure-Go Engine for Bitcoin, Ethereum and Solana
+// It derives keys with BIP39, BIP32 (secp256k1) and SLIP-10 (Ed25519) and needs no CGO,
+// so it is the engine of builds without the trustwalletcore tag
+type GoEngine struct{}
+
+// NewGoEngine creates a new pure-Go engine
+func NewGoEngine() *GoEngine {
+	return &GoEngine{}
+}
+
+// GenerateWallet generates a new HD wallet for the specified coin type
+// It creates a new mnemonic phrase of wordCount words and derives keys for the given blockchain
+// The optional passphrase is the BIP39 "25th word" mixed into the seed
+func (g *GoEngine) GenerateWallet(coinType uint32, wordCount int, passphrase string) (*WalletKeys, error) {
+	if !g.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	strength, err := MnemonicStrength(wordCount)
+	if err != nil {
+		return nil, err
+	}
+
+	entropy, err := bip39.NewEntropy(strength)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyGenerationFailed, err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyGenerationFailed, err)
+	}
+
+	keys, err := g.deriveKey(mnemonic, passphrase, coinType, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyGenerationFailed, err)
+	}
+	keys.Mnemonic = mnemonic
+
+	return keys, nil
+}
+
+// ImportWallet imports an existing wallet from a mnemonic phrase and optional BIP39 passphrase
+// It validates the mnemonic and derives keys for the specified coin type
+func (g *GoEngine) ImportWallet(mnemonic string, passphrase string, coinType uint32) (*WalletKeys, error) {
+	keys, err := g.DeriveKey(mnemonic, passphrase, coinType, "")
+	if err != nil {
+		return nil, err
+	}
+	keys.Mnemonic = mnemonic
+
+	return keys, nil
+}
+
+// DeriveKey derives the key pair and address for a coin type from a mnemonic and BIP39 passphrase
+// If derivationPath is empty, the key is derived at the coin's default path
+func (g *GoEngine) DeriveKey(mnemonic string, passphrase string, coinType uint32, derivationPath string) (*WalletKeys, error) {
+	if mnemonic == "" {
+		return nil, fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	// Validate mnemonic word by word so the caller learns what is wrong with it
+	if err := validateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	return g.deriveKey(mnemonic, passphrase, coinType, derivationPath)
+}
+
+// DeriveAddress derives an address for a specific coin type and derivation path
+// If derivationPath is empty, it uses the default path for the coin type
+func (g *GoEngine) DeriveAddress(mnemonic string, passphrase string, coinType uint32, derivationPath string) (string, error) {
+	keys, err := g.DeriveKey(mnemonic, passphrase, coinType, derivationPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Only the address is needed
+	for i := range keys.PrivateKey {
+		keys.PrivateKey[i] = 0
+	}
+
+	return keys.Address, nil
+}
+
+// DeriveAddresses derives the public keys and addresses at each path from one seed
+// The seed is expanded once for the whole batch and no private key leaves this function
+// An empty address type yields the coin's default address format
+func (g *GoEngine) DeriveAddresses(mnemonic string, passphrase string, coinType uint32, addressType string, paths []string) ([]DerivedAddress, error) {
+	if mnemonic == "" {
+		return nil, fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if addressType != "" {
+		if _, err := AddressTypePurpose(coinType, addressType); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	master, err := masterKey(mnemonic, passphrase, coinType)
+	if err != nil {
+		return nil, err
+	}
+	defer master.zero()
+
+	addresses := make([]DerivedAddress, 0, len(paths))
+	for _, path := range paths {
+		key, err := master.derivePath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, err := g.PublicKey(key.key, coinType)
+		key.zero()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrAddressDerivation, err)
+		}
+
+		address, err := g.AddressForType(publicKey, coinType, addressType)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, DerivedAddress{
+			Path:      path,
+			PublicKey: publicKey,
+			Address:   address,
+		})
+	}
+
+	return addresses, nil
+}
+
+// PublicKey returns the public key for a raw private key using the coin's curve
+// secp256k1 keys are compressed, except for Ethereum which uses the uncompressed form
+func (g *GoEngine) PublicKey(privateKey []byte, coinType uint32) ([]byte, error) {
+	if len(privateKey) == 0 {
+		return nil, fmt.Errorf("%w: empty private key", ErrKeyGenerationFailed)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if len(privateKey) != 32 {
+		return nil, fmt.Errorf("%w: private key must be 32 bytes", ErrKeyGenerationFailed)
+	}
+
+	if coinType == CoinTypeSolana {
+		return ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey), nil
+	}
+
+	privKey, err := secp256k1PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyGenerationFailed, err)
+	}
+	defer privKey.Zero()
+
+	if coinType == CoinTypeEthereum {
+		return privKey.PubKey().SerializeUncompressed(), nil
+	}
+	return privKey.PubKey().SerializeCompressed(), nil
+}
+
+// SignTransaction signs a transaction using the private key for the specified coin type
+// secp256k1 chains sign a 32-byte digest and return r||s||v; Ed25519 chains sign the data itself
+func (g *GoEngine) SignTransaction(privateKey []byte, coinType uint32, txData []byte) ([]byte, error) {
+	if len(privateKey) == 0 {
+		return nil, fmt.Errorf("%w: empty private key", ErrSigningFailed)
+	}
+
+	if len(txData) == 0 {
+		return nil, fmt.Errorf("%w: empty transaction data", ErrSigningFailed)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if coinType == CoinTypeSolana {
+		return signEd25519(privateKey, txData)
+	}
+
+	signature, err := signSecp256k1(privateKey, txData)
+	if err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+// isValidCoinType checks if the coin type is in the coin registry and implemented by the engine
+func (g *GoEngine) isValidCoinType(coinType uint32) bool {
+	if !goEngineCoins[coinType] {
+		return false
+	}
+	_, ok := LookupCoin(coinType)
+	return ok
+}
+
+// deriveKey derives the key pair and address at a path of an already validated mnemonic
+func (g *GoEngine) deriveKey(mnemonic string, passphrase string, coinType uint32, derivationPath string) (*WalletKeys, error) {
+	if derivationPath == "" {
+		path, err := DefaultDerivationPath(coinType)
+		if err != nil {
+			return nil, err
+		}
+		derivationPath = path
+	}
+
+	master, err := masterKey(mnemonic, passphrase, coinType)
+	if err != nil {
+		return nil, err
+	}
+	defer master.zero()
+
+	key, err := master.derivePath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := g.PublicKey(key.key, coinType)
+	if err != nil {
+		key.zero()
+		return nil, fmt.Errorf("%w: %v", ErrAddressDerivation, err)
+	}
+
+	address, err := g.AddressForType(publicKey, coinType, "")
+	if err != nil {
+		key.zero()
+		return nil, err
+	}
+
+	curve, _ := Curve(coinType)
+
+	return &WalletKeys{
+		PrivateKey: key.key,
+		PublicKey:  publicKey,
+		Address:    address,
+		Curve:      curve,
+	}, nil
+}
+
+// masterKey expands a mnemonic into the master key of the coin's curve
+func masterKey(mnemonic string, passphrase string, coinType uint32) (*hdKey, error) {
+	seed := bip39.NewSeed(strings.Join(MnemonicWords(mnemonic), " "), passphrase)
+	defer func() {
+		for i := range seed {
+			seed[i] = 0
+		}
+	}()
+
+	curve, err := Curve(coinType)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMasterKey(seed, curve)
+}
+
+// secp256k1PrivateKey parses a 32-byte secp256k1 private key, rejecting zero and out-of-range scalars
+func secp256k1PrivateKey(privateKey []byte) (*secp256k1.PrivateKey, error) {
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(privateKey); overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("private key is not a valid secp256k1 scalar")
+	}
+	return secp256k1.NewPrivateKey(&scalar), nil
+}
+
+// signSecp256k1 signs a 32-byte digest and returns the recoverable signature r||s||v with v in {0, 1}
+func signSecp256k1(privateKey []byte, digest []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("%w: secp256k1 signatures require a 32-byte digest", ErrSigningFailed)
+	}
+
+	privKey, err := secp256k1PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSigningFailed, err)
+	}
+	defer privKey.Zero()
+
+	// SignCompact returns header || r || s with header = 27 + recovery id for uncompressed keys
+	compact := ecdsa.SignCompact(privKey, digest, false)
+
+	signature := make([]byte, 65)
+	copy(signature, compact[1:])
+	signature[64] = compact[0] - 27
+
+	return signature, nil
+}
+
+// signEd25519 signs data with a 32-byte Ed25519 private key seed
+func signEd25519(privateKey []byte, data []byte) ([]byte, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: private key must be 32 bytes", ErrSigningFailed)
+	}
+
+	key := ed25519.NewKeyFromSeed(privateKey)
+	defer func() {
+		for i := range key {
+			key[i] = 0
+		}
+	}()
+
+	return ed25519.Sign(key, data), nil
+}
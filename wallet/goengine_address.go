@@ -0,0 +1,121 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Bitcoin mainnet address parameters
+const (
+	bitcoinHRP         = "bc"
+	bitcoinP2PKHPrefix = 0x00
+	bitcoinP2SHPrefix  = 0x05
+)
+
+// AddressForType returns the address of a public key in the given address type
+// An empty address type yields the coin's default address format
+func (g *GoEngine) AddressForType(publicKey []byte, coinType uint32, addressType string) (string, error) {
+	if len(publicKey) == 0 {
+		return "", fmt.Errorf("%w: empty public key", ErrAddressDerivation)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if addressType != "" {
+		if _, err := AddressTypePurpose(coinType, addressType); err != nil {
+			return "", err
+		}
+	}
+
+	if coinType == CoinTypeSolana {
+		if len(publicKey) != ed25519.PublicKeySize {
+			return "", fmt.Errorf("%w: invalid public key", ErrAddressDerivation)
+		}
+		return base58Encode(publicKey), nil
+	}
+
+	pubKey, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid public key", ErrAddressDerivation)
+	}
+
+	if coinType == CoinTypeEthereum {
+		return ethereumAddress(pubKey.SerializeUncompressed()), nil
+	}
+
+	address, err := bitcoinAddress(pubKey, addressType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrAddressDerivation, err)
+	}
+
+	return address, nil
+}
+
+// bitcoinAddress derives a Bitcoin address of a public key; the default type is P2WPKH
+func bitcoinAddress(pubKey *secp256k1.PublicKey, addressType string) (string, error) {
+	keyHash := hash160(pubKey.SerializeCompressed())
+
+	switch addressType {
+	case AddressTypeP2PKH:
+		return base58CheckEncode(append([]byte{bitcoinP2PKHPrefix}, keyHash...)), nil
+
+	case AddressTypeP2SHP2WPKH:
+		// P2SH wrapping the witness program 0 <hash160(pubkey)> (BIP49)
+		redeemScript := append([]byte{0x00, 0x14}, keyHash...)
+		return base58CheckEncode(append([]byte{bitcoinP2SHPrefix}, hash160(redeemScript)...)), nil
+
+	case AddressTypeP2TR:
+		outputKey, err := taprootOutputKey(pubKey)
+		if err != nil {
+			return "", err
+		}
+		return encodeSegwitAddress(bitcoinHRP, 1, outputKey)
+
+	default:
+		return encodeSegwitAddress(bitcoinHRP, 0, keyHash)
+	}
+}
+
+// taprootOutputKey tweaks an internal key with no script tree (BIP86):
+// Q = lift_x(P) + hash_TapTweak(x(P))·G
+func taprootOutputKey(pubKey *secp256k1.PublicKey) ([]byte, error) {
+	xOnly := pubKey.SerializeCompressed()[1:]
+
+	// lift_x picks the point with an even y coordinate
+	internalKey, err := secp256k1.ParsePubKey(append([]byte{0x02}, xOnly...))
+	if err != nil {
+		return nil, err
+	}
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(taggedHash("TapTweak", xOnly)); overflow {
+		return nil, errors.New("invalid taproot tweak")
+	}
+
+	var tweakPoint, internalPoint, outputPoint secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&tweak, &tweakPoint)
+	internalKey.AsJacobian(&internalPoint)
+	secp256k1.AddNonConst(&internalPoint, &tweakPoint, &outputPoint)
+	outputPoint.ToAffine()
+	outputPoint.X.Normalize()
+
+	x := outputPoint.X.Bytes()
+	return x[:], nil
+}
+
+// taggedHash computes the BIP340 tagged hash SHA256(SHA256(tag) || SHA256(tag) || msg)
+func taggedHash(tag string, msg []byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	h.Write(msg)
+	return h.Sum(nil)
+}
@@ -0,0 +1,223 @@
+package wallet
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Ethereum SigningInput and SigningOutput field numbers (see Ethereum.proto)
+const (
+	ethInputChainID        protowire.Number = 1
+	ethInputNonce          protowire.Number = 2
+	ethInputTxMode         protowire.Number = 3
+	ethInputGasPrice       protowire.Number = 4
+	ethInputGasLimit       protowire.Number = 5
+	ethInputMaxInclusion   protowire.Number = 6
+	ethInputMaxFee         protowire.Number = 7
+	ethInputToAddress      protowire.Number = 8
+	ethInputTransaction    protowire.Number = 10
+	ethTransactionTransfer protowire.Number = 1
+	ethTransactionGeneric  protowire.Number = 6
+	ethOutputEncoded       protowire.Number = 1
+	ethOutputV             protowire.Number = 2
+	ethOutputR             protowire.Number = 3
+	ethOutputS             protowire.Number = 4
+	ethOutputData          protowire.Number = 5
+	ethOutputPreHash       protowire.Number = 8
+)
+
+// Ethereum transaction modes
+const (
+	ethTxModeLegacy    = 0
+	ethTxModeEnveloped = 1
+)
+
+// SignInput signs a chain-specific SigningInput protobuf
+// The pure-Go engine signs Ethereum transfers and contract calls, in legacy (EIP-155)
+// or EIP-1559 mode; other chains and transaction kinds require the trustwalletcore engine
+func (g *GoEngine) SignInput(privateKey []byte, coinType uint32, input []byte) (*SignedTransaction, error) {
+	if len(privateKey) == 0 {
+		return nil, fmt.Errorf("%w: empty private key", ErrSigningFailed)
+	}
+
+	if len(input) == 0 {
+		return nil, fmt.Errorf("%w: empty signing input", ErrInvalidSigningInput)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if coinType != CoinTypeEthereum {
+		return nil, fmt.Errorf("%w: signing input for coin type %d requires the %s engine", ErrInvalidCoinType, coinType, EngineTrustWalletCore)
+	}
+
+	fields, err := parseFields(input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSigningInput, err)
+	}
+
+	tx, err := parseEthereumTransaction(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	preHash := keccak256(tx.signingPayload())
+	signature, err := signSecp256k1(privateKey, preHash)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, v := tx.encode(signature)
+	r, s := trimLeadingZeros(signature[:32]), trimLeadingZeros(signature[32:64])
+
+	var output []byte
+	output = appendBytesField(output, ethOutputEncoded, encoded)
+	output = appendBytesField(output, ethOutputV, v)
+	output = appendBytesField(output, ethOutputR, r)
+	output = appendBytesField(output, ethOutputS, s)
+	output = appendBytesField(output, ethOutputData, tx.data)
+	output = appendBytesField(output, ethOutputPreHash, preHash)
+
+	// Read the output back the way Trust Wallet Core output is read, so both engines agree
+	return parseSigningOutput(CoinTypeEthereum, output)
+}
+
+// ethereumTransaction holds the fields of an Ethereum SigningInput as RLP integers and strings
+type ethereumTransaction struct {
+	mode         uint64
+	chainID      []byte
+	nonce        []byte
+	gasPrice     []byte
+	gasLimit     []byte
+	maxInclusion []byte
+	maxFee       []byte
+	to           []byte
+	value        []byte
+	data         []byte
+}
+
+// parseEthereumTransaction reads a transfer or generic contract call from a SigningInput
+func parseEthereumTransaction(fields protoFields) (*ethereumTransaction, error) {
+	tx := &ethereumTransaction{
+		mode:         fields.varint(ethInputTxMode),
+		chainID:      trimLeadingZeros(fields.bytes(ethInputChainID)),
+		nonce:        trimLeadingZeros(fields.bytes(ethInputNonce)),
+		gasPrice:     trimLeadingZeros(fields.bytes(ethInputGasPrice)),
+		gasLimit:     trimLeadingZeros(fields.bytes(ethInputGasLimit)),
+		maxInclusion: trimLeadingZeros(fields.bytes(ethInputMaxInclusion)),
+		maxFee:       trimLeadingZeros(fields.bytes(ethInputMaxFee)),
+	}
+
+	if tx.mode != ethTxModeLegacy && tx.mode != ethTxModeEnveloped {
+		return nil, fmt.Errorf("%w: transaction mode %d requires the %s engine", ErrInvalidSigningInput, tx.mode, EngineTrustWalletCore)
+	}
+
+	if len(tx.chainID) == 0 {
+		return nil, fmt.Errorf("%w: chain_id is required", ErrInvalidSigningInput)
+	}
+
+	if to := string(fields.bytes(ethInputToAddress)); to != "" {
+		if !isHexAddress(to, 20) {
+			return nil, fmt.Errorf("%w: to_address must be a 0x-prefixed 20-byte address", ErrInvalidSigningInput)
+		}
+		tx.to, _ = decodeHex(to)
+	}
+
+	transaction, err := parseFields(fields.bytes(ethInputTransaction))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSigningInput, err)
+	}
+
+	// Transfer and ContractGeneric share the layout { amount = 1; data = 2 }
+	call, ok := transaction[ethTransactionTransfer]
+	if !ok {
+		call, ok = transaction[ethTransactionGeneric]
+	}
+	if !ok || len(transaction) != 1 {
+		return nil, fmt.Errorf("%w: only transfer and contract_generic transactions are supported by the %s engine", ErrInvalidSigningInput, EngineGo)
+	}
+
+	callFields, err := parseFields(call)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSigningInput, err)
+	}
+	tx.value = trimLeadingZeros(callFields.bytes(1))
+	tx.data = callFields.bytes(2)
+
+	return tx, nil
+}
+
+// signingPayload returns the data whose Keccak-256 hash is signed
+// Legacy transactions are replay-protected with the chain ID (EIP-155)
+func (tx *ethereumTransaction) signingPayload() []byte {
+	if tx.mode == ethTxModeEnveloped {
+		return append([]byte{0x02}, rlpList(tx.eip1559Fields()...)...)
+	}
+
+	return rlpList(rlpStrings(tx.nonce, tx.gasPrice, tx.gasLimit, tx.to, tx.value, tx.data, tx.chainID, nil, nil)...)
+}
+
+// encode returns the signed transaction and its v value for a r||s||recovery id signature
+func (tx *ethereumTransaction) encode(signature []byte) ([]byte, []byte) {
+	r, s := trimLeadingZeros(signature[:32]), trimLeadingZeros(signature[32:64])
+	recoveryID := int64(signature[64])
+
+	if tx.mode == ethTxModeEnveloped {
+		v := big.NewInt(recoveryID).Bytes()
+		fields := append(tx.eip1559Fields(), rlpStrings(v, r, s)...)
+		return append([]byte{0x02}, rlpList(fields...)...), v
+	}
+
+	// v = chain_id * 2 + 35 + recovery id
+	v := new(big.Int).SetBytes(tx.chainID)
+	v.Mul(v, big.NewInt(2))
+	v.Add(v, big.NewInt(35+recoveryID))
+
+	return rlpList(rlpStrings(tx.nonce, tx.gasPrice, tx.gasLimit, tx.to, tx.value, tx.data, v.Bytes(), r, s)...), v.Bytes()
+}
+
+// eip1559Fields returns the encoded unsigned fields of an EIP-1559 transaction with an empty access list
+func (tx *ethereumTransaction) eip1559Fields() [][]byte {
+	fields := rlpStrings(tx.chainID, tx.nonce, tx.maxInclusion, tx.maxFee, tx.gasLimit, tx.to, tx.value, tx.data)
+	return append(fields, rlpList())
+}
+
+// rlpList encodes already encoded items as an RLP list
+func rlpList(items ...[]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(rlpLength(len(payload), 0xc0), payload...)
+}
+
+// rlpStrings encodes each byte string as an RLP item
+func rlpStrings(values ...[]byte) [][]byte {
+	items := make([][]byte, len(values))
+	for i, b := range values {
+		if len(b) == 1 && b[0] < 0x80 {
+			items[i] = []byte{b[0]}
+			continue
+		}
+		items[i] = append(rlpLength(len(b), 0x80), b...)
+	}
+	return items
+}
+
+// rlpLength encodes the length prefix of a string (offset 0x80) or list (offset 0xc0)
+func rlpLength(n int, offset byte) []byte {
+	if n < 56 {
+		return []byte{offset + byte(n)}
+	}
+	length := big.NewInt(int64(n)).Bytes()
+	return append([]byte{offset + 55 + byte(len(length))}, length...)
+}
+
+// trimLeadingZeros returns the minimal big-endian form of an integer
+func trimLeadingZeros(b []byte) []byte {
+	return bytes.TrimLeft(b, "\x00")
+}
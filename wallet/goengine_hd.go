@@ -0,0 +1,402 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// SLIP-132 extended public key versions
+const (
+	versionXPUB uint32 = 0x0488b21e
+	versionYPUB uint32 = 0x049d7cb2
+	versionZPUB uint32 = 0x04b24746
+)
+
+// hdKey is a BIP32 (secp256k1) or SLIP-10 (Ed25519) extended key
+// Public-only keys, parsed from an extended public key, have no private key
+type hdKey struct {
+	curve             string
+	key               []byte
+	publicKey         []byte // compressed secp256k1 public key of public-only keys
+	chainCode         []byte
+	depth             byte
+	parentFingerprint []byte
+	childNumber       uint32
+}
+
+// newMasterKey derives the master key of a curve from a BIP39 seed
+func newMasterKey(seed []byte, curve string) (*hdKey, error) {
+	var hmacKey string
+	switch curve {
+	case CurveSecp256k1:
+		hmacKey = "Bitcoin seed"
+	case CurveEd25519:
+		hmacKey = "ed25519 seed"
+	default:
+		return nil, fmt.Errorf("%w: curve %s is not supported by the %s engine", ErrAddressDerivation, curve, EngineGo)
+	}
+
+	mac := hmac.New(sha512.New, []byte(hmacKey))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := &hdKey{curve: curve, key: sum[:32], chainCode: sum[32:], parentFingerprint: make([]byte, 4)}
+	if curve == CurveSecp256k1 {
+		if _, err := secp256k1PrivateKey(key.key); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrKeyGenerationFailed, err)
+		}
+	}
+
+	return key, nil
+}
+
+// derivePath derives the private key at a path such as m/44'/60'/0'/0/0
+func (k *hdKey) derivePath(path string) (*hdKey, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("%w: failed to derive key for path %s", ErrAddressDerivation, path)
+	}
+
+	key := k
+	for _, part := range parts[1:] {
+		index, err := parsePathIndex(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to derive key for path %s", ErrAddressDerivation, path)
+		}
+
+		child, err := key.privateChild(index)
+		if key != k {
+			key.zero()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to derive key for path %s: %v", ErrAddressDerivation, path, err)
+		}
+		key = child
+	}
+
+	if key == k {
+		// Return a copy so callers may zero the result independently of the master key
+		key = &hdKey{curve: k.curve, key: append([]byte{}, k.key...), chainCode: k.chainCode, parentFingerprint: k.parentFingerprint}
+	}
+
+	return key, nil
+}
+
+// privateChild derives a child private key (BIP32 CKDpriv, or SLIP-10 for Ed25519)
+func (k *hdKey) privateChild(index uint32) (*hdKey, error) {
+	hardened := index >= hardenedOffset
+	if k.curve == CurveEd25519 && !hardened {
+		return nil, errors.New("ed25519 only supports hardened derivation")
+	}
+
+	parentPublicKey, err := k.compressedPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, 37)
+	if hardened {
+		data = append(append(data, 0x00), k.key...)
+	} else {
+		data = append(data, parentPublicKey...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	child := &hdKey{
+		curve:             k.curve,
+		chainCode:         sum[32:],
+		depth:             k.depth + 1,
+		parentFingerprint: hash160(parentPublicKey)[:4],
+		childNumber:       index,
+	}
+
+	if k.curve == CurveEd25519 {
+		child.key = sum[:32]
+		return child, nil
+	}
+
+	// k_i = parse256(IL) + k_par (mod n)
+	var tweak, parent secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return nil, errors.New("invalid child key")
+	}
+	parent.SetByteSlice(k.key)
+	tweak.Add(&parent)
+	if tweak.IsZero() {
+		return nil, errors.New("invalid child key")
+	}
+
+	childKey := tweak.Bytes()
+	child.key = childKey[:]
+	tweak.Zero()
+	parent.Zero()
+
+	return child, nil
+}
+
+// publicChild derives a non-hardened child public key (BIP32 CKDpub)
+func (k *hdKey) publicChild(index uint32) (*hdKey, error) {
+	if index >= hardenedOffset {
+		return nil, errors.New("cannot derive a hardened child from a public key")
+	}
+
+	parentKey, err := secp256k1.ParsePubKey(k.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data := binary.BigEndian.AppendUint32(append([]byte{}, k.publicKey...), index)
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	// K_i = point(parse256(IL)) + K_par
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return nil, errors.New("invalid child key")
+	}
+
+	var tweakPoint, parentPoint, childPoint secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&tweak, &tweakPoint)
+	parentKey.AsJacobian(&parentPoint)
+	secp256k1.AddNonConst(&tweakPoint, &parentPoint, &childPoint)
+	if (childPoint.X.IsZero() && childPoint.Y.IsZero()) || childPoint.Z.IsZero() {
+		return nil, errors.New("invalid child key")
+	}
+	childPoint.ToAffine()
+
+	return &hdKey{
+		curve:             k.curve,
+		publicKey:         secp256k1.NewPublicKey(&childPoint.X, &childPoint.Y).SerializeCompressed(),
+		chainCode:         sum[32:],
+		depth:             k.depth + 1,
+		parentFingerprint: hash160(k.publicKey)[:4],
+		childNumber:       index,
+	}, nil
+}
+
+// compressedPublicKey returns the key's public key, compressed for secp256k1
+func (k *hdKey) compressedPublicKey() ([]byte, error) {
+	if k.key == nil {
+		return k.publicKey, nil
+	}
+
+	if k.curve == CurveEd25519 {
+		return ed25519.NewKeyFromSeed(k.key).Public().(ed25519.PublicKey), nil
+	}
+
+	privKey, err := secp256k1PrivateKey(k.key)
+	if err != nil {
+		return nil, err
+	}
+	defer privKey.Zero()
+
+	return privKey.PubKey().SerializeCompressed(), nil
+}
+
+// serializePublic encodes the key as a base58check extended public key with the given version
+func (k *hdKey) serializePublic(version uint32) (string, error) {
+	publicKey, err := k.compressedPublicKey()
+	if err != nil {
+		return "", err
+	}
+
+	payload := make([]byte, 0, 78)
+	payload = binary.BigEndian.AppendUint32(payload, version)
+	payload = append(payload, k.depth)
+	payload = append(payload, k.parentFingerprint...)
+	payload = binary.BigEndian.AppendUint32(payload, k.childNumber)
+	payload = append(payload, k.chainCode...)
+	payload = append(payload, publicKey...)
+
+	return base58CheckEncode(payload), nil
+}
+
+// zero clears the private key material
+func (k *hdKey) zero() {
+	for i := range k.key {
+		k.key[i] = 0
+	}
+}
+
+// parseExtendedPublicKey decodes an xpub, ypub or zpub
+func parseExtendedPublicKey(xpub string) (*hdKey, error) {
+	payload, err := base58CheckDecode(xpub)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) != 78 {
+		return nil, errors.New("unexpected extended key length")
+	}
+
+	switch binary.BigEndian.Uint32(payload[:4]) {
+	case versionXPUB, versionYPUB, versionZPUB:
+	default:
+		return nil, errors.New("not an extended public key")
+	}
+
+	publicKey := payload[45:]
+	if _, err := secp256k1.ParsePubKey(publicKey); err != nil {
+		return nil, err
+	}
+
+	return &hdKey{
+		curve:             CurveSecp256k1,
+		publicKey:         publicKey,
+		chainCode:         payload[13:45],
+		depth:             payload[4],
+		parentFingerprint: payload[5:9],
+		childNumber:       binary.BigEndian.Uint32(payload[9:13]),
+	}, nil
+}
+
+// parsePathIndex parses one derivation path level, where a trailing ' marks a hardened index
+func parsePathIndex(part string) (uint32, error) {
+	hardened := strings.HasSuffix(part, "'")
+	index, err := strconv.ParseUint(strings.TrimSuffix(part, "'"), 10, 31)
+	if err != nil {
+		return 0, err
+	}
+	if hardened {
+		index += hardenedOffset
+	}
+	return uint32(index), nil
+}
+
+// extendedKeyVersionFor returns the SLIP-132 version for a purpose
+func extendedKeyVersionFor(purpose uint32) uint32 {
+	switch purpose {
+	case PurposeBIP49:
+		return versionYPUB
+	case PurposeBIP84:
+		return versionZPUB
+	default:
+		return versionXPUB
+	}
+}
+
+// ExtendedPublicKey returns the account-level extended public key of a wallet
+// The version follows the purpose: xpub for 44 and 86, ypub for 49 and zpub for 84
+// Ed25519 chains such as Solana have no extended public keys, as they only support hardened derivation
+func (g *GoEngine) ExtendedPublicKey(mnemonic string, passphrase string, coinType uint32, purpose uint32, account uint32) (string, error) {
+	if mnemonic == "" {
+		return "", fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if hardenedOnly(coinType) {
+		return "", fmt.Errorf("%w: coin type %d only supports hardened derivation", ErrInvalidExtendedKey, coinType)
+	}
+
+	if err := validatePurpose(coinType, purpose); err != nil {
+		return "", err
+	}
+
+	if account >= hardenedOffset {
+		return "", fmt.Errorf("%w: account must be below %d", ErrInvalidDerivationPath, uint32(hardenedOffset))
+	}
+
+	if err := validateMnemonic(mnemonic); err != nil {
+		return "", err
+	}
+
+	master, err := masterKey(mnemonic, passphrase, coinType)
+	if err != nil {
+		return "", err
+	}
+	defer master.zero()
+
+	accountKey, err := master.derivePath(fmt.Sprintf("m/%d'/%d'/%d'", purpose, coinType, account))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+	}
+	defer accountKey.zero()
+
+	xpub, err := accountKey.serializePublic(extendedKeyVersionFor(purpose))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to export extended public key", ErrInvalidExtendedKey)
+	}
+
+	return xpub, nil
+}
+
+// DeriveFromExtendedKey derives the public key and address at change/addressIndex below an
+// account-level extended public key, without access to the mnemonic
+// For Bitcoin ypub and zpub keys imply their address type; an xpub needs an explicit one
+func (g *GoEngine) DeriveFromExtendedKey(xpub string, coinType uint32, addressType string, change, addressIndex uint32) (*WalletKeys, error) {
+	if xpub == "" {
+		return nil, fmt.Errorf("%w: empty extended public key", ErrInvalidExtendedKey)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if hardenedOnly(coinType) {
+		return nil, fmt.Errorf("%w: coin type %d only supports hardened derivation", ErrInvalidExtendedKey, coinType)
+	}
+
+	if addressType != "" {
+		if _, err := AddressTypePurpose(coinType, addressType); err != nil {
+			return nil, err
+		}
+	}
+	if coinType == CoinTypeBitcoin {
+		resolved, err := extendedKeyAddressType(xpub, addressType)
+		if err != nil {
+			return nil, err
+		}
+		addressType = resolved
+	}
+
+	// Validates change and address index the same way as key paths
+	if _, err := formatBIP44Path(PurposeBIP44, coinType, 0, change, addressIndex); err != nil {
+		return nil, err
+	}
+
+	account, err := parseExtendedPublicKey(xpub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+	}
+
+	changeKey, err := account.publicChild(change)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to derive public key", ErrInvalidExtendedKey)
+	}
+	key, err := changeKey.publicChild(addressIndex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to derive public key", ErrInvalidExtendedKey)
+	}
+
+	publicKey := key.publicKey
+	if coinType == CoinTypeEthereum {
+		parsed, _ := secp256k1.ParsePubKey(publicKey)
+		publicKey = parsed.SerializeUncompressed()
+	}
+
+	address, err := g.AddressForType(publicKey, coinType, addressType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletKeys{
+		PublicKey: publicKey,
+		Address:   address,
+		Curve:     CurveSecp256k1,
+	}, nil
+}
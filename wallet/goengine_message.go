@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// SignMessage signs a message with the domain separation of the given format
+// The signature uses the chain's conventional encoding:
+//   - eip191: 0x-prefixed hex r||s||v with v in {27, 28}
+//   - bitcoin: base64 compact signature with a BIP137 header for the address type
+//   - solana-offchain: base58 Ed25519 signature over the off-chain message envelope
+//
+// EIP-712 typed data requires the trustwalletcore engine
+func (g *GoEngine) SignMessage(privateKey []byte, coinType uint32, format string, message string, address string) (string, error) {
+	if len(privateKey) == 0 {
+		return "", fmt.Errorf("%w: empty private key", ErrSigningFailed)
+	}
+
+	if message == "" {
+		return "", fmt.Errorf("%w: empty message", ErrInvalidMessage)
+	}
+
+	if err := checkMessageFormat(coinType, format); err != nil {
+		return "", err
+	}
+
+	switch format {
+	case MessageFormatEIP191:
+		prefixed := ethereumMessagePrefix + strconv.Itoa(len(message)) + message
+		signature, err := signSecp256k1(privateKey, keccak256([]byte(prefixed)))
+		if err != nil {
+			return "", err
+		}
+		signature[64] += 27
+		return "0x" + hex.EncodeToString(signature), nil
+
+	case MessageFormatBitcoin:
+		// BIP137 has no header for Taproot; those addresses need BIP322 signatures
+		if strings.HasPrefix(address, "bc1p") {
+			return "", fmt.Errorf("%w: bitcoin message signatures are not defined for taproot addresses", ErrUnsupportedMessageFormat)
+		}
+
+		signature, err := signSecp256k1(privateKey, bitcoinMessageDigest(message))
+		if err != nil {
+			return "", err
+		}
+
+		// Compact signature: header || r || s, where the header encodes the
+		// recovery id and the address type (BIP137)
+		compact := make([]byte, 65)
+		compact[0] = bitcoinSignatureHeader(address) + signature[64]
+		copy(compact[1:], signature[:64])
+
+		return base64.StdEncoding.EncodeToString(compact), nil
+
+	case MessageFormatSolanaOffchain:
+		envelope, err := solanaOffchainEnvelope(message)
+		if err != nil {
+			return "", err
+		}
+
+		signature, err := signEd25519(privateKey, envelope)
+		if err != nil {
+			return "", err
+		}
+
+		return base58Encode(signature), nil
+
+	default:
+		return "", g.typedDataUnsupported()
+	}
+}
+
+// VerifyMessage checks a signature produced by SignMessage or SignTransaction against a public key
+// It returns false for a well-formed signature that does not match, and an error
+// wrapping ErrInvalidMessage when the message or signature cannot be decoded
+func (g *GoEngine) VerifyMessage(publicKey []byte, coinType uint32, format string, message string, signature string) (bool, error) {
+	if len(publicKey) == 0 {
+		return false, fmt.Errorf("%w: empty public key", ErrInvalidMessage)
+	}
+
+	if message == "" || signature == "" {
+		return false, fmt.Errorf("%w: message and signature are required", ErrInvalidMessage)
+	}
+
+	if !g.isValidCoinType(coinType) {
+		return false, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if format != MessageFormatRaw {
+		if err := checkMessageFormat(coinType, format); err != nil {
+			return false, err
+		}
+	}
+
+	digest, sig, err := verificationInput(format, message, signature, func(string) ([]byte, error) {
+		return nil, g.typedDataUnsupported()
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if coinType == CoinTypeSolana {
+		if len(publicKey) != ed25519.PublicKeySize {
+			return false, fmt.Errorf("%w: stored public key is invalid", ErrInvalidMessage)
+		}
+		return len(sig) == ed25519.SignatureSize && ed25519.Verify(publicKey, digest, sig), nil
+	}
+
+	if len(publicKey) != 33 && len(publicKey) != 65 {
+		return false, fmt.Errorf("%w: unexpected public key length %d", ErrInvalidMessage, len(publicKey))
+	}
+
+	pubKey, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil {
+		return false, fmt.Errorf("%w: stored public key is invalid", ErrInvalidMessage)
+	}
+
+	// ECDSA verification operates on a 32-byte digest
+	if len(digest) != 32 {
+		return false, fmt.Errorf("%w: signed data must be a 32-byte digest", ErrInvalidMessage)
+	}
+
+	// secp256k1 signatures that are neither compact nor recoverable are DER encoded
+	if len(sig) != 64 && len(sig) != 65 {
+		parsed, err := ecdsa.ParseDERSignature(sig)
+		if err != nil {
+			return false, nil
+		}
+		return parsed.Verify(digest, pubKey), nil
+	}
+
+	// Only r||s is checked; the recovery id is not part of the signature proper
+	var r, s secp256k1.ModNScalar
+	if overflowR, overflowS := r.SetByteSlice(sig[:32]), s.SetByteSlice(sig[32:64]); overflowR || overflowS || r.IsZero() || s.IsZero() {
+		return false, nil
+	}
+
+	return ecdsa.NewSignature(&r, &s).Verify(digest, pubKey), nil
+}
+
+// typedDataUnsupported reports that EIP-712 hashing needs Trust Wallet Core
+func (g *GoEngine) typedDataUnsupported() error {
+	return fmt.Errorf("%w: %s requires the %s engine", ErrUnsupportedMessageFormat, MessageFormatEIP712, EngineTrustWalletCore)
+}
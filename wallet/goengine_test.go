@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestGoEngineSignInputEIP155(t *testing.T) {
+	engine := NewGoEngine()
+
+	// EIP-155 example transaction: nonce 9, 20 gwei, 21000 gas, 1 ether on chain 1
+	const description = `{"chain_id":1,"to":"0x3535353535353535353535353535353535353535","value":"1000000000000000000","nonce":"9","gas":"21000","gas_price":"20000000000"}`
+	const want = "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+
+	input, err := BuildSigningInput(CoinTypeEthereum, []byte(description), nil, "", "")
+	if err != nil {
+		t.Fatalf("BuildSigningInput() error = %v", err)
+	}
+
+	privateKey := bytes.Repeat([]byte{0x46}, 32)
+	signed, err := engine.SignInput(privateKey, CoinTypeEthereum, input)
+	if err != nil {
+		t.Fatalf("SignInput() error = %v", err)
+	}
+	if signed.RawTx != want {
+		t.Errorf("SignInput() raw tx = %s, want %s", signed.RawTx, want)
+	}
+
+	raw, _ := hex.DecodeString(want[2:])
+	if signed.TxHash != "0x"+hex.EncodeToString(keccak256(raw)) {
+		t.Errorf("SignInput() tx hash = %s does not match the raw transaction", signed.TxHash)
+	}
+}
+
+func TestGoEngineSignInputUnsupported(t *testing.T) {
+	engine := NewGoEngine()
+	privateKey := bytes.Repeat([]byte{0x46}, 32)
+
+	input, err := BuildSigningInput(CoinTypeSolana, []byte(`{"recipient":"11111111111111111111111111111111","lamports":1,"recent_blockhash":"11111111111111111111111111111111"}`), nil, "", "")
+	if err != nil {
+		t.Fatalf("BuildSigningInput() error = %v", err)
+	}
+	if _, err := engine.SignInput(privateKey, CoinTypeSolana, input); !errors.Is(err, ErrInvalidCoinType) {
+		t.Errorf("SignInput(solana) error = %v, want %v", err, ErrInvalidCoinType)
+	}
+
+	if _, err := engine.SignMessage(privateKey, CoinTypeEthereum, MessageFormatEIP712, `{}`, ""); !errors.Is(err, ErrUnsupportedMessageFormat) {
+		t.Errorf("SignMessage(eip712) error = %v, want %v", err, ErrUnsupportedMessageFormat)
+	}
+}
+
+func TestSegwitAddressRoundTrip(t *testing.T) {
+	tests := []string{
+		"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+	}
+
+	for _, address := range tests {
+		version, program, err := decodeSegwitAddress(bitcoinHRP, address)
+		if err != nil {
+			t.Fatalf("decodeSegwitAddress(%s) error = %v", address, err)
+		}
+		got, err := encodeSegwitAddress(bitcoinHRP, version, program)
+		if err != nil {
+			t.Fatalf("encodeSegwitAddress() error = %v", err)
+		}
+		if got != address {
+			t.Errorf("encodeSegwitAddress() = %s, want %s", got, address)
+		}
+	}
+
+	// A corrupted checksum is rejected
+	if _, _, err := decodeSegwitAddress(bitcoinHRP, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyv"); err == nil {
+		t.Error("decodeSegwitAddress() accepted a bad checksum")
+	}
+}
@@ -0,0 +1,52 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// Curve names reported for wallets
+const (
+	CurveSecp256k1              = "secp256k1"
+	CurveEd25519                = "ed25519"
+	CurveEd25519Blake2bNano     = "ed25519-blake2b-nano"
+	CurveCurve25519             = "curve25519"
+	CurveNist256p1              = "nist256p1"
+	CurveEd25519ExtendedCardano = "ed25519-extended-cardano"
+	CurveUnknown                = "unknown"
+)
+
+// Coin type constants for blockchains with chain-specific support
+// Every chain in the coin registry can be used for keys, addresses and raw signing
+const (
+	CoinTypeBitcoin  uint32 = 0   // TWCoinTypeBitcoin
+	CoinTypeEthereum uint32 = 60  // TWCoinTypeEthereum
+	CoinTypeSolana   uint32 = 501 // TWCoinTypeSolana
+)
+
+var (
+	ErrInvalidMnemonic     = errors.New("invalid mnemonic phrase")
+	ErrInvalidCoinType     = errors.New("invalid coin type")
+	ErrKeyGenerationFailed = errors.New("key generation failed")
+	ErrSigningFailed       = errors.New("transaction signing failed")
+	ErrAddressDerivation   = errors.New("address derivation failed")
+)
+
+// WalletKeys contains the key material for a wallet
+type WalletKeys struct {
+	Mnemonic   string
+	PrivateKey []byte
+	PublicKey  []byte
+	Address    string
+	Curve      string
+}
+
+// GetPrivateKeyHex returns the private key as a hexadecimal string
+func GetPrivateKeyHex(privateKey []byte) string {
+	return hex.EncodeToString(privateKey)
+}
+
+// GetPublicKeyHex returns the public key as a hexadecimal string
+func GetPublicKeyHex(publicKey []byte) string {
+	return hex.EncodeToString(publicKey)
+}
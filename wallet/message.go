@@ -0,0 +1,209 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Message signing formats
+const (
+	MessageFormatEIP191         = "eip191"
+	MessageFormatEIP712         = "eip712"
+	MessageFormatBitcoin        = "bitcoin"
+	MessageFormatSolanaOffchain = "solana-offchain"
+)
+
+// MessageFormatRaw verifies signatures returned by SignTransaction: the message is the
+// base64-encoded signed data and the signature is the base64-encoded signature
+const MessageFormatRaw = "raw"
+
+// ethereumMessagePrefix is the EIP-191 personal message prefix
+const ethereumMessagePrefix = "\x19Ethereum Signed Message:\n"
+
+// bitcoinMessagePrefix is the domain separator for Bitcoin signed messages
+const bitcoinMessagePrefix = "Bitcoin Signed Message:\n"
+
+// solanaOffchainDomain is the signing domain for Solana off-chain messages
+const solanaOffchainDomain = "\xffsolana offchain"
+
+// Solana off-chain message limits (version 0)
+const (
+	solanaOffchainMaxLimited  = 1212
+	solanaOffchainMaxExtended = 65515
+)
+
+var (
+	// ErrInvalidMessage is returned when a message cannot be signed in the requested format
+	ErrInvalidMessage = errors.New("invalid message")
+	// ErrUnsupportedMessageFormat is returned when a format is unknown or does not match the wallet's chain
+	ErrUnsupportedMessageFormat = errors.New("unsupported message format")
+)
+
+// messageFormatCoins maps each message format to the coin type it applies to
+var messageFormatCoins = map[string]uint32{
+	MessageFormatEIP191:         CoinTypeEthereum,
+	MessageFormatEIP712:         CoinTypeEthereum,
+	MessageFormatBitcoin:        CoinTypeBitcoin,
+	MessageFormatSolanaOffchain: CoinTypeSolana,
+}
+
+// DefaultMessageFormat returns the message format used when none is requested
+func DefaultMessageFormat(coinType uint32) string {
+	switch coinType {
+	case CoinTypeEthereum:
+		return MessageFormatEIP191
+	case CoinTypeBitcoin:
+		return MessageFormatBitcoin
+	case CoinTypeSolana:
+		return MessageFormatSolanaOffchain
+	default:
+		return ""
+	}
+}
+
+// checkMessageFormat checks that a message format exists and applies to the coin type
+func checkMessageFormat(coinType uint32, format string) error {
+	formatCoin, ok := messageFormatCoins[format]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedMessageFormat, format)
+	}
+	if formatCoin != coinType {
+		return fmt.Errorf("%w: %s cannot be used with coin type %d", ErrUnsupportedMessageFormat, format, coinType)
+	}
+	return nil
+}
+
+// bitcoinMessageDigest returns the double SHA-256 of the prefixed Bitcoin message
+func bitcoinMessageDigest(message string) []byte {
+	var payload []byte
+	payload = appendCompactSize(payload, uint64(len(bitcoinMessagePrefix)))
+	payload = append(payload, bitcoinMessagePrefix...)
+	payload = appendCompactSize(payload, uint64(len(message)))
+	payload = append(payload, message...)
+
+	return doubleSHA256(payload)
+}
+
+// bitcoinSignatureHeader returns the BIP137 header base for an address type
+func bitcoinSignatureHeader(address string) byte {
+	switch {
+	case strings.HasPrefix(address, "bc1q"):
+		// P2WPKH
+		return 39
+	case strings.HasPrefix(address, "3"):
+		// P2SH-P2WPKH
+		return 35
+	default:
+		// P2PKH with a compressed public key
+		return 31
+	}
+}
+
+// appendCompactSize appends a Bitcoin variable-length integer
+func appendCompactSize(b []byte, n uint64) []byte {
+	switch {
+	case n < 0xfd:
+		return append(b, byte(n))
+	case n <= 0xffff:
+		return binary.LittleEndian.AppendUint16(append(b, 0xfd), uint16(n))
+	case n <= 0xffffffff:
+		return binary.LittleEndian.AppendUint32(append(b, 0xfe), uint32(n))
+	default:
+		return binary.LittleEndian.AppendUint64(append(b, 0xff), n)
+	}
+}
+
+// solanaOffchainEnvelope builds a version 0 Solana off-chain message:
+// signing domain || version || format || length (u16 LE) || message
+func solanaOffchainEnvelope(message string) ([]byte, error) {
+	var format byte
+	switch {
+	case isRestrictedASCII(message) && len(message) <= solanaOffchainMaxLimited:
+		format = 0
+	case len(message) <= solanaOffchainMaxLimited:
+		format = 1
+	case len(message) <= solanaOffchainMaxExtended:
+		format = 2
+	default:
+		return nil, fmt.Errorf("%w: message exceeds %d bytes", ErrInvalidMessage, solanaOffchainMaxExtended)
+	}
+
+	envelope := make([]byte, 0, len(solanaOffchainDomain)+4+len(message))
+	envelope = append(envelope, solanaOffchainDomain...)
+	envelope = append(envelope, 0, format)
+	envelope = binary.LittleEndian.AppendUint16(envelope, uint16(len(message)))
+	envelope = append(envelope, message...)
+
+	return envelope, nil
+}
+
+// isRestrictedASCII reports whether s only contains printable ASCII characters
+func isRestrictedASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// verificationInput decodes a signature and computes the signed payload for a format
+// EIP-712 digests are computed by the engine's typedDataHash
+func verificationInput(format string, message string, signature string, typedDataHash func(string) ([]byte, error)) ([]byte, []byte, error) {
+	switch format {
+	case MessageFormatEIP191:
+		sig, err := decodeHex(signature)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: signature must be hex", ErrInvalidMessage)
+		}
+		prefixed := ethereumMessagePrefix + strconv.Itoa(len(message)) + message
+		return keccak256([]byte(prefixed)), sig, nil
+
+	case MessageFormatEIP712:
+		sig, err := decodeHex(signature)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: signature must be hex", ErrInvalidMessage)
+		}
+		digest, err := typedDataHash(message)
+		if err != nil {
+			return nil, nil, err
+		}
+		return digest, sig, nil
+
+	case MessageFormatBitcoin:
+		compact, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil || len(compact) != 65 {
+			return nil, nil, fmt.Errorf("%w: signature must be a base64 65-byte compact signature", ErrInvalidMessage)
+		}
+		if compact[0] < 27 || compact[0] > 42 {
+			return nil, nil, fmt.Errorf("%w: invalid compact signature header %d", ErrInvalidMessage, compact[0])
+		}
+		return bitcoinMessageDigest(message), compact[1:], nil
+
+	case MessageFormatSolanaOffchain:
+		sig, err := base58Decode(signature)
+		if err != nil || len(sig) != 64 {
+			return nil, nil, fmt.Errorf("%w: signature must be a base58 64-byte signature", ErrInvalidMessage)
+		}
+		envelope, err := solanaOffchainEnvelope(message)
+		if err != nil {
+			return nil, nil, err
+		}
+		return envelope, sig, nil
+
+	default:
+		data, err := base64.StdEncoding.DecodeString(message)
+		if err != nil || len(data) == 0 {
+			return nil, nil, fmt.Errorf("%w: raw message must be base64-encoded", ErrInvalidMessage)
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature)
+		if err != nil || len(sig) == 0 {
+			return nil, nil, fmt.Errorf("%w: raw signature must be base64-encoded", ErrInvalidMessage)
+		}
+		return data, sig, nil
+	}
+}
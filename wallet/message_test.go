@@ -0,0 +1,169 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignMessageEncodings(t *testing.T) {
+	engine := testEngine(t)
+
+	tests := []struct {
+		name     string
+		coinType uint32
+		format   string
+		message  string
+		check    func(t *testing.T, signature string)
+	}{
+		{
+			name:     "eip191",
+			coinType: CoinTypeEthereum,
+			format:   MessageFormatEIP191,
+			message:  "hello",
+			check: func(t *testing.T, signature string) {
+				raw, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+				if err != nil || len(raw) != 65 {
+					t.Fatalf("signature %q is not 65 bytes of hex", signature)
+				}
+				if v := raw[64]; v != 27 && v != 28 {
+					t.Errorf("v = %d, want 27 or 28", v)
+				}
+			},
+		},
+		{
+			name:     "bitcoin",
+			coinType: CoinTypeBitcoin,
+			format:   MessageFormatBitcoin,
+			message:  "hello",
+			check: func(t *testing.T, signature string) {
+				raw, err := base64.StdEncoding.DecodeString(signature)
+				if err != nil || len(raw) != 65 {
+					t.Fatalf("signature %q is not 65 bytes of base64", signature)
+				}
+				// The test wallet uses a native SegWit address
+				if h := raw[0]; h < 39 || h > 42 {
+					t.Errorf("header = %d, want P2WPKH header 39-42", h)
+				}
+			},
+		},
+		{
+			name:     "solana offchain",
+			coinType: CoinTypeSolana,
+			format:   MessageFormatSolanaOffchain,
+			message:  "hello",
+			check: func(t *testing.T, signature string) {
+				raw, err := base58Decode(signature)
+				if err != nil || len(raw) != 64 {
+					t.Fatalf("signature %q is not 64 bytes of base58", signature)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := engine.ImportWallet(testMnemonic, "", tt.coinType)
+			if err != nil {
+				t.Fatalf("ImportWallet() error = %v", err)
+			}
+
+			signature, err := engine.SignMessage(keys.PrivateKey, tt.coinType, tt.format, tt.message, keys.Address)
+			if err != nil {
+				t.Fatalf("SignMessage() error = %v", err)
+			}
+			tt.check(t, signature)
+		})
+	}
+}
+
+func TestSignMessageRejectsMismatchedFormat(t *testing.T) {
+	engine := testEngine(t)
+
+	keys, err := engine.ImportWallet(testMnemonic, "", CoinTypeSolana)
+	if err != nil {
+		t.Fatalf("ImportWallet() error = %v", err)
+	}
+
+	_, err = engine.SignMessage(keys.PrivateKey, CoinTypeSolana, MessageFormatEIP191, "hello", keys.Address)
+	if !errors.Is(err, ErrUnsupportedMessageFormat) {
+		t.Errorf("SignMessage() error = %v, want %v", err, ErrUnsupportedMessageFormat)
+	}
+}
+
+func TestSolanaOffchainEnvelope(t *testing.T) {
+	envelope, err := solanaOffchainEnvelope("hi")
+	if err != nil {
+		t.Fatalf("solanaOffchainEnvelope() error = %v", err)
+	}
+
+	want := "ff736f6c616e61206f6666636861696e" + // "\xffsolana offchain"
+		"00" + // version
+		"00" + // restricted ASCII
+		"0200" + // length
+		"6869"
+	if got := hex.EncodeToString(envelope); got != want {
+		t.Errorf("envelope = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyMessageRoundTrip(t *testing.T) {
+	engine := testEngine(t)
+
+	tests := []struct {
+		name     string
+		coinType uint32
+		format   string
+	}{
+		{name: "eip191", coinType: CoinTypeEthereum, format: MessageFormatEIP191},
+		{name: "bitcoin", coinType: CoinTypeBitcoin, format: MessageFormatBitcoin},
+		{name: "solana offchain", coinType: CoinTypeSolana, format: MessageFormatSolanaOffchain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := engine.ImportWallet(testMnemonic, "", tt.coinType)
+			if err != nil {
+				t.Fatalf("ImportWallet() error = %v", err)
+			}
+
+			signature, err := engine.SignMessage(keys.PrivateKey, tt.coinType, tt.format, "hello", keys.Address)
+			if err != nil {
+				t.Fatalf("SignMessage() error = %v", err)
+			}
+
+			valid, err := engine.VerifyMessage(keys.PublicKey, tt.coinType, tt.format, "hello", signature)
+			if err != nil || !valid {
+				t.Errorf("VerifyMessage() = %v, %v, want true", valid, err)
+			}
+
+			valid, err = engine.VerifyMessage(keys.PublicKey, tt.coinType, tt.format, "goodbye", signature)
+			if err != nil || valid {
+				t.Errorf("VerifyMessage() with another message = %v, %v, want false", valid, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRawSignature(t *testing.T) {
+	engine := testEngine(t)
+	digest := []byte(strings.Repeat("\x01", 32))
+
+	keys, err := engine.ImportWallet(testMnemonic, "", CoinTypeEthereum)
+	if err != nil {
+		t.Fatalf("ImportWallet() error = %v", err)
+	}
+
+	signature, err := engine.SignTransaction(keys.PrivateKey, CoinTypeEthereum, digest)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+
+	valid, err := engine.VerifyMessage(keys.PublicKey, CoinTypeEthereum, MessageFormatRaw,
+		base64.StdEncoding.EncodeToString(digest), base64.StdEncoding.EncodeToString(signature))
+	if err != nil || !valid {
+		t.Errorf("VerifyMessage() = %v, %v, want true", valid, err)
+	}
+}
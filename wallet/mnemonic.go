@@ -0,0 +1,72 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// WordlistEnglish is the BIP39 wordlist used for mnemonics
+// Trust Wallet Core only ships the English wordlist
+const WordlistEnglish = "english"
+
+// DefaultWordCount is the number of mnemonic words generated when none is configured
+const DefaultWordCount = 12
+
+// ErrInvalidWordCount is returned when a mnemonic length is not a BIP39 word count
+var ErrInvalidWordCount = errors.New("invalid mnemonic word count")
+
+// mnemonicStrengths maps each BIP39 word count to its entropy in bits
+var mnemonicStrengths = map[int]int{
+	12: 128,
+	15: 160,
+	18: 192,
+	21: 224,
+	24: 256,
+}
+
+// MnemonicStrength returns the entropy in bits of a mnemonic with wordCount words
+func MnemonicStrength(wordCount int) (int, error) {
+	strength, ok := mnemonicStrengths[wordCount]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d (must be 12, 15, 18, 21 or 24)", ErrInvalidWordCount, wordCount)
+	}
+	return strength, nil
+}
+
+// ValidateWordlist checks that a wordlist name is supported
+// An empty name selects the English wordlist
+func ValidateWordlist(wordlist string) error {
+	if wordlist != "" && !strings.EqualFold(wordlist, WordlistEnglish) {
+		return fmt.Errorf("%w: unsupported wordlist %q, only %s is available", ErrInvalidMnemonic, wordlist, WordlistEnglish)
+	}
+	return nil
+}
+
+// MnemonicWords splits a mnemonic into its words, ignoring extra whitespace
+func MnemonicWords(mnemonic string) []string {
+	return strings.Fields(mnemonic)
+}
+
+// validateMnemonic checks the word count, the wordlist and the checksum of a mnemonic
+// Errors identify words by position only, so the phrase never ends up in responses or logs
+func validateMnemonic(mnemonic string) error {
+	words := MnemonicWords(mnemonic)
+	if _, err := MnemonicStrength(len(words)); err != nil {
+		return fmt.Errorf("%w: mnemonic has %d words, must be 12, 15, 18, 21 or 24", ErrInvalidMnemonic, len(words))
+	}
+
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); !ok {
+			return fmt.Errorf("%w: word %d is not in the %s wordlist", ErrInvalidMnemonic, i+1, WordlistEnglish)
+		}
+	}
+
+	if _, err := bip39.MnemonicToByteArray(strings.Join(words, " ")); err != nil {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMnemonicStrength(t *testing.T) {
+	tests := []struct {
+		wordCount int
+		want      int
+		wantErr   bool
+	}{
+		{wordCount: 12, want: 128},
+		{wordCount: 15, want: 160},
+		{wordCount: 18, want: 192},
+		{wordCount: 21, want: 224},
+		{wordCount: 24, want: 256},
+		{wordCount: 0, wantErr: true},
+		{wordCount: 13, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := MnemonicStrength(tt.wordCount)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("MnemonicStrength(%d) error = %v, wantErr %v", tt.wordCount, err, tt.wantErr)
+		}
+		if tt.wantErr && !errors.Is(err, ErrInvalidWordCount) {
+			t.Errorf("MnemonicStrength(%d) error = %v, want %v", tt.wordCount, err, ErrInvalidWordCount)
+		}
+		if got != tt.want {
+			t.Errorf("MnemonicStrength(%d) = %d, want %d", tt.wordCount, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWordlist(t *testing.T) {
+	for _, wordlist := range []string{"", "english", "English"} {
+		if err := ValidateWordlist(wordlist); err != nil {
+			t.Errorf("ValidateWordlist(%q) error = %v", wordlist, err)
+		}
+	}
+	if err := ValidateWordlist("japanese"); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Errorf("ValidateWordlist(japanese) error = %v, want %v", err, ErrInvalidMnemonic)
+	}
+}
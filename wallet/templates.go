@@ -0,0 +1,437 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ErrInvalidTransaction is returned when a JSON transaction description is invalid
+var ErrInvalidTransaction = errors.New("invalid transaction description")
+
+// Bitcoin signing constants
+const (
+	bitcoinSigHashAll      = 1
+	bitcoinDefaultSequence = 0xffffffff
+)
+
+// Quantity is a non-negative integer given as a JSON number, a decimal string or a 0x-prefixed hex string
+type Quantity struct {
+	value *big.Int
+}
+
+// UnmarshalJSON parses a quantity from a JSON number or string
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		q.value = nil
+		return nil
+	}
+
+	v := new(big.Int)
+	var ok bool
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		_, ok = v.SetString(s[2:], 16)
+	} else {
+		_, ok = v.SetString(s, 10)
+	}
+	if !ok || v.Sign() < 0 {
+		return fmt.Errorf("invalid quantity %q", s)
+	}
+
+	q.value = v
+	return nil
+}
+
+// isSet reports whether the quantity was provided
+func (q Quantity) isSet() bool {
+	return q.value != nil
+}
+
+// bytes returns the quantity as minimal big-endian bytes
+func (q Quantity) bytes() []byte {
+	if q.value == nil {
+		return nil
+	}
+	return q.value.Bytes()
+}
+
+// EthereumTransaction describes an Ethereum transfer or contract call
+// Either GasPrice (legacy) or MaxFeePerGas and MaxPriorityFeePerGas (EIP-1559) must be set
+type EthereumTransaction struct {
+	To                   string   `json:"to"`
+	Value                Quantity `json:"value"`
+	Nonce                Quantity `json:"nonce"`
+	Gas                  Quantity `json:"gas"`
+	GasPrice             Quantity `json:"gas_price"`
+	MaxFeePerGas         Quantity `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas Quantity `json:"max_priority_fee_per_gas"`
+	ChainID              Quantity `json:"chain_id"`
+	Data                 string   `json:"data"`
+}
+
+// BitcoinUTXO is an unspent output owned by the wallet
+type BitcoinUTXO struct {
+	TxID   string `json:"txid"`
+	Vout   uint32 `json:"vout"`
+	Amount int64  `json:"amount"`
+	// Script is the hex scriptPubKey; defaults to the wallet's script for its address type
+	Script string `json:"script"`
+}
+
+// BitcoinOutput is a payment to an address in satoshis
+type BitcoinOutput struct {
+	Address string `json:"address"`
+	Amount  int64  `json:"amount"`
+}
+
+// BitcoinTransaction describes a Bitcoin payment to one or more outputs funded by the given UTXOs
+// FeeRate is in satoshis per virtual byte; change goes to ChangeAddress or the wallet address
+type BitcoinTransaction struct {
+	UTXOs         []BitcoinUTXO   `json:"utxos"`
+	Outputs       []BitcoinOutput `json:"outputs"`
+	FeeRate       int64           `json:"fee_rate"`
+	ChangeAddress string          `json:"change_address"`
+}
+
+// SolanaTransaction describes a native SOL transfer
+type SolanaTransaction struct {
+	Recipient       string `json:"recipient"`
+	Lamports        uint64 `json:"lamports"`
+	RecentBlockhash string `json:"recent_blockhash"`
+	Memo            string `json:"memo"`
+}
+
+// BuildSigningInput converts a JSON transaction description into a Trust Wallet Core
+// SigningInput protobuf for the coin type. The private key is left unset and is
+// injected by SignInput. publicKey, address and addressType belong to the signing
+// wallet and are used for defaults such as the Bitcoin change address and UTXO scripts.
+func BuildSigningInput(coinType uint32, description []byte, publicKey []byte, address string, addressType string) ([]byte, error) {
+	switch coinType {
+	case CoinTypeEthereum:
+		var tx EthereumTransaction
+		if err := decodeDescription(description, &tx); err != nil {
+			return nil, err
+		}
+		return buildEthereumInput(&tx)
+	case CoinTypeBitcoin:
+		var tx BitcoinTransaction
+		if err := decodeDescription(description, &tx); err != nil {
+			return nil, err
+		}
+		return buildBitcoinInput(&tx, publicKey, address, addressType)
+	case CoinTypeSolana:
+		var tx SolanaTransaction
+		if err := decodeDescription(description, &tx); err != nil {
+			return nil, err
+		}
+		return buildSolanaInput(&tx)
+	default:
+		return nil, fmt.Errorf("%w: transaction templates not supported for coin type %d", ErrInvalidCoinType, coinType)
+	}
+}
+
+// EVMChain is the EVM chain an Ethereum transaction is signed for
+type EVMChain struct {
+	ChainID uint64
+	EIP1559 bool
+}
+
+// BuildEVMSigningInput converts an Ethereum JSON transaction description into a
+// SigningInput for an EVM chain. chain_id defaults to the chain's ID and must match
+// it when given; EIP-1559 fee fields are rejected on chains without EIP-1559.
+func BuildEVMSigningInput(description []byte, chain EVMChain) ([]byte, error) {
+	var tx EthereumTransaction
+	if err := decodeDescription(description, &tx); err != nil {
+		return nil, err
+	}
+
+	chainID := new(big.Int).SetUint64(chain.ChainID)
+	if tx.ChainID.isSet() && tx.ChainID.value.Cmp(chainID) != 0 {
+		return nil, fmt.Errorf("%w: chain_id %s does not match the chain profile's %s", ErrInvalidTransaction, tx.ChainID.value, chainID)
+	}
+	tx.ChainID = Quantity{value: chainID}
+
+	if !chain.EIP1559 && (tx.MaxFeePerGas.isSet() || tx.MaxPriorityFeePerGas.isSet()) {
+		return nil, fmt.Errorf("%w: chain does not support EIP-1559 fee fields, use gas_price", ErrInvalidTransaction)
+	}
+
+	return buildEthereumInput(&tx)
+}
+
+// decodeDescription strictly decodes a JSON transaction description
+func decodeDescription(description []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(description))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTransaction, err)
+	}
+	return nil
+}
+
+// buildEthereumInput encodes an Ethereum SigningInput (see Ethereum.proto)
+func buildEthereumInput(tx *EthereumTransaction) ([]byte, error) {
+	if !isHexAddress(tx.To, 20) {
+		return nil, fmt.Errorf("%w: to must be a 0x-prefixed 20-byte address", ErrInvalidTransaction)
+	}
+	if !tx.ChainID.isSet() || tx.ChainID.value.Sign() == 0 {
+		return nil, fmt.Errorf("%w: chain_id is required", ErrInvalidTransaction)
+	}
+	if !tx.Nonce.isSet() {
+		return nil, fmt.Errorf("%w: nonce is required", ErrInvalidTransaction)
+	}
+	if !tx.Gas.isSet() || tx.Gas.value.Sign() == 0 {
+		return nil, fmt.Errorf("%w: gas is required", ErrInvalidTransaction)
+	}
+
+	eip1559 := tx.MaxFeePerGas.isSet() || tx.MaxPriorityFeePerGas.isSet()
+	switch {
+	case eip1559 && tx.GasPrice.isSet():
+		return nil, fmt.Errorf("%w: gas_price cannot be combined with EIP-1559 fee fields", ErrInvalidTransaction)
+	case eip1559 && (!tx.MaxFeePerGas.isSet() || !tx.MaxPriorityFeePerGas.isSet()):
+		return nil, fmt.Errorf("%w: max_fee_per_gas and max_priority_fee_per_gas are both required", ErrInvalidTransaction)
+	case !eip1559 && !tx.GasPrice.isSet():
+		return nil, fmt.Errorf("%w: gas_price or EIP-1559 fee fields are required", ErrInvalidTransaction)
+	}
+
+	data, err := decodeHex(tx.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: data must be hex-encoded", ErrInvalidTransaction)
+	}
+
+	// Transaction.transfer { amount = 1; data = 2 }
+	var transfer []byte
+	transfer = appendBytesField(transfer, 1, tx.Value.bytes())
+	transfer = appendBytesField(transfer, 2, data)
+	var transaction []byte
+	transaction = appendBytesField(transaction, 1, transfer)
+
+	var input []byte
+	input = appendBytesField(input, 1, tx.ChainID.bytes())
+	input = appendBytesField(input, 2, tx.Nonce.bytes())
+	if eip1559 {
+		// TransactionMode.Enveloped
+		input = appendVarintField(input, 3, 1)
+		input = appendBytesField(input, 6, tx.MaxPriorityFeePerGas.bytes())
+		input = appendBytesField(input, 7, tx.MaxFeePerGas.bytes())
+	} else {
+		input = appendBytesField(input, 4, tx.GasPrice.bytes())
+	}
+	input = appendBytesField(input, 5, tx.Gas.bytes())
+	input = appendBytesField(input, 8, []byte(tx.To))
+	input = appendBytesField(input, 10, transaction)
+
+	return input, nil
+}
+
+// buildBitcoinInput encodes a Bitcoin SigningInput (see Bitcoin.proto)
+// UTXOs without a script are assumed to pay the wallet key in its address type
+func buildBitcoinInput(tx *BitcoinTransaction, publicKey []byte, address string, addressType string) ([]byte, error) {
+	if len(tx.UTXOs) == 0 {
+		return nil, fmt.Errorf("%w: at least one utxo is required", ErrInvalidTransaction)
+	}
+	if len(tx.Outputs) == 0 {
+		return nil, fmt.Errorf("%w: at least one output is required", ErrInvalidTransaction)
+	}
+	if tx.FeeRate <= 0 {
+		return nil, fmt.Errorf("%w: fee_rate must be positive", ErrInvalidTransaction)
+	}
+
+	for i, output := range tx.Outputs {
+		if output.Address == "" {
+			return nil, fmt.Errorf("%w: outputs[%d].address is required", ErrInvalidTransaction, i)
+		}
+		if output.Amount <= 0 {
+			return nil, fmt.Errorf("%w: outputs[%d].amount must be positive", ErrInvalidTransaction, i)
+		}
+	}
+
+	changeAddress := tx.ChangeAddress
+	if changeAddress == "" {
+		changeAddress = address
+	}
+
+	// The first output is the payment; later ones are sent as extra_outputs
+	var input []byte
+	input = appendVarintField(input, 1, bitcoinSigHashAll)
+	input = appendVarintField(input, 2, uint64(tx.Outputs[0].Amount))
+	input = appendVarintField(input, 3, uint64(tx.FeeRate))
+	input = appendBytesField(input, 4, []byte(tx.Outputs[0].Address))
+	input = appendBytesField(input, 5, []byte(changeAddress))
+
+	for i, utxo := range tx.UTXOs {
+		txid, err := hex.DecodeString(utxo.TxID)
+		if err != nil || len(txid) != 32 {
+			return nil, fmt.Errorf("%w: utxos[%d].txid must be a 32-byte hex string", ErrInvalidTransaction, i)
+		}
+		if utxo.Amount <= 0 {
+			return nil, fmt.Errorf("%w: utxos[%d].amount must be positive", ErrInvalidTransaction, i)
+		}
+
+		var script []byte
+		if utxo.Script != "" {
+			script, err = hex.DecodeString(utxo.Script)
+			if err != nil {
+				return nil, fmt.Errorf("%w: utxos[%d].script must be hex-encoded", ErrInvalidTransaction, i)
+			}
+		} else {
+			script, err = bitcoinScript(publicKey, addressType)
+			if err != nil {
+				return nil, fmt.Errorf("%w: utxos[%d].script is required for this wallet: %v", ErrInvalidTransaction, i, err)
+			}
+		}
+
+		// OutPoint hashes are in internal byte order, the reverse of the displayed txid
+		for l, r := 0, len(txid)-1; l < r; l, r = l+1, r-1 {
+			txid[l], txid[r] = txid[r], txid[l]
+		}
+
+		// OutPoint { hash = 1; index = 2; sequence = 3 }
+		var outPoint []byte
+		outPoint = appendBytesField(outPoint, 1, txid)
+		outPoint = appendVarintField(outPoint, 2, uint64(utxo.Vout))
+		outPoint = appendVarintField(outPoint, 3, bitcoinDefaultSequence)
+
+		// UnspentTransaction { out_point = 1; script = 2; amount = 3 }
+		var unspent []byte
+		unspent = appendBytesField(unspent, 1, outPoint)
+		unspent = appendBytesField(unspent, 2, script)
+		unspent = appendVarintField(unspent, 3, uint64(utxo.Amount))
+
+		input = appendBytesField(input, 8, unspent)
+	}
+
+	input = appendVarintField(input, 10, uint64(CoinTypeBitcoin))
+
+	// OutputAddress { to_address = 1; amount = 2 }
+	for _, output := range tx.Outputs[1:] {
+		var extra []byte
+		extra = appendBytesField(extra, 1, []byte(output.Address))
+		extra = appendVarintField(extra, 2, uint64(output.Amount))
+
+		input = appendBytesField(input, 14, extra)
+	}
+
+	return input, nil
+}
+
+// buildSolanaInput encodes a Solana SigningInput (see Solana.proto)
+func buildSolanaInput(tx *SolanaTransaction) ([]byte, error) {
+	if !isBase58(tx.Recipient, 32, 44) {
+		return nil, fmt.Errorf("%w: recipient must be a base58 account address", ErrInvalidTransaction)
+	}
+	if !isBase58(tx.RecentBlockhash, 32, 44) {
+		return nil, fmt.Errorf("%w: recent_blockhash must be a base58 blockhash", ErrInvalidTransaction)
+	}
+	if tx.Lamports == 0 {
+		return nil, fmt.Errorf("%w: lamports must be positive", ErrInvalidTransaction)
+	}
+
+	// Transfer { recipient = 1; value = 2; memo = 3 }
+	var transfer []byte
+	transfer = appendBytesField(transfer, 1, []byte(tx.Recipient))
+	transfer = appendVarintField(transfer, 2, tx.Lamports)
+	if tx.Memo != "" {
+		transfer = appendBytesField(transfer, 3, []byte(tx.Memo))
+	}
+
+	var input []byte
+	input = appendBytesField(input, 2, []byte(tx.RecentBlockhash))
+	input = appendBytesField(input, 4, transfer)
+
+	return input, nil
+}
+
+// appendBytesField appends a length-delimited protobuf field, skipping empty values
+func appendBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendVarintField appends a varint protobuf field, skipping zero values
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// bitcoinScript returns the scriptPubKey paying a compressed public key in an address type
+// An empty address type is the default P2WPKH
+func bitcoinScript(publicKey []byte, addressType string) ([]byte, error) {
+	if len(publicKey) != 33 {
+		return nil, errors.New("no compressed public key")
+	}
+	pubKey, err := secp256k1.ParsePubKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyHash := hash160(publicKey)
+	witnessProgram := append([]byte{0x00, 0x14}, keyHash...)
+
+	switch addressType {
+	case AddressTypeP2PKH:
+		// OP_DUP OP_HASH160 <hash160(pubkey)> OP_EQUALVERIFY OP_CHECKSIG
+		script := append([]byte{0x76, 0xa9, 0x14}, keyHash...)
+		return append(script, 0x88, 0xac), nil
+
+	case AddressTypeP2SHP2WPKH:
+		// OP_HASH160 <hash160(0 <hash160(pubkey)>)> OP_EQUAL
+		script := append([]byte{0xa9, 0x14}, hash160(witnessProgram)...)
+		return append(script, 0x87), nil
+
+	case "", AddressTypeP2WPKH:
+		return witnessProgram, nil
+
+	case AddressTypeP2TR:
+		// OP_1 <BIP86 output key>
+		outputKey, err := taprootOutputKey(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{0x51, 0x20}, outputKey...), nil
+
+	default:
+		return nil, fmt.Errorf("no default script for address type %q", addressType)
+	}
+}
+
+// decodeHex decodes an optionally 0x-prefixed hex string
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	return hex.DecodeString(s)
+}
+
+// isHexAddress reports whether s is a 0x-prefixed hex string of size bytes
+func isHexAddress(s string, size int) bool {
+	if !strings.HasPrefix(s, "0x") || len(s) != 2+2*size {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
+
+// isBase58 reports whether s uses the base58 alphabet and has a plausible length
+func isBase58(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", r) {
+			return false
+		}
+	}
+	return true
+}
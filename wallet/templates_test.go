@@ -0,0 +1,186 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// TestBuildSigningInputGolden compares the built SigningInputs byte for byte with
+// encodings of the Trust Wallet Core messages framed by hand
+func TestBuildSigningInputGolden(t *testing.T) {
+	// Compressed secp256k1 generator point; its P2WPKH script hashes to 751e76e8...
+	publicKey, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	const address = "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"
+
+	tests := []struct {
+		name        string
+		coinType    uint32
+		description string
+		want        string
+	}{
+		{
+			// chain_id=1 nonce=9 gas_price=20 gwei gas=21000 to transfer{amount=1 ether}
+			name:        "ethereum legacy",
+			coinType:    CoinTypeEthereum,
+			description: `{"chain_id":1,"to":"0x3535353535353535353535353535353535353535","value":"1000000000000000000","nonce":"9","gas":"21000","gas_price":"20000000000"}`,
+			want: "0a0101" + "120109" + "220504a817c800" + "2a025208" +
+				"422a3078" + "33353335333533353335333533353335333533353335333533353335333533353335333533353335" +
+				"520c0a0a0a080de0b6b3a7640000",
+		},
+		{
+			// chain_id=1 tx_mode=Enveloped max_inclusion_fee=1 max_fee=2 gas=21000 to transfer{data}; zero nonce and value are omitted
+			name:        "ethereum eip1559",
+			coinType:    CoinTypeEthereum,
+			description: `{"chain_id":1,"to":"0x3535353535353535353535353535353535353535","value":"0","nonce":"0","gas":"0x5208","max_fee_per_gas":"2","max_priority_fee_per_gas":"1","data":"0xa9059cbb"}`,
+			want: "0a0101" + "1801" + "320101" + "3a0102" + "2a025208" +
+				"422a3078" + "33353335333533353335333533353335333533353335333533353335333533353335333533353335" +
+				"52080a061204a9059cbb",
+		},
+		{
+			// hash_type=1 amount=50000 byte_fee=10 to_address change_address utxo{out_point script amount} extra_outputs{to_address amount}
+			name:     "bitcoin",
+			coinType: CoinTypeBitcoin,
+			description: `{"utxos":[{"txid":"0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20","vout":1,"amount":100000}],` +
+				`"outputs":[{"address":"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2","amount":50000},{"address":"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy","amount":20000}],"fee_rate":10}`,
+			want: "0801" + "10d08603" + "180a" +
+				"2222314276424d53455973745765747154466e354175346d3447466737784a614e564e32" +
+				"2a2a6263317177353038643671656a7874646734793572337a6172766172793063357877376b763866337434" +
+				"4248" + "0a2a" + "0a20201f1e1d1c1b1a191817161514131211100f0e0d0c0b0a090807060504030201" + "1001" + "18ffffffff0f" +
+				"12160014751e76e8199196d454941c45d1b3a323f1433bd6" + "18a08d06" +
+				"7228" + "0a22334a393874315770455a3733434e6d5176696563726e796957726e715268574e4c79" + "10a09c01",
+		},
+		{
+			// recent_blockhash transfer{recipient value=1000 memo}
+			name:        "solana",
+			coinType:    CoinTypeSolana,
+			description: `{"recipient":"EN2sCsJ1WDV8UFqsiTXHcUPUxQ4juE71eCknHYYMifkd","lamports":1000,"recent_blockhash":"9ipJh5xfyoyDaiq8trtrdqQeAhQbQkWy2eANizKvx75K","memo":"hi"}`,
+			want: "122c3969704a68357866796f794461697138747274726471516541685162516b57793265414e697a4b767837354b" +
+				"2235" + "0a2c454e327343734a31574456385546717369545848635550557851346a7545373165436b6e4859594d69666b64" + "10e807" + "1a026869",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildSigningInput(tt.coinType, []byte(tt.description), publicKey, address, "")
+			if err != nil {
+				t.Fatalf("BuildSigningInput() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("BuildSigningInput() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildBitcoinInputDefaultScript(t *testing.T) {
+	publicKey, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	const description = `{"utxos":[{"txid":"0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20","vout":0,"amount":1000}],` +
+		`"outputs":[{"address":"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2","amount":500}],"fee_rate":1}`
+
+	// scriptPubKeys of the generator point in each address type
+	tests := []struct {
+		addressType string
+		want        string
+	}{
+		{addressType: "", want: "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{addressType: AddressTypeP2PKH, want: "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"},
+		{addressType: AddressTypeP2SHP2WPKH, want: "a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487"},
+		{addressType: AddressTypeP2WPKH, want: "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{addressType: AddressTypeP2TR, want: "5120da4710964f7852695de2da025290e24af6d8c281de5a0b902b7135fd9fd74d21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addressType, func(t *testing.T) {
+			input, err := BuildSigningInput(CoinTypeBitcoin, []byte(description), publicKey, "", tt.addressType)
+			if err != nil {
+				t.Fatalf("BuildSigningInput() error = %v", err)
+			}
+			fields, err := parseFields(input)
+			if err != nil {
+				t.Fatalf("parseFields() error = %v", err)
+			}
+			// UnspentTransaction { out_point = 1; script = 2; amount = 3 }
+			unspent, err := parseFields(fields.bytes(8))
+			if err != nil {
+				t.Fatalf("parseFields(utxo) error = %v", err)
+			}
+			if got := hex.EncodeToString(unspent.bytes(2)); got != tt.want {
+				t.Errorf("utxo script = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Without a default script, the UTXO script must be given
+	if _, err := BuildSigningInput(CoinTypeBitcoin, []byte(description), publicKey, "", AddressTypeP2WSH); !errors.Is(err, ErrInvalidTransaction) {
+		t.Errorf("BuildSigningInput(p2wsh) error = %v, want %v", err, ErrInvalidTransaction)
+	}
+	if _, err := BuildSigningInput(CoinTypeBitcoin, []byte(description), nil, "", ""); !errors.Is(err, ErrInvalidTransaction) {
+		t.Errorf("BuildSigningInput(no public key) error = %v, want %v", err, ErrInvalidTransaction)
+	}
+}
+
+func TestBuildBitcoinInputValidation(t *testing.T) {
+	const utxos = `"utxos":[{"txid":"0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20","vout":0,"amount":1000,"script":"0014751e76e8199196d454941c45d1b3a323f1433bd6"}]`
+
+	tests := []struct {
+		name        string
+		description string
+	}{
+		{name: "no outputs", description: `{` + utxos + `,"outputs":[],"fee_rate":1}`},
+		{name: "output without address", description: `{` + utxos + `,"outputs":[{"address":"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2","amount":1},{"amount":1}],"fee_rate":1}`},
+		{name: "zero output amount", description: `{` + utxos + `,"outputs":[{"address":"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2","amount":0}],"fee_rate":1}`},
+		{name: "no fee rate", description: `{` + utxos + `,"outputs":[{"address":"1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2","amount":1}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildSigningInput(CoinTypeBitcoin, []byte(tt.description), nil, "", ""); !errors.Is(err, ErrInvalidTransaction) {
+				t.Errorf("BuildSigningInput() error = %v, want %v", err, ErrInvalidTransaction)
+			}
+		})
+	}
+}
+
+func TestBuildEVMSigningInput(t *testing.T) {
+	const legacy = `{"to":"0x3535353535353535353535353535353535353535","value":"1","nonce":"9","gas":"21000","gas_price":"20000000000"}`
+	const enveloped = `{"to":"0x3535353535353535353535353535353535353535","value":"1","nonce":"9","gas":"21000","max_fee_per_gas":"2","max_priority_fee_per_gas":"1"}`
+
+	bsc := EVMChain{ChainID: 56}
+	polygon := EVMChain{ChainID: 137, EIP1559: true}
+
+	// The profile's chain ID is used when the description has none
+	got, err := BuildEVMSigningInput([]byte(legacy), bsc)
+	if err != nil {
+		t.Fatalf("BuildEVMSigningInput() error = %v", err)
+	}
+	want, err := BuildSigningInput(CoinTypeEthereum, []byte(legacy[:len(legacy)-1]+`,"chain_id":56}`), nil, "", "")
+	if err != nil {
+		t.Fatalf("BuildSigningInput() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("BuildEVMSigningInput() = %x, want %x", got, want)
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		chain       EVMChain
+		wantErr     bool
+	}{
+		{name: "matching chain id", description: legacy[:len(legacy)-1] + `,"chain_id":"0x38"}`, chain: bsc},
+		{name: "mismatched chain id", description: legacy[:len(legacy)-1] + `,"chain_id":1}`, chain: bsc, wantErr: true},
+		{name: "eip1559 chain", description: enveloped, chain: polygon},
+		{name: "eip1559 on legacy chain", description: enveloped, chain: bsc, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildEVMSigningInput([]byte(tt.description), tt.chain)
+			if tt.wantErr != errors.Is(err, ErrInvalidTransaction) || (!tt.wantErr && err != nil) {
+				t.Errorf("BuildEVMSigningInput() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
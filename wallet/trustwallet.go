@@ -1,3 +1,5 @@
+//go:build trustwalletcore
+
 package wallet
 
 // NOTE: This file uses CGO to interface with Trust Wallet Core C library.
@@ -5,6 +7,9 @@ package wallet
 // are not installed on your local machine. These errors will NOT affect the
 // Docker build, which includes all necessary libraries and headers.
 //
+// It is only compiled with the trustwalletcore build tag; without it the
+// pure-Go engine in goengine.go is used.
+//
 // To build successfully, use Docker:
 //   docker-compose build build
 //
@@ -12,6 +17,7 @@ package wallet
 //   1. Build Trust Wallet Core from source
 //   2. Install headers to /usr/local/include
 //   3. Install libraries to /usr/local/lib
+//   4. Build with -tags trustwalletcore
 
 // #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
 // #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
@@ -23,41 +29,20 @@ package wallet
 // #include <TrustWalletCore/TWAnyAddress.h>
 // #include <TrustWalletCore/TWString.h>
 // #include <TrustWalletCore/TWData.h>
-// #include <TrustWalletCore/TWMnemonic.h>
 // #include <stdlib.h>
 import "C"
 
 import (
-	"encoding/hex"
-	"errors"
 	"fmt"
 	"unsafe"
 )
 
-// Coin type constants for supported blockchains
-const (
-	CoinTypeBitcoin  uint32 = 0   // TWCoinTypeBitcoin
-	CoinTypeEthereum uint32 = 60  // TWCoinTypeEthereum
-	CoinTypeSolana   uint32 = 501 // TWCoinTypeSolana
-)
-
-var (
-	ErrInvalidMnemonic     = errors.New("invalid mnemonic phrase")
-	ErrInvalidCoinType     = errors.New("invalid coin type")
-	ErrKeyGenerationFailed = errors.New("key generation failed")
-	ErrSigningFailed       = errors.New("transaction signing failed")
-	ErrAddressDerivation   = errors.New("address derivation failed")
-)
-
-// WalletKeys contains the key material for a wallet
-type WalletKeys struct {
-	Mnemonic   string
-	PrivateKey []byte
-	PublicKey  []byte
-	Address    string
+func init() {
+	RegisterEngine(EngineTrustWalletCore, func() Engine { return NewTrustWalletCore() })
+	defaultEngine = EngineTrustWalletCore
 }
 
-// TrustWalletCore wraps Trust Wallet Core functionality
+// TrustWalletCore is the Engine backed by the Trust Wallet Core C library
 type TrustWalletCore struct{}
 
 // NewTrustWalletCore creates a new Trust Wallet Core wrapper instance
@@ -66,17 +51,23 @@ func NewTrustWalletCore() *TrustWalletCore {
 }
 
 // GenerateWallet generates a new HD wallet for the specified coin type
-// It creates a new mnemonic phrase and derives keys for the given blockchain
-func (twc *TrustWalletCore) GenerateWallet(coinType uint32) (*WalletKeys, error) {
+// It creates a new mnemonic phrase of wordCount words and derives keys for the given blockchain
+// The optional passphrase is the BIP39 "25th word" mixed into the seed
+func (twc *TrustWalletCore) GenerateWallet(coinType uint32, wordCount int, passphrase string) (*WalletKeys, error) {
 	if !twc.isValidCoinType(coinType) {
 		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
 	}
 
-	// Generate a new HD wallet with 128 bits (12 words)
-	emptyPassphrase := C.TWStringCreateWithUTF8Bytes(C.CString(""))
-	defer C.TWStringDelete(emptyPassphrase)
+	strength, err := MnemonicStrength(wordCount)
+	if err != nil {
+		return nil, err
+	}
 
-	wallet := C.TWHDWalletCreate(128, emptyPassphrase)
+	// Generate a new HD wallet with the requested entropy
+	passphraseTW := newTWString(passphrase)
+	defer C.TWStringDelete(passphraseTW)
+
+	wallet := C.TWHDWalletCreate(C.int(strength), passphraseTW)
 	if wallet == nil {
 		return nil, fmt.Errorf("%w: failed to create HD wallet", ErrKeyGenerationFailed)
 	}
@@ -91,7 +82,7 @@ func (twc *TrustWalletCore) GenerateWallet(coinType uint32) (*WalletKeys, error)
 	mnemonic := C.GoString(C.TWStringUTF8Bytes(mnemonicTW))
 
 	// Derive key for the specified coin type
-	privateKey := C.TWHDWalletGetKeyForCoin(wallet, coinType)
+	privateKey := C.TWHDWalletGetKeyForCoin(wallet, TWCoinType(coinType))
 	if privateKey == nil {
 		return nil, fmt.Errorf("%w: failed to derive key for coin type %d", ErrKeyGenerationFailed, coinType)
 	}
@@ -106,8 +97,8 @@ func (twc *TrustWalletCore) GenerateWallet(coinType uint32) (*WalletKeys, error)
 
 	privateKeyBytes := C.GoBytes(unsafe.Pointer(C.TWDataBytes(privateKeyData)), C.int(C.TWDataSize(privateKeyData)))
 
-	// Get public key
-	publicKey := C.TWPrivateKeyGetPublicKeySecp256k1(privateKey, true)
+	// Get public key using the coin's curve (e.g. Ed25519 for Solana)
+	publicKey := C.TWPrivateKeyGetPublicKey(privateKey, TWCoinType(coinType))
 	if publicKey == nil {
 		return nil, fmt.Errorf("%w: failed to derive public key", ErrKeyGenerationFailed)
 	}
@@ -133,12 +124,13 @@ func (twc *TrustWalletCore) GenerateWallet(coinType uint32) (*WalletKeys, error)
 		PrivateKey: privateKeyBytes,
 		PublicKey:  publicKeyBytes,
 		Address:    address,
+		Curve:      curveName(curveForCoin(coinType)),
 	}, nil
 }
 
-// ImportWallet imports an existing wallet from a mnemonic phrase
+// ImportWallet imports an existing wallet from a mnemonic phrase and optional BIP39 passphrase
 // It validates the mnemonic and derives keys for the specified coin type
-func (twc *TrustWalletCore) ImportWallet(mnemonic string, coinType uint32) (*WalletKeys, error) {
+func (twc *TrustWalletCore) ImportWallet(mnemonic string, passphrase string, coinType uint32) (*WalletKeys, error) {
 	if mnemonic == "" {
 		return nil, fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
 	}
@@ -147,26 +139,26 @@ func (twc *TrustWalletCore) ImportWallet(mnemonic string, coinType uint32) (*Wal
 		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
 	}
 
-	// Validate mnemonic
-	mnemonicTW := C.TWStringCreateWithUTF8Bytes(C.CString(mnemonic))
-	defer C.TWStringDelete(mnemonicTW)
-
-	if !C.TWMnemonicIsValid(mnemonicTW) {
-		return nil, fmt.Errorf("%w: mnemonic validation failed", ErrInvalidMnemonic)
+	// Validate mnemonic word by word so the caller learns what is wrong with it
+	if err := validateMnemonic(mnemonic); err != nil {
+		return nil, err
 	}
 
+	mnemonicTW := newTWString(mnemonic)
+	defer C.TWStringDelete(mnemonicTW)
+
 	// Import wallet from mnemonic
-	emptyPassphrase := C.TWStringCreateWithUTF8Bytes(C.CString(""))
-	defer C.TWStringDelete(emptyPassphrase)
+	passphraseTW := newTWString(passphrase)
+	defer C.TWStringDelete(passphraseTW)
 
-	wallet := C.TWHDWalletCreateWithMnemonic(mnemonicTW, emptyPassphrase)
+	wallet := C.TWHDWalletCreateWithMnemonic(mnemonicTW, passphraseTW)
 	if wallet == nil {
 		return nil, fmt.Errorf("%w: failed to import wallet", ErrInvalidMnemonic)
 	}
 	defer C.TWHDWalletDelete(wallet)
 
 	// Derive key for the specified coin type
-	privateKey := C.TWHDWalletGetKeyForCoin(wallet, coinType)
+	privateKey := C.TWHDWalletGetKeyForCoin(wallet, TWCoinType(coinType))
 	if privateKey == nil {
 		return nil, fmt.Errorf("%w: failed to derive key for coin type %d", ErrKeyGenerationFailed, coinType)
 	}
@@ -181,8 +173,8 @@ func (twc *TrustWalletCore) ImportWallet(mnemonic string, coinType uint32) (*Wal
 
 	privateKeyBytes := C.GoBytes(unsafe.Pointer(C.TWDataBytes(privateKeyData)), C.int(C.TWDataSize(privateKeyData)))
 
-	// Get public key
-	publicKey := C.TWPrivateKeyGetPublicKeySecp256k1(privateKey, true)
+	// Get public key using the coin's curve (e.g. Ed25519 for Solana)
+	publicKey := C.TWPrivateKeyGetPublicKey(privateKey, TWCoinType(coinType))
 	if publicKey == nil {
 		return nil, fmt.Errorf("%w: failed to derive public key", ErrKeyGenerationFailed)
 	}
@@ -208,71 +200,112 @@ func (twc *TrustWalletCore) ImportWallet(mnemonic string, coinType uint32) (*Wal
 		PrivateKey: privateKeyBytes,
 		PublicKey:  publicKeyBytes,
 		Address:    address,
+		Curve:      curveName(curveForCoin(coinType)),
 	}, nil
 }
 
-// DeriveAddress derives an address for a specific coin type and derivation path
-// If derivationPath is empty, it uses the default path for the coin type
-func (twc *TrustWalletCore) DeriveAddress(mnemonic string, coinType uint32, derivationPath string) (string, error) {
+// DeriveKey derives the key pair and address for a coin type from a mnemonic and BIP39 passphrase
+// If derivationPath is empty, the key is derived at the coin's default path with
+// TWHDWalletGetKeyForCoin; otherwise TWHDWalletGetKey derives it at the given path
+func (twc *TrustWalletCore) DeriveKey(mnemonic string, passphrase string, coinType uint32, derivationPath string) (*WalletKeys, error) {
 	if mnemonic == "" {
-		return "", fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
+		return nil, fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
 	}
 
 	if !twc.isValidCoinType(coinType) {
-		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
 	}
 
-	// Validate mnemonic
-	mnemonicTW := C.TWStringCreateWithUTF8Bytes(C.CString(mnemonic))
-	defer C.TWStringDelete(mnemonicTW)
-
-	if !C.TWMnemonicIsValid(mnemonicTW) {
-		return "", fmt.Errorf("%w: mnemonic validation failed", ErrInvalidMnemonic)
+	// Validate mnemonic word by word so the caller learns what is wrong with it
+	if err := validateMnemonic(mnemonic); err != nil {
+		return nil, err
 	}
 
+	mnemonicTW := newTWString(mnemonic)
+	defer C.TWStringDelete(mnemonicTW)
+
 	// Import wallet from mnemonic
-	emptyPassphrase := C.TWStringCreateWithUTF8Bytes(C.CString(""))
-	defer C.TWStringDelete(emptyPassphrase)
+	passphraseTW := newTWString(passphrase)
+	defer C.TWStringDelete(passphraseTW)
 
-	wallet := C.TWHDWalletCreateWithMnemonic(mnemonicTW, emptyPassphrase)
+	wallet := C.TWHDWalletCreateWithMnemonic(mnemonicTW, passphraseTW)
 	if wallet == nil {
-		return "", fmt.Errorf("%w: failed to import wallet", ErrInvalidMnemonic)
+		return nil, fmt.Errorf("%w: failed to import wallet", ErrInvalidMnemonic)
 	}
 	defer C.TWHDWalletDelete(wallet)
 
 	var privateKey *C.struct_TWPrivateKey
 	if derivationPath != "" {
 		// Use custom derivation path
-		pathTW := C.TWStringCreateWithUTF8Bytes(C.CString(derivationPath))
+		pathTW := newTWString(derivationPath)
 		defer C.TWStringDelete(pathTW)
 
-		privateKey = C.TWHDWalletGetKey(wallet, coinType, pathTW)
+		privateKey = C.TWHDWalletGetKey(wallet, TWCoinType(coinType), pathTW)
 		if privateKey == nil {
-			return "", fmt.Errorf("%w: failed to derive key for path %s", ErrAddressDerivation, derivationPath)
+			return nil, fmt.Errorf("%w: failed to derive key for path %s", ErrAddressDerivation, derivationPath)
 		}
 	} else {
 		// Use default derivation path
-		privateKey = C.TWHDWalletGetKeyForCoin(wallet, coinType)
+		privateKey = C.TWHDWalletGetKeyForCoin(wallet, TWCoinType(coinType))
 		if privateKey == nil {
-			return "", fmt.Errorf("%w: failed to derive key for coin type %d", ErrAddressDerivation, coinType)
+			return nil, fmt.Errorf("%w: failed to derive key for coin type %d", ErrAddressDerivation, coinType)
 		}
 	}
 	defer C.TWPrivateKeyDelete(privateKey)
 
-	// Get public key
-	publicKey := C.TWPrivateKeyGetPublicKeySecp256k1(privateKey, true)
+	// Get private key data
+	privateKeyData := C.TWPrivateKeyData(privateKey)
+	if privateKeyData == nil {
+		return nil, fmt.Errorf("%w: failed to get private key data", ErrAddressDerivation)
+	}
+	defer C.TWDataDelete(privateKeyData)
+
+	privateKeyBytes := C.GoBytes(unsafe.Pointer(C.TWDataBytes(privateKeyData)), C.int(C.TWDataSize(privateKeyData)))
+
+	// Get public key using the coin's curve
+	publicKey := C.TWPrivateKeyGetPublicKey(privateKey, TWCoinType(coinType))
 	if publicKey == nil {
-		return "", fmt.Errorf("%w: failed to derive public key", ErrAddressDerivation)
+		return nil, fmt.Errorf("%w: failed to derive public key", ErrAddressDerivation)
 	}
 	defer C.TWPublicKeyDelete(publicKey)
 
+	// Get public key data
+	publicKeyData := C.TWPublicKeyData(publicKey)
+	if publicKeyData == nil {
+		return nil, fmt.Errorf("%w: failed to get public key data", ErrAddressDerivation)
+	}
+	defer C.TWDataDelete(publicKeyData)
+
+	publicKeyBytes := C.GoBytes(unsafe.Pointer(C.TWDataBytes(publicKeyData)), C.int(C.TWDataSize(publicKeyData)))
+
 	// Derive address for the coin type
 	address, err := twc.getAddressForCoinType(publicKey, coinType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletKeys{
+		PrivateKey: privateKeyBytes,
+		PublicKey:  publicKeyBytes,
+		Address:    address,
+		Curve:      curveName(curveForCoin(coinType)),
+	}, nil
+}
+
+// DeriveAddress derives an address for a specific coin type and derivation path
+// If derivationPath is empty, it uses the default path for the coin type
+func (twc *TrustWalletCore) DeriveAddress(mnemonic string, passphrase string, coinType uint32, derivationPath string) (string, error) {
+	keys, err := twc.DeriveKey(mnemonic, passphrase, coinType, derivationPath)
 	if err != nil {
 		return "", err
 	}
 
-	return address, nil
+	// Only the address is needed
+	for i := range keys.PrivateKey {
+		keys.PrivateKey[i] = 0
+	}
+
+	return keys.Address, nil
 }
 
 // SignTransaction signs a transaction using the private key for the specified coin type
@@ -310,8 +343,8 @@ func (twc *TrustWalletCore) SignTransaction(privateKey []byte, coinType uint32,
 	}
 	defer C.TWDataDelete(txDataTW)
 
-	// Sign the transaction data using SECP256k1 curve
-	signature := C.TWPrivateKeySign(privKey, txDataTW, C.TWCurveSECP256k1)
+	// Sign the transaction data using the coin's curve
+	signature := C.TWPrivateKeySign(privKey, txDataTW, curveForCoin(coinType))
 	if signature == nil {
 		return nil, fmt.Errorf("%w: signature generation failed", ErrSigningFailed)
 	}
@@ -325,23 +358,78 @@ func (twc *TrustWalletCore) SignTransaction(privateKey []byte, coinType uint32,
 	return signatureBytes, nil
 }
 
-// isValidCoinType checks if the coin type is supported
+// PublicKey returns the public key for a raw private key using the coin's curve
+func (twc *TrustWalletCore) PublicKey(privateKey []byte, coinType uint32) ([]byte, error) {
+	if len(privateKey) == 0 {
+		return nil, fmt.Errorf("%w: empty private key", ErrKeyGenerationFailed)
+	}
+
+	if !twc.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	privateKeyData := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&privateKey[0])), C.size_t(len(privateKey)))
+	if privateKeyData == nil {
+		return nil, fmt.Errorf("%w: failed to create private key data", ErrKeyGenerationFailed)
+	}
+	defer C.TWDataDelete(privateKeyData)
+
+	privKey := C.TWPrivateKeyCreateWithData(privateKeyData)
+	if privKey == nil {
+		return nil, fmt.Errorf("%w: failed to create private key", ErrKeyGenerationFailed)
+	}
+	defer C.TWPrivateKeyDelete(privKey)
+
+	publicKey := C.TWPrivateKeyGetPublicKey(privKey, TWCoinType(coinType))
+	if publicKey == nil {
+		return nil, fmt.Errorf("%w: failed to derive public key", ErrKeyGenerationFailed)
+	}
+	defer C.TWPublicKeyDelete(publicKey)
+
+	publicKeyData := C.TWPublicKeyData(publicKey)
+	if publicKeyData == nil {
+		return nil, fmt.Errorf("%w: failed to get public key data", ErrKeyGenerationFailed)
+	}
+	defer C.TWDataDelete(publicKeyData)
+
+	return C.GoBytes(unsafe.Pointer(C.TWDataBytes(publicKeyData)), C.int(C.TWDataSize(publicKeyData))), nil
+}
+
+// curveForCoin returns the Trust Wallet Core curve for a coin type
+func curveForCoin(coinType uint32) TWCurve {
+	return C.TWCoinTypeCurve(TWCoinType(coinType))
+}
+
+// curveName maps a Trust Wallet Core curve to its reported name
+func curveName(curve TWCurve) string {
+	switch curve {
+	case C.TWCurveSECP256k1:
+		return CurveSecp256k1
+	case C.TWCurveED25519:
+		return CurveEd25519
+	case C.TWCurveED25519Blake2bNano:
+		return CurveEd25519Blake2bNano
+	case C.TWCurveCurve25519:
+		return CurveCurve25519
+	case C.TWCurveNIST256p1:
+		return CurveNist256p1
+	case C.TWCurveED25519ExtendedCardano:
+		return CurveEd25519ExtendedCardano
+	default:
+		return CurveUnknown
+	}
+}
+
+// isValidCoinType checks if the coin type is in the coin registry
 func (twc *TrustWalletCore) isValidCoinType(coinType uint32) bool {
-	// For now, we explicitly support Bitcoin, Ethereum, and Solana
-	// In a production implementation, this could be expanded to check against
-	// all coin types supported by Trust Wallet Core
-	supportedTypes := map[uint32]bool{
-		uint32(CoinTypeBitcoin):  true,
-		uint32(CoinTypeEthereum): true,
-		uint32(CoinTypeSolana):   true,
-	}
-	return supportedTypes[coinType]
+	_, ok := LookupCoin(coinType)
+	return ok
 }
 
 // getAddressForCoinType derives the address from a public key for a specific coin type
 func (twc *TrustWalletCore) getAddressForCoinType(publicKey *C.struct_TWPublicKey, coinType uint32) (string, error) {
 	// Use TWAnyAddress for all coin types
-	anyAddress := C.TWAnyAddressCreateWithPublicKey(publicKey, coinType)
+	anyAddress := C.TWAnyAddressCreateWithPublicKey(publicKey, TWCoinType(coinType))
 	if anyAddress == nil {
 		return "", fmt.Errorf("%w: failed to create address for coin type %d", ErrAddressDerivation, coinType)
 	}
@@ -361,12 +449,10 @@ func (twc *TrustWalletCore) getAddressForCoinType(publicKey *C.struct_TWPublicKe
 	return address, nil
 }
 
-// GetPrivateKeyHex returns the private key as a hexadecimal string
-func GetPrivateKeyHex(privateKey []byte) string {
-	return hex.EncodeToString(privateKey)
-}
-
-// GetPublicKeyHex returns the public key as a hexadecimal string
-func GetPublicKeyHex(publicKey []byte) string {
-	return hex.EncodeToString(publicKey)
+// newTWString creates a TWString from a Go string without leaking the C copy
+// The caller must release the result with TWStringDelete
+func newTWString(s string) unsafe.Pointer {
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	return C.TWStringCreateWithUTF8Bytes(cs)
 }
@@ -0,0 +1,132 @@
+//go:build trustwalletcore
+
+package wallet
+
+// #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
+// #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
+// #include <TrustWalletCore/TWAnyAddress.h>
+// #include <TrustWalletCore/TWBitcoinAddress.h>
+// #include <TrustWalletCore/TWCoinType.h>
+// #include <TrustWalletCore/TWData.h>
+// #include <TrustWalletCore/TWDerivation.h>
+// #include <TrustWalletCore/TWHRP.h>
+// #include <TrustWalletCore/TWHash.h>
+// #include <TrustWalletCore/TWPublicKey.h>
+// #include <TrustWalletCore/TWSegwitAddress.h>
+// #include <TrustWalletCore/TWString.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// AddressForType returns the address of a public key in the given address type
+// An empty address type yields the coin's default address format
+func (twc *TrustWalletCore) AddressForType(publicKey []byte, coinType uint32, addressType string) (string, error) {
+	if len(publicKey) == 0 {
+		return "", fmt.Errorf("%w: empty public key", ErrAddressDerivation)
+	}
+
+	if !twc.isValidCoinType(coinType) {
+		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if addressType != "" {
+		if _, err := AddressTypePurpose(coinType, addressType); err != nil {
+			return "", err
+		}
+	}
+
+	publicKeyData := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&publicKey[0])), C.size_t(len(publicKey)))
+	defer C.TWDataDelete(publicKeyData)
+
+	pubKeyType, err := publicKeyType(publicKey, coinType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrAddressDerivation, err)
+	}
+
+	pubKey := C.TWPublicKeyCreateWithData(publicKeyData, pubKeyType)
+	if pubKey == nil {
+		return "", fmt.Errorf("%w: invalid public key", ErrAddressDerivation)
+	}
+	defer C.TWPublicKeyDelete(pubKey)
+
+	return twc.addressForType(pubKey, coinType, addressType)
+}
+
+// addressForType derives the address of a public key in the given address type
+func (twc *TrustWalletCore) addressForType(publicKey *C.struct_TWPublicKey, coinType uint32, addressType string) (string, error) {
+	switch addressType {
+	case "":
+		return twc.getAddressForCoinType(publicKey, coinType)
+
+	case AddressTypeP2PKH:
+		address := C.TWBitcoinAddressCreateWithPublicKey(publicKey, C.TWCoinTypeP2pkhPrefix(TWCoinType(coinType)))
+		if address == nil {
+			return "", fmt.Errorf("%w: failed to create P2PKH address", ErrAddressDerivation)
+		}
+		defer C.TWBitcoinAddressDelete(address)
+		return twStringToAddress(C.TWBitcoinAddressDescription(address))
+
+	case AddressTypeP2SHP2WPKH:
+		// P2SH wrapping the witness program 0 <hash160(pubkey)> (BIP49)
+		publicKeyData := C.TWPublicKeyData(publicKey)
+		defer C.TWDataDelete(publicKeyData)
+
+		keyHash := C.TWHashSHA256RIPEMD(publicKeyData)
+		defer C.TWDataDelete(keyHash)
+
+		redeemScript := append([]byte{0x00, 0x14}, C.GoBytes(unsafe.Pointer(C.TWDataBytes(keyHash)), C.int(C.TWDataSize(keyHash)))...)
+		redeemScriptTW := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&redeemScript[0])), C.size_t(len(redeemScript)))
+		defer C.TWDataDelete(redeemScriptTW)
+
+		scriptHash := C.TWHashSHA256RIPEMD(redeemScriptTW)
+		defer C.TWDataDelete(scriptHash)
+
+		payload := append([]byte{byte(C.TWCoinTypeP2shPrefix(TWCoinType(coinType)))}, C.GoBytes(unsafe.Pointer(C.TWDataBytes(scriptHash)), C.int(C.TWDataSize(scriptHash)))...)
+		payloadTW := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&payload[0])), C.size_t(len(payload)))
+		defer C.TWDataDelete(payloadTW)
+
+		address := C.TWBitcoinAddressCreateWithData(payloadTW)
+		if address == nil {
+			return "", fmt.Errorf("%w: failed to create P2SH-P2WPKH address", ErrAddressDerivation)
+		}
+		defer C.TWBitcoinAddressDelete(address)
+		return twStringToAddress(C.TWBitcoinAddressDescription(address))
+
+	case AddressTypeP2WPKH:
+		address := C.TWSegwitAddressCreateWithPublicKey(C.TWHRPBitcoin, publicKey)
+		if address == nil {
+			return "", fmt.Errorf("%w: failed to create P2WPKH address", ErrAddressDerivation)
+		}
+		defer C.TWSegwitAddressDelete(address)
+		return twStringToAddress(C.TWSegwitAddressDescription(address))
+
+	case AddressTypeP2TR:
+		address := C.TWAnyAddressCreateWithPublicKeyDerivation(publicKey, TWCoinType(coinType), C.TWDerivationBitcoinTaproot)
+		if address == nil {
+			return "", fmt.Errorf("%w: failed to create P2TR address", ErrAddressDerivation)
+		}
+		defer C.TWAnyAddressDelete(address)
+		return twStringToAddress(C.TWAnyAddressDescription(address))
+
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidAddressType, addressType)
+	}
+}
+
+// twStringToAddress converts and releases an address description
+func twStringToAddress(addressTW unsafe.Pointer) (string, error) {
+	if addressTW == nil {
+		return "", fmt.Errorf("%w: failed to get address description", ErrAddressDerivation)
+	}
+	defer C.TWStringDelete(addressTW)
+
+	address := C.GoString(C.TWStringUTF8Bytes(addressTW))
+	if address == "" {
+		return "", fmt.Errorf("%w: empty address generated", ErrAddressDerivation)
+	}
+
+	return address, nil
+}
@@ -0,0 +1,63 @@
+//go:build trustwalletcore
+
+package wallet
+
+// #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
+// #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
+// #include <TrustWalletCore/TWAnySigner.h>
+// #include <TrustWalletCore/TWData.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// SignInput signs a chain-specific SigningInput protobuf with TWAnySignerSign
+// The wallet's private key is injected into the input inside the plugin, replacing
+// any private key the caller may have set
+func (twc *TrustWalletCore) SignInput(privateKey []byte, coinType uint32, input []byte) (*SignedTransaction, error) {
+	if len(privateKey) == 0 {
+		return nil, fmt.Errorf("%w: empty private key", ErrSigningFailed)
+	}
+
+	if len(input) == 0 {
+		return nil, fmt.Errorf("%w: empty signing input", ErrInvalidSigningInput)
+	}
+
+	if !twc.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	schema, ok := anySignerSchemas[coinType]
+	if !ok {
+		return nil, fmt.Errorf("%w: signing input not supported for coin type %d", ErrInvalidCoinType, coinType)
+	}
+
+	signingInput, err := injectPrivateKey(input, schema.inputPrivateKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Clear the copy of the private key embedded in the input
+		for i := range signingInput {
+			signingInput[i] = 0
+		}
+	}()
+
+	inputData := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&signingInput[0])), C.size_t(len(signingInput)))
+	if inputData == nil {
+		return nil, fmt.Errorf("%w: failed to create signing input data", ErrSigningFailed)
+	}
+	defer C.TWDataDelete(inputData)
+
+	outputData := C.TWAnySignerSign(inputData, TWCoinType(coinType))
+	if outputData == nil {
+		return nil, fmt.Errorf("%w: any signer returned no output", ErrSigningFailed)
+	}
+	defer C.TWDataDelete(outputData)
+
+	output := C.GoBytes(unsafe.Pointer(C.TWDataBytes(outputData)), C.int(C.TWDataSize(outputData)))
+
+	return parseSigningOutput(coinType, output)
+}
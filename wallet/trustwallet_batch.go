@@ -0,0 +1,98 @@
+//go:build trustwalletcore
+
+package wallet
+
+// #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
+// #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
+// #include <TrustWalletCore/TWData.h>
+// #include <TrustWalletCore/TWHDWallet.h>
+// #include <TrustWalletCore/TWPrivateKey.h>
+// #include <TrustWalletCore/TWPublicKey.h>
+// #include <TrustWalletCore/TWString.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// DeriveAddresses derives the public keys and addresses at each path from one seed
+// The seed is expanded once for the whole batch and no private key leaves this function
+// An empty address type yields the coin's default address format
+func (twc *TrustWalletCore) DeriveAddresses(mnemonic string, passphrase string, coinType uint32, addressType string, paths []string) ([]DerivedAddress, error) {
+	if mnemonic == "" {
+		return nil, fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
+	}
+
+	if !twc.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if addressType != "" {
+		if _, err := AddressTypePurpose(coinType, addressType); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	mnemonicTW := newTWString(mnemonic)
+	defer C.TWStringDelete(mnemonicTW)
+
+	passphraseTW := newTWString(passphrase)
+	defer C.TWStringDelete(passphraseTW)
+
+	wallet := C.TWHDWalletCreateWithMnemonic(mnemonicTW, passphraseTW)
+	if wallet == nil {
+		return nil, fmt.Errorf("%w: failed to import wallet", ErrInvalidMnemonic)
+	}
+	defer C.TWHDWalletDelete(wallet)
+
+	addresses := make([]DerivedAddress, 0, len(paths))
+	for _, path := range paths {
+		derived, err := twc.deriveAddressAt(wallet, coinType, addressType, path)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *derived)
+	}
+
+	return addresses, nil
+}
+
+// deriveAddressAt derives the public key and address at a path of an HD wallet
+func (twc *TrustWalletCore) deriveAddressAt(wallet *C.struct_TWHDWallet, coinType uint32, addressType string, path string) (*DerivedAddress, error) {
+	pathTW := newTWString(path)
+	defer C.TWStringDelete(pathTW)
+
+	privateKey := C.TWHDWalletGetKey(wallet, TWCoinType(coinType), pathTW)
+	if privateKey == nil {
+		return nil, fmt.Errorf("%w: failed to derive key for path %s", ErrAddressDerivation, path)
+	}
+	defer C.TWPrivateKeyDelete(privateKey)
+
+	publicKey := C.TWPrivateKeyGetPublicKey(privateKey, TWCoinType(coinType))
+	if publicKey == nil {
+		return nil, fmt.Errorf("%w: failed to derive public key", ErrAddressDerivation)
+	}
+	defer C.TWPublicKeyDelete(publicKey)
+
+	publicKeyData := C.TWPublicKeyData(publicKey)
+	if publicKeyData == nil {
+		return nil, fmt.Errorf("%w: failed to get public key data", ErrAddressDerivation)
+	}
+	defer C.TWDataDelete(publicKeyData)
+
+	address, err := twc.addressForType(publicKey, coinType, addressType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedAddress{
+		Path:      path,
+		PublicKey: C.GoBytes(unsafe.Pointer(C.TWDataBytes(publicKeyData)), C.int(C.TWDataSize(publicKeyData))),
+		Address:   address,
+	}, nil
+}
@@ -0,0 +1,104 @@
+//go:build trustwalletcore
+
+package wallet
+
+// #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
+// #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
+// #include <TrustWalletCore/TWAnyAddress.h>
+// #include <TrustWalletCore/TWCoinType.h>
+// #include <TrustWalletCore/TWCoinTypeConfiguration.h>
+// #include <TrustWalletCore/TWString.h>
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+var (
+	coinRegistryOnce sync.Once
+	coinRegistry     map[uint32]CoinInfo
+	coinList         []CoinInfo
+)
+
+// loadCoinRegistry builds the coin registry from Trust Wallet Core metadata on first use
+func loadCoinRegistry() {
+	coinRegistryOnce.Do(func() {
+		coinRegistry = make(map[uint32]CoinInfo, len(knownCoinTypes))
+		for _, coinType := range knownCoinTypes {
+			info, ok := coinInfo(coinType)
+			if !ok {
+				continue
+			}
+			coinRegistry[coinType] = info
+			coinList = append(coinList, info)
+		}
+	})
+}
+
+// Coins returns the chains supported by Trust Wallet Core, ordered by coin type
+func Coins() []CoinInfo {
+	loadCoinRegistry()
+	coins := make([]CoinInfo, len(coinList))
+	copy(coins, coinList)
+	return coins
+}
+
+// LookupCoin returns the registry entry of a coin type
+func LookupCoin(coinType uint32) (CoinInfo, bool) {
+	loadCoinRegistry()
+	info, ok := coinRegistry[coinType]
+	return info, ok
+}
+
+// IsValidAddress reports whether an address is valid on the coin's chain
+func (c CoinInfo) IsValidAddress(address string) bool {
+	addressTW := newTWString(address)
+	defer C.TWStringDelete(addressTW)
+	return bool(C.TWAnyAddressIsValid(addressTW, TWCoinType(c.CoinType)))
+}
+
+// NormalizeAddress returns the canonical form of an address on the coin's chain,
+// e.g. EIP-55 checksummed for EVM chains; ok is false if the address is invalid
+func (c CoinInfo) NormalizeAddress(address string) (normalized string, ok bool) {
+	addressTW := newTWString(address)
+	defer C.TWStringDelete(addressTW)
+
+	anyAddress := C.TWAnyAddressCreateWithString(addressTW, TWCoinType(c.CoinType))
+	if anyAddress == nil {
+		return "", false
+	}
+	defer C.TWAnyAddressDelete(anyAddress)
+
+	return twStringToGo(C.TWAnyAddressDescription(anyAddress)), true
+}
+
+// coinInfo reads the metadata of a coin type from Trust Wallet Core
+// Coin types the library does not know have no ID and are reported as missing
+func coinInfo(coinType uint32) (CoinInfo, bool) {
+	coin := TWCoinType(coinType)
+
+	id := twStringToGo(C.TWCoinTypeConfigurationGetID(coin))
+	if id == "" || id == "?" {
+		return CoinInfo{}, false
+	}
+
+	return CoinInfo{
+		CoinType:       coinType,
+		ID:             id,
+		Name:           twStringToGo(C.TWCoinTypeConfigurationGetName(coin)),
+		Symbol:         twStringToGo(C.TWCoinTypeConfigurationGetSymbol(coin)),
+		Decimals:       int(C.TWCoinTypeConfigurationGetDecimals(coin)),
+		Curve:          curveName(C.TWCoinTypeCurve(coin)),
+		DerivationPath: twStringToGo(C.TWCoinTypeDerivationPath(coin)),
+	}, true
+}
+
+// twStringToGo converts and releases a TWString, returning an empty string for nil
+func twStringToGo(s unsafe.Pointer) string {
+	if s == nil {
+		return ""
+	}
+	defer C.TWStringDelete(s)
+	return C.GoString(C.TWStringUTF8Bytes(s))
+}
@@ -0,0 +1,151 @@
+//go:build trustwalletcore
+
+package wallet
+
+// #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
+// #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
+// #include <TrustWalletCore/TWCurve.h>
+// #include <TrustWalletCore/TWData.h>
+// #include <TrustWalletCore/TWEthereumMessageSigner.h>
+// #include <TrustWalletCore/TWPrivateKey.h>
+// #include <TrustWalletCore/TWString.h>
+// #include <stdlib.h>
+import "C"
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// SignMessage signs a message with the domain separation of the given format
+// The signature uses the chain's conventional encoding:
+//   - eip191, eip712: 0x-prefixed hex r||s||v with v in {27, 28}
+//   - bitcoin: base64 compact signature with a BIP137 header for the address type
+//   - solana-offchain: base58 Ed25519 signature over the off-chain message envelope
+func (twc *TrustWalletCore) SignMessage(privateKey []byte, coinType uint32, format string, message string, address string) (string, error) {
+	if len(privateKey) == 0 {
+		return "", fmt.Errorf("%w: empty private key", ErrSigningFailed)
+	}
+
+	if message == "" {
+		return "", fmt.Errorf("%w: empty message", ErrInvalidMessage)
+	}
+
+	if err := checkMessageFormat(coinType, format); err != nil {
+		return "", err
+	}
+
+	privateKeyData := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&privateKey[0])), C.size_t(len(privateKey)))
+	if privateKeyData == nil {
+		return "", fmt.Errorf("%w: failed to create private key data", ErrSigningFailed)
+	}
+	defer C.TWDataDelete(privateKeyData)
+
+	privKey := C.TWPrivateKeyCreateWithData(privateKeyData)
+	if privKey == nil {
+		return "", fmt.Errorf("%w: failed to create private key", ErrSigningFailed)
+	}
+	defer C.TWPrivateKeyDelete(privKey)
+
+	switch format {
+	case MessageFormatEIP191, MessageFormatEIP712:
+		return signEthereumMessage(privKey, format, message)
+	case MessageFormatBitcoin:
+		return signBitcoinMessage(privKey, message, address)
+	default:
+		return signSolanaOffchainMessage(privKey, message)
+	}
+}
+
+// signEthereumMessage signs an EIP-191 personal message or EIP-712 typed data JSON
+func signEthereumMessage(privKey *C.struct_TWPrivateKey, format string, message string) (string, error) {
+	cs := C.CString(message)
+	defer C.free(unsafe.Pointer(cs))
+
+	messageTW := C.TWStringCreateWithUTF8Bytes(cs)
+	defer C.TWStringDelete(messageTW)
+
+	var signatureTW unsafe.Pointer
+	if format == MessageFormatEIP712 {
+		signatureTW = unsafe.Pointer(C.TWEthereumMessageSignerSignTypedMessage(privKey, messageTW))
+	} else {
+		signatureTW = unsafe.Pointer(C.TWEthereumMessageSignerSignMessage(privKey, messageTW))
+	}
+	if signatureTW == nil {
+		return "", fmt.Errorf("%w: signature generation failed", ErrSigningFailed)
+	}
+	defer C.TWStringDelete(signatureTW)
+
+	signature := C.GoString(C.TWStringUTF8Bytes(signatureTW))
+	if signature == "" {
+		// Trust Wallet Core returns an empty signature for malformed typed data
+		return "", fmt.Errorf("%w: message could not be encoded as %s", ErrInvalidMessage, format)
+	}
+
+	return "0x" + strings.TrimPrefix(signature, "0x"), nil
+}
+
+// signBitcoinMessage signs a message with the "Bitcoin Signed Message" prefix
+func signBitcoinMessage(privKey *C.struct_TWPrivateKey, message string, address string) (string, error) {
+	// BIP137 has no header for Taproot; those addresses need BIP322 signatures
+	if strings.HasPrefix(address, "bc1p") {
+		return "", fmt.Errorf("%w: bitcoin message signatures are not defined for taproot addresses", ErrUnsupportedMessageFormat)
+	}
+
+	digest := bitcoinMessageDigest(message)
+
+	signature, err := signDigest(privKey, digest, C.TWCurveSECP256k1)
+	if err != nil {
+		return "", err
+	}
+	if len(signature) != 65 {
+		return "", fmt.Errorf("%w: unexpected signature length %d", ErrSigningFailed, len(signature))
+	}
+
+	// Compact signature: header || r || s, where the header encodes the
+	// recovery id and the address type (BIP137)
+	compact := make([]byte, 65)
+	compact[0] = bitcoinSignatureHeader(address) + signature[64]
+	copy(compact[1:], signature[:64])
+
+	return base64.StdEncoding.EncodeToString(compact), nil
+}
+
+// signSolanaOffchainMessage signs a message wrapped in the Solana off-chain message envelope
+func signSolanaOffchainMessage(privKey *C.struct_TWPrivateKey, message string) (string, error) {
+	envelope, err := solanaOffchainEnvelope(message)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := signDigest(privKey, envelope, C.TWCurveED25519)
+	if err != nil {
+		return "", err
+	}
+
+	return base58Encode(signature), nil
+}
+
+// signDigest signs data with a private key on the given curve
+func signDigest(privKey *C.struct_TWPrivateKey, data []byte, curve TWCurve) ([]byte, error) {
+	dataTW := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&data[0])), C.size_t(len(data)))
+	if dataTW == nil {
+		return nil, fmt.Errorf("%w: failed to create message data", ErrSigningFailed)
+	}
+	defer C.TWDataDelete(dataTW)
+
+	signature := C.TWPrivateKeySign(privKey, dataTW, curve)
+	if signature == nil {
+		return nil, fmt.Errorf("%w: signature generation failed", ErrSigningFailed)
+	}
+	defer C.TWDataDelete(signature)
+
+	signatureBytes := C.GoBytes(unsafe.Pointer(C.TWDataBytes(signature)), C.int(C.TWDataSize(signature)))
+	if len(signatureBytes) == 0 {
+		return nil, fmt.Errorf("%w: empty signature generated", ErrSigningFailed)
+	}
+
+	return signatureBytes, nil
+}
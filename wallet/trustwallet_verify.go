@@ -0,0 +1,127 @@
+//go:build trustwalletcore
+
+package wallet
+
+// #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
+// #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
+// #include <TrustWalletCore/TWCoinType.h>
+// #include <TrustWalletCore/TWCurve.h>
+// #include <TrustWalletCore/TWData.h>
+// #include <TrustWalletCore/TWEthereumAbi.h>
+// #include <TrustWalletCore/TWPublicKey.h>
+// #include <TrustWalletCore/TWPublicKeyType.h>
+// #include <TrustWalletCore/TWString.h>
+// #include <stdlib.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// VerifyMessage checks a signature produced by SignMessage or SignTransaction against a public key
+// It returns false for a well-formed signature that does not match, and an error
+// wrapping ErrInvalidMessage when the message or signature cannot be decoded
+func (twc *TrustWalletCore) VerifyMessage(publicKey []byte, coinType uint32, format string, message string, signature string) (bool, error) {
+	if len(publicKey) == 0 {
+		return false, fmt.Errorf("%w: empty public key", ErrInvalidMessage)
+	}
+
+	if message == "" || signature == "" {
+		return false, fmt.Errorf("%w: message and signature are required", ErrInvalidMessage)
+	}
+
+	if !twc.isValidCoinType(coinType) {
+		return false, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if format != MessageFormatRaw {
+		if err := checkMessageFormat(coinType, format); err != nil {
+			return false, err
+		}
+	}
+
+	digest, sig, err := verificationInput(format, message, signature, typedDataHash)
+	if err != nil {
+		return false, err
+	}
+
+	pubKeyType, err := publicKeyType(publicKey, coinType)
+	if err != nil {
+		return false, err
+	}
+
+	// ECDSA verification operates on a 32-byte digest
+	if pubKeyType != C.TWPublicKeyTypeED25519 && len(digest) != 32 {
+		return false, fmt.Errorf("%w: signed data must be a 32-byte digest", ErrInvalidMessage)
+	}
+
+	publicKeyData := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&publicKey[0])), C.size_t(len(publicKey)))
+	defer C.TWDataDelete(publicKeyData)
+
+	pubKey := C.TWPublicKeyCreateWithData(publicKeyData, pubKeyType)
+	if pubKey == nil {
+		return false, fmt.Errorf("%w: stored public key is invalid", ErrInvalidMessage)
+	}
+	defer C.TWPublicKeyDelete(pubKey)
+
+	digestTW := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&digest[0])), C.size_t(len(digest)))
+	defer C.TWDataDelete(digestTW)
+
+	// secp256k1 signatures that are neither compact nor recoverable are DER encoded
+	if pubKeyType != C.TWPublicKeyTypeED25519 && len(sig) != 64 && len(sig) != 65 {
+		sigTW := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&sig[0])), C.size_t(len(sig)))
+		defer C.TWDataDelete(sigTW)
+		return bool(C.TWPublicKeyVerifyAsDER(pubKey, sigTW, digestTW)), nil
+	}
+
+	// Only r||s is checked; the recovery id is not part of the signature proper
+	if len(sig) == 65 {
+		sig = sig[:64]
+	}
+
+	sigTW := C.TWDataCreateWithBytes((*C.uint8_t)(unsafe.Pointer(&sig[0])), C.size_t(len(sig)))
+	defer C.TWDataDelete(sigTW)
+
+	return bool(C.TWPublicKeyVerify(pubKey, sigTW, digestTW)), nil
+}
+
+// typedDataHash returns the EIP-712 signing hash of a typed data JSON document
+func typedDataHash(message string) ([]byte, error) {
+	cs := C.CString(message)
+	defer C.free(unsafe.Pointer(cs))
+
+	messageTW := C.TWStringCreateWithUTF8Bytes(cs)
+	defer C.TWStringDelete(messageTW)
+
+	hash := C.TWEthereumAbiEncodeTyped(messageTW)
+	if hash == nil {
+		return nil, fmt.Errorf("%w: typed data could not be encoded", ErrInvalidMessage)
+	}
+	defer C.TWDataDelete(hash)
+
+	digest := C.GoBytes(unsafe.Pointer(C.TWDataBytes(hash)), C.int(C.TWDataSize(hash)))
+	if len(digest) != 32 {
+		// Trust Wallet Core returns an empty hash for malformed typed data
+		return nil, fmt.Errorf("%w: typed data could not be encoded", ErrInvalidMessage)
+	}
+
+	return digest, nil
+}
+
+// publicKeyType returns the Trust Wallet Core key type for a stored public key
+// Only secp256k1 keys come in two encodings, which are told apart by length
+func publicKeyType(publicKey []byte, coinType uint32) (C.enum_TWPublicKeyType, error) {
+	if curveForCoin(coinType) != C.TWCurveSECP256k1 {
+		return C.TWCoinTypePublicKeyType(TWCoinType(coinType)), nil
+	}
+
+	switch len(publicKey) {
+	case 33:
+		return C.TWPublicKeyTypeSECP256k1, nil
+	case 65:
+		return C.TWPublicKeyTypeSECP256k1Extended, nil
+	default:
+		return 0, fmt.Errorf("%w: unexpected public key length %d", ErrInvalidMessage, len(publicKey))
+	}
+}
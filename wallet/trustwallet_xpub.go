@@ -0,0 +1,152 @@
+//go:build trustwalletcore
+
+package wallet
+
+// #cgo CFLAGS: -I${SRCDIR}/../../third_party/wallet-core/include -I/usr/local/include
+// #cgo LDFLAGS: -L/usr/local/lib -lTrustWalletCore -lwallet_core_rs -lTrezorCrypto -lprotobuf -lstdc++ -lm -lpthread
+// #include <TrustWalletCore/TWCurve.h>
+// #include <TrustWalletCore/TWData.h>
+// #include <TrustWalletCore/TWDerivation.h>
+// #include <TrustWalletCore/TWHDVersion.h>
+// #include <TrustWalletCore/TWHDWallet.h>
+// #include <TrustWalletCore/TWPublicKey.h>
+// #include <TrustWalletCore/TWPurpose.h>
+// #include <TrustWalletCore/TWString.h>
+// #include <stdlib.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ExtendedPublicKey returns the account-level extended public key of a wallet
+// The version follows the purpose: xpub for 44 and 86, ypub for 49 and zpub for 84
+// Ed25519 chains such as Solana have no extended public keys, as they only support hardened derivation
+func (twc *TrustWalletCore) ExtendedPublicKey(mnemonic string, passphrase string, coinType uint32, purpose uint32, account uint32) (string, error) {
+	if mnemonic == "" {
+		return "", fmt.Errorf("%w: empty mnemonic", ErrInvalidMnemonic)
+	}
+
+	if !twc.isValidCoinType(coinType) {
+		return "", fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if hardenedOnly(coinType) {
+		return "", fmt.Errorf("%w: coin type %d only supports hardened derivation", ErrInvalidExtendedKey, coinType)
+	}
+
+	if err := validatePurpose(coinType, purpose); err != nil {
+		return "", err
+	}
+
+	if account >= hardenedOffset {
+		return "", fmt.Errorf("%w: account must be below %d", ErrInvalidDerivationPath, uint32(hardenedOffset))
+	}
+
+	mnemonicTW := newTWString(mnemonic)
+	defer C.TWStringDelete(mnemonicTW)
+
+	passphraseTW := newTWString(passphrase)
+	defer C.TWStringDelete(passphraseTW)
+
+	wallet := C.TWHDWalletCreateWithMnemonic(mnemonicTW, passphraseTW)
+	if wallet == nil {
+		return "", fmt.Errorf("%w: failed to import wallet", ErrInvalidMnemonic)
+	}
+	defer C.TWHDWalletDelete(wallet)
+
+	xpubTW := C.TWHDWalletGetExtendedPublicKeyAccount(wallet, C.enum_TWPurpose(purpose), TWCoinType(coinType),
+		C.TWDerivationDefault, extendedKeyVersion(purpose), C.uint32_t(account))
+	if xpubTW == nil {
+		return "", fmt.Errorf("%w: failed to export extended public key", ErrInvalidExtendedKey)
+	}
+	defer C.TWStringDelete(xpubTW)
+
+	xpub := C.GoString(C.TWStringUTF8Bytes(xpubTW))
+	if xpub == "" {
+		return "", fmt.Errorf("%w: empty extended public key", ErrInvalidExtendedKey)
+	}
+
+	return xpub, nil
+}
+
+// DeriveFromExtendedKey derives the public key and address at change/addressIndex below an
+// account-level extended public key, without access to the mnemonic
+// For Bitcoin ypub and zpub keys imply their address type; an xpub needs an explicit one
+func (twc *TrustWalletCore) DeriveFromExtendedKey(xpub string, coinType uint32, addressType string, change, addressIndex uint32) (*WalletKeys, error) {
+	if xpub == "" {
+		return nil, fmt.Errorf("%w: empty extended public key", ErrInvalidExtendedKey)
+	}
+
+	if !twc.isValidCoinType(coinType) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCoinType, coinType)
+	}
+
+	if hardenedOnly(coinType) {
+		return nil, fmt.Errorf("%w: coin type %d only supports hardened derivation", ErrInvalidExtendedKey, coinType)
+	}
+
+	if addressType != "" {
+		if _, err := AddressTypePurpose(coinType, addressType); err != nil {
+			return nil, err
+		}
+	}
+	if coinType == CoinTypeBitcoin {
+		resolved, err := extendedKeyAddressType(xpub, addressType)
+		if err != nil {
+			return nil, err
+		}
+		addressType = resolved
+	}
+
+	// Only the change and address index levels are derived from the extended key;
+	// the purpose and account are fixed by the key itself
+	path, err := formatBIP44Path(PurposeBIP44, coinType, 0, change, addressIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	xpubTW := newTWString(xpub)
+	defer C.TWStringDelete(xpubTW)
+
+	pathTW := newTWString(path)
+	defer C.TWStringDelete(pathTW)
+
+	publicKey := C.TWHDWalletGetPublicKeyFromExtended(xpubTW, TWCoinType(coinType), pathTW)
+	if publicKey == nil {
+		return nil, fmt.Errorf("%w: failed to derive public key", ErrInvalidExtendedKey)
+	}
+	defer C.TWPublicKeyDelete(publicKey)
+
+	publicKeyData := C.TWPublicKeyData(publicKey)
+	if publicKeyData == nil {
+		return nil, fmt.Errorf("%w: failed to get public key data", ErrAddressDerivation)
+	}
+	defer C.TWDataDelete(publicKeyData)
+
+	publicKeyBytes := C.GoBytes(unsafe.Pointer(C.TWDataBytes(publicKeyData)), C.int(C.TWDataSize(publicKeyData)))
+
+	address, err := twc.addressForType(publicKey, coinType, addressType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletKeys{
+		PublicKey: publicKeyBytes,
+		Address:   address,
+		Curve:     curveName(curveForCoin(coinType)),
+	}, nil
+}
+
+// extendedKeyVersion returns the SLIP-132 version bytes for a purpose
+func extendedKeyVersion(purpose uint32) C.enum_TWHDVersion {
+	switch purpose {
+	case PurposeBIP49:
+		return C.TWHDVersionYPUB
+	case PurposeBIP84:
+		return C.TWHDVersionZPUB
+	default:
+		return C.TWHDVersionXPUB
+	}
+}
@@ -0,0 +1,6 @@
+package wallet
+
+import "errors"
+
+// ErrInvalidExtendedKey is returned when an extended public key cannot be exported or parsed
+var ErrInvalidExtendedKey = errors.New("invalid extended public key")
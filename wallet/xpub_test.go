@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtendedPublicKeyBIP84(t *testing.T) {
+	engine := testEngine(t)
+
+	// BIP84 test vectors for the test mnemonic
+	xpub, err := engine.ExtendedPublicKey(testMnemonic, "", CoinTypeBitcoin, PurposeBIP84, 0)
+	if err != nil {
+		t.Fatalf("ExtendedPublicKey() error = %v", err)
+	}
+	const wantXpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	if xpub != wantXpub {
+		t.Errorf("ExtendedPublicKey() = %s, want %s", xpub, wantXpub)
+	}
+
+	keys, err := engine.DeriveFromExtendedKey(xpub, CoinTypeBitcoin, "", 0, 0)
+	if err != nil {
+		t.Fatalf("DeriveFromExtendedKey() error = %v", err)
+	}
+	if want := "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"; keys.Address != want {
+		t.Errorf("DeriveFromExtendedKey() address = %s, want %s", keys.Address, want)
+	}
+}
+
+func TestDeriveFromExtendedKeyBitcoinAddressTypes(t *testing.T) {
+	engine := testEngine(t)
+
+	// BIP44, BIP49 and BIP86 test vectors for the test mnemonic
+	tests := []struct {
+		purpose     uint32
+		wantXpub    string
+		addressType string
+		wantAddress string
+	}{
+		{
+			purpose:     PurposeBIP44,
+			wantXpub:    "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj",
+			addressType: AddressTypeP2PKH,
+			wantAddress: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA",
+		},
+		{
+			purpose:     PurposeBIP49,
+			wantXpub:    "ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSER9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf663zsP",
+			wantAddress: "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf",
+		},
+		{
+			purpose:     PurposeBIP86,
+			wantXpub:    "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ",
+			addressType: AddressTypeP2TR,
+			wantAddress: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+		},
+	}
+
+	for _, tt := range tests {
+		xpub, err := engine.ExtendedPublicKey(testMnemonic, "", CoinTypeBitcoin, tt.purpose, 0)
+		if err != nil {
+			t.Fatalf("ExtendedPublicKey(%d) error = %v", tt.purpose, err)
+		}
+		if xpub != tt.wantXpub {
+			t.Errorf("ExtendedPublicKey(%d) = %s, want %s", tt.purpose, xpub, tt.wantXpub)
+		}
+
+		keys, err := engine.DeriveFromExtendedKey(xpub, CoinTypeBitcoin, tt.addressType, 0, 0)
+		if err != nil {
+			t.Fatalf("DeriveFromExtendedKey(%d) error = %v", tt.purpose, err)
+		}
+		if keys.Address != tt.wantAddress {
+			t.Errorf("DeriveFromExtendedKey(%d) address = %s, want %s", tt.purpose, keys.Address, tt.wantAddress)
+		}
+	}
+}
+
+func TestDeriveFromExtendedKeyRejectsAmbiguousAddressType(t *testing.T) {
+	engine := testEngine(t)
+
+	xpub, err := engine.ExtendedPublicKey(testMnemonic, "", CoinTypeBitcoin, PurposeBIP86, 0)
+	if err != nil {
+		t.Fatalf("ExtendedPublicKey() error = %v", err)
+	}
+	zpub, err := engine.ExtendedPublicKey(testMnemonic, "", CoinTypeBitcoin, PurposeBIP84, 0)
+	if err != nil {
+		t.Fatalf("ExtendedPublicKey() error = %v", err)
+	}
+
+	// An xpub does not record whether it is a P2PKH or a Taproot account
+	if _, err := engine.DeriveFromExtendedKey(xpub, CoinTypeBitcoin, "", 0, 0); !errors.Is(err, ErrInvalidAddressType) {
+		t.Errorf("DeriveFromExtendedKey(xpub) error = %v, want %v", err, ErrInvalidAddressType)
+	}
+	// A zpub only derives P2WPKH addresses
+	if _, err := engine.DeriveFromExtendedKey(zpub, CoinTypeBitcoin, AddressTypeP2TR, 0, 0); !errors.Is(err, ErrInvalidAddressType) {
+		t.Errorf("DeriveFromExtendedKey(zpub, p2tr) error = %v, want %v", err, ErrInvalidAddressType)
+	}
+}
+
+func TestDeriveFromExtendedKeyMatchesSeed(t *testing.T) {
+	engine := testEngine(t)
+
+	xpub, err := engine.ExtendedPublicKey(testMnemonic, "", CoinTypeEthereum, PurposeBIP44, 0)
+	if err != nil {
+		t.Fatalf("ExtendedPublicKey() error = %v", err)
+	}
+
+	for _, index := range []uint32{0, 7} {
+		path, err := BIP44Path(CoinTypeEthereum, 0, 0, index)
+		if err != nil {
+			t.Fatalf("BIP44Path() error = %v", err)
+		}
+		want, err := engine.DeriveAddress(testMnemonic, "", CoinTypeEthereum, path)
+		if err != nil {
+			t.Fatalf("DeriveAddress() error = %v", err)
+		}
+
+		keys, err := engine.DeriveFromExtendedKey(xpub, CoinTypeEthereum, "", 0, index)
+		if err != nil {
+			t.Fatalf("DeriveFromExtendedKey() error = %v", err)
+		}
+		if keys.Address != want {
+			t.Errorf("DeriveFromExtendedKey() index %d = %s, want %s", index, keys.Address, want)
+		}
+	}
+}
+
+func TestExtendedPublicKeyRejectsEd25519(t *testing.T) {
+	engine := testEngine(t)
+
+	if _, err := engine.ExtendedPublicKey(testMnemonic, "", CoinTypeSolana, PurposeBIP44, 0); !errors.Is(err, ErrInvalidExtendedKey) {
+		t.Errorf("ExtendedPublicKey() error = %v, want %v", err, ErrInvalidExtendedKey)
+	}
+	if _, err := engine.ExtendedPublicKey(testMnemonic, "", CoinTypeEthereum, PurposeBIP84, 0); !errors.Is(err, ErrInvalidDerivationPath) {
+		t.Errorf("ExtendedPublicKey() error = %v, want %v", err, ErrInvalidDerivationPath)
+	}
+}